@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rinq/rinq-go/src/internal/namespaces"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// commands is the list of available commands.
+var commands []command
+
+func init() {
+	commands = []command{
+		{
+			"call",
+			"<namespace> <command> [payload]",
+			"Invoke a command and write its response to stdout.",
+			runCall,
+		},
+		{
+			"execute",
+			"<namespace> <command> [payload]",
+			"Invoke a command without waiting for a response.",
+			runExecute,
+		},
+		{
+			"notify",
+			"<namespace> <type> <session-id> [payload]",
+			"Send a notification to a specific session.",
+			runNotify,
+		},
+		{
+			"notify-many",
+			"<namespace> <type> <constraint> [payload]",
+			"Send a notification to all sessions that match a constraint, such as '{a=1, b!=2}'.",
+			runNotifyMany,
+		},
+		{
+			"listen",
+			"[-set key=value]... <namespace>...",
+			"Listen for notifications and write them to stdout.",
+			runListen,
+		},
+		{
+			"session",
+			"[-watch] [-interval duration] <session-id>",
+			"Write the current revision and attributes of a session to stdout.",
+			runSession,
+		},
+		{
+			"replay",
+			"[-realtime] <file>",
+			"Re-issue the calls in a capture file and report any differences between the captured and actual responses.",
+			runReplay,
+		},
+	}
+}
+
+// runCall implements the "call" command.
+func runCall(ctx context.Context, e *env, args []string) error {
+	if len(args) < 2 || len(args) > 3 {
+		return errUsage
+	}
+
+	ns, cmd := args[0], args[1]
+	if err := namespaces.Validate(ns); err != nil {
+		return err
+	}
+
+	out, err := readPayload(optionalArg(args, 2), e.stdin)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	return withSession(e, func(sess rinq.Session) error {
+		ctx, cancel := context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+
+		in, err := sess.Call(ctx, ns, cmd, out)
+		defer in.Close()
+
+		if f, ok := rinq.FailureFromError(err); ok {
+			defer f.Payload.Close()
+
+			if err := writeFailure(e.stdout, f); err != nil {
+				return err
+			}
+
+			return errFailed
+		} else if err != nil {
+			return err
+		}
+
+		v, err := payloadJSON(in)
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(e.stdout, v)
+	})
+}
+
+// runExecute implements the "execute" command.
+func runExecute(ctx context.Context, e *env, args []string) error {
+	if len(args) < 2 || len(args) > 3 {
+		return errUsage
+	}
+
+	ns, cmd := args[0], args[1]
+	if err := namespaces.Validate(ns); err != nil {
+		return err
+	}
+
+	out, err := readPayload(optionalArg(args, 2), e.stdin)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	return withSession(e, func(sess rinq.Session) error {
+		ctx, cancel := context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+
+		return sess.Execute(ctx, ns, cmd, out)
+	})
+}
+
+// runNotify implements the "notify" command.
+func runNotify(ctx context.Context, e *env, args []string) error {
+	if len(args) < 3 || len(args) > 4 {
+		return errUsage
+	}
+
+	ns, t := args[0], args[1]
+	if err := namespaces.Validate(ns); err != nil {
+		return err
+	}
+
+	target, err := ident.ParseSessionID(args[2])
+	if err != nil {
+		return err
+	}
+
+	out, err := readPayload(optionalArg(args, 3), e.stdin)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	return withSession(e, func(sess rinq.Session) error {
+		ctx, cancel := context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+
+		return sess.Notify(ctx, ns, t, target, out)
+	})
+}
+
+// runNotifyMany implements the "notify-many" command.
+func runNotifyMany(ctx context.Context, e *env, args []string) error {
+	if len(args) < 3 || len(args) > 4 {
+		return errUsage
+	}
+
+	ns, t := args[0], args[1]
+	if err := namespaces.Validate(ns); err != nil {
+		return err
+	}
+
+	con, err := constraint.Parse(args[2])
+	if err != nil {
+		return err
+	}
+
+	out, err := readPayload(optionalArg(args, 3), e.stdin)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	return withSession(e, func(sess rinq.Session) error {
+		ctx, cancel := context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+
+		return sess.NotifyMany(ctx, ns, t, con, out)
+	})
+}
+
+// notificationJSON is the JSON representation of a notification.
+type notificationJSON struct {
+	ID         string      `json:"id"`
+	Source     string      `json:"source"`
+	Namespace  string      `json:"namespace"`
+	Type       string      `json:"type"`
+	Multicast  bool        `json:"multicast,omitempty"`
+	Constraint string      `json:"constraint,omitempty"`
+	Payload    interface{} `json:"payload"`
+}
+
+// runListen implements the "listen" command.
+func runListen(ctx context.Context, e *env, args []string) error {
+	var attrs attrFlag
+
+	fs := flag.NewFlagSet("listen", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Var(&attrs, "set", "set a session attribute in each namespace, may be repeated")
+
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+
+	for _, ns := range fs.Args() {
+		if err := namespaces.Validate(ns); err != nil {
+			return err
+		}
+	}
+
+	return withSession(e, func(sess rinq.Session) error {
+		var m sync.Mutex
+
+		handler := func(_ context.Context, _ rinq.Session, n rinq.Notification) {
+			defer n.Payload.Close()
+
+			v := notificationJSON{
+				ID:        n.ID.String(),
+				Source:    n.Source.SessionID().String(),
+				Namespace: n.Namespace,
+				Type:      n.Type,
+				Multicast: n.IsMulticast,
+			}
+
+			if n.IsMulticast {
+				v.Constraint = n.Constraint.String()
+			}
+
+			p, err := payloadJSON(n.Payload)
+			if err != nil {
+				fmt.Fprintf(e.stderr, "rinq listen: %s\n", err)
+				return
+			}
+			v.Payload = p
+
+			m.Lock()
+			defer m.Unlock()
+
+			_ = writeJSON(e.stdout, v)
+		}
+
+		for _, ns := range fs.Args() {
+			if err := sess.Listen(ns, handler); err != nil {
+				return err
+			}
+
+			if len(attrs) > 0 {
+				if err := updateAttrs(ctx, e, sess, ns, attrs); err != nil {
+					return err
+				}
+			}
+		}
+
+		fmt.Fprintf(e.stderr, "listening as %s\n", sess.ID())
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sess.Done():
+			return errors.New("session destroyed")
+		}
+	})
+}
+
+// updateAttrs sets attrs on the ns namespace of sess.
+func updateAttrs(
+	ctx context.Context,
+	e *env,
+	sess rinq.Session,
+	ns string,
+	attrs []rinq.Attr,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	_, err := sess.CurrentRevision().Update(ctx, ns, attrs...)
+	return err
+}
+
+// withSession dials the network and calls fn with a new session. The session
+// is destroyed and the peer is stopped when fn returns.
+func withSession(e *env, fn func(rinq.Session) error) error {
+	peer, err := e.dial()
+	if err != nil {
+		return err
+	}
+	defer stop(peer)
+
+	sess := peer.Session()
+	defer sess.Destroy()
+
+	return fn(sess)
+}
+
+// optionalArg returns args[i], or an empty string if there is no such argument.
+func optionalArg(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+
+	return ""
+}
+
+// attrFlag is a flag.Value that accumulates "key=value" attributes.
+type attrFlag []rinq.Attr
+
+func (f *attrFlag) String() string {
+	var s []string
+	for _, a := range *f {
+		s = append(s, a.Key+"="+a.Value)
+	}
+
+	return strings.Join(s, ", ")
+}
+
+func (f *attrFlag) Set(s string) error {
+	i := strings.IndexByte(s, '=')
+	if i <= 0 {
+		return fmt.Errorf("attribute must be in the form key=value: %s", s)
+	}
+
+	*f = append(*f, rinq.Set(s[:i], s[i+1:]))
+	return nil
+}
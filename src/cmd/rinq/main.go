@@ -0,0 +1,164 @@
+// Command rinq is a command-line tool for interacting with a Rinq network.
+//
+// It connects to the network using rinqamqp.DialEnv(), so the broker and the
+// peer's options are configured using the RINQ_AMQP_DSN and RINQ_* environment
+// variables.
+//
+// Payloads are given as JSON, either as a command-line argument or read from
+// stdin when the argument is "-". They are sent as CBOR. Responses and
+// notifications are written to stdout as JSON, one value per line.
+//
+// Usage:
+//
+//	rinq [-v] [-timeout duration] <command> [arguments]
+//
+// Run "rinq help" for a list of commands.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/options"
+	"github.com/rinq/rinq-go/src/rinqamqp"
+)
+
+// Exit codes.
+const (
+	exitError   = 1 // an error occurred
+	exitUsage   = 2 // the command-line arguments are invalid
+	exitFailure = 3 // a command responded with a failure, or a replay differed
+)
+
+// errUsage is returned by a command when its arguments are invalid.
+var errUsage = errors.New("invalid usage")
+
+// errFailed is returned by a command when it has written a failure response,
+// or when replayed responses differ from those that were captured.
+var errFailed = errors.New("command failed")
+
+// command is a sub-command of the CLI.
+type command struct {
+	name     string
+	synopsis string
+	help     string
+	run      func(ctx context.Context, e *env, args []string) error
+}
+
+// env is the environment in which a command runs.
+type env struct {
+	verbose bool
+	timeout time.Duration
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+// dial connects to the Rinq network.
+func (e *env) dial() (rinq.Peer, error) {
+	level := slog.LevelWarn
+	if e.verbose {
+		level = slog.LevelDebug
+	}
+
+	logger := slog.New(
+		slog.NewTextHandler(e.stderr, &slog.HandlerOptions{Level: level}),
+	)
+
+	return rinqamqp.DialEnv(
+		options.StructuredLogger(logging.FromSlog(logger)),
+	)
+}
+
+// stop gracefully stops p and waits for it to disconnect.
+func stop(p rinq.Peer) {
+	p.GracefulStop()
+	<-p.Done()
+}
+
+func main() {
+	e := &env{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	os.Exit(run(ctx, e, os.Args[1:]))
+}
+
+// run executes the command described by args and returns the exit code.
+func run(ctx context.Context, e *env, args []string) int {
+	fs := flag.NewFlagSet("rinq", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.BoolVar(&e.verbose, "v", false, "write debug logs to stderr")
+	fs.DurationVar(&e.timeout, "timeout", 5*time.Second, "the maximum time to wait for each operation")
+	fs.Usage = func() { usage(e.stderr, fs) }
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	name := fs.Arg(0)
+	if name == "help" {
+		fs.SetOutput(e.stdout)
+		usage(e.stdout, fs)
+		return 0
+	}
+
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+
+		err := c.run(ctx, e, fs.Args()[1:])
+
+		switch err {
+		case nil:
+			return 0
+		case errFailed:
+			return exitFailure
+		case errUsage:
+			fmt.Fprintf(e.stderr, "usage: rinq %s %s\n", c.name, c.synopsis)
+			return exitUsage
+		default:
+			fmt.Fprintf(e.stderr, "rinq %s: %s\n", c.name, err)
+			return exitError
+		}
+	}
+
+	fmt.Fprintf(e.stderr, "rinq: unknown command '%s'\n", name)
+	return exitUsage
+}
+
+// usage writes the CLI usage information to w.
+func usage(w io.Writer, fs *flag.FlagSet) {
+	fmt.Fprintln(w, "usage: rinq [-v] [-timeout duration] <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %s %s\n", c.name, c.synopsis)
+		fmt.Fprintf(w, "      %s\n", c.help)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "options:")
+	fs.PrintDefaults()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("run", func() {
+	var (
+		stdout, stderr *bytes.Buffer
+		e              *env
+	)
+
+	BeforeEach(func() {
+		stdout = &bytes.Buffer{}
+		stderr = &bytes.Buffer{}
+		e = &env{
+			stdin:  &bytes.Buffer{},
+			stdout: stdout,
+			stderr: stderr,
+		}
+	})
+
+	It("lists the commands", func() {
+		code := run(context.Background(), e, []string{"help"})
+
+		Expect(code).To(Equal(0))
+		Expect(stdout.String()).To(ContainSubstring("notify-many"))
+	})
+
+	It("fails if no command is given", func() {
+		code := run(context.Background(), e, nil)
+
+		Expect(code).To(Equal(exitUsage))
+	})
+
+	It("fails if the command is unknown", func() {
+		code := run(context.Background(), e, []string{"<unknown>"})
+
+		Expect(code).To(Equal(exitUsage))
+		Expect(stderr.String()).To(ContainSubstring("unknown command"))
+	})
+
+	DescribeTable(
+		"validates arguments before connecting",
+		func(code int, args ...string) {
+			Expect(run(context.Background(), e, args)).To(Equal(code))
+		},
+		Entry("call with too few arguments", exitUsage, "call", "ns"),
+		Entry("call with an invalid namespace", exitError, "call", "_ns", "cmd"),
+		Entry("call with an invalid payload", exitError, "call", "ns", "cmd", "{"),
+		Entry("execute with too many arguments", exitUsage, "execute", "ns", "cmd", "1", "2"),
+		Entry("notify with an invalid session ID", exitError, "notify", "ns", "type", "<invalid>"),
+		Entry("notify-many with an invalid constraint", exitError, "notify-many", "ns", "type", "{a=1"),
+		Entry("listen without namespaces", exitUsage, "listen"),
+		Entry("listen with an invalid attribute", exitUsage, "listen", "-set", "foo", "ns"),
+		Entry("session without a session ID", exitUsage, "session"),
+		Entry("session with an invalid session ID", exitError, "session", "<invalid>"),
+		Entry("session with a non-positive interval", exitUsage, "session", "-watch", "-interval", "0s", "0-0.1"),
+		Entry("replay without a file", exitUsage, "replay"),
+		Entry("replay with a missing file", exitError, "replay", "/nonexistent/capture.jsonl"),
+	)
+})
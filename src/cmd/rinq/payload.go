@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/codec"
+)
+
+// readPayload returns a CBOR payload containing the JSON value in arg.
+//
+// If arg is "-" the JSON value is read from r. If arg is empty, a nil payload
+// is returned.
+func readPayload(arg string, r io.Reader) (*rinq.Payload, error) {
+	var buf []byte
+
+	switch arg {
+	case "":
+		return nil, nil
+	case "-":
+		var err error
+		if buf, err = io.ReadAll(r); err != nil {
+			return nil, err
+		}
+	default:
+		buf = []byte(arg)
+	}
+
+	if !json.Valid(buf) {
+		return nil, errors.New("payload is not valid JSON")
+	}
+
+	p := rinq.NewPayloadFromBytesWithContentType(buf, codec.JSON.ContentType())
+	defer p.Close()
+
+	return p.Transcode(codec.CBOR)
+}
+
+// payloadJSON returns the JSON representation of p.
+func payloadJSON(p *rinq.Payload) (json.RawMessage, error) {
+	if p == nil {
+		return json.RawMessage("null"), nil
+	}
+
+	t, err := p.Transcode(codec.JSON)
+	if err != nil {
+		return nil, err
+	}
+	defer t.Close()
+
+	buf := bytes.TrimSpace(t.Bytes())
+	if len(buf) == 0 {
+		return json.RawMessage("null"), nil
+	}
+
+	return json.RawMessage(buf), nil
+}
+
+// writeJSON writes v to w as a single line of JSON.
+func writeJSON(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// failureJSON is the JSON representation of a command failure.
+type failureJSON struct {
+	Failure struct {
+		Type    string          `json:"type"`
+		Message string          `json:"message,omitempty"`
+		Payload json.RawMessage `json:"payload"`
+	} `json:"failure"`
+}
+
+// writeFailure writes f to w as JSON.
+func writeFailure(w io.Writer, f rinq.Failure) error {
+	var v failureJSON
+
+	payload, err := payloadJSON(f.Payload)
+	if err != nil {
+		return err
+	}
+
+	v.Failure.Type = f.Type
+	v.Failure.Message = f.Message
+	v.Failure.Payload = payload
+
+	return writeJSON(w, v)
+}
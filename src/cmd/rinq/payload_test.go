@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/codec"
+)
+
+var _ = Describe("readPayload", func() {
+	It("returns a CBOR payload containing the JSON value", func() {
+		p, err := readPayload(`{"a":[1,2]}`, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		defer p.Close()
+
+		Expect(p.ContentType()).To(Equal(codec.CBOR.ContentType()))
+
+		var v map[string][]int
+		Expect(p.Decode(&v)).To(Succeed())
+		Expect(v).To(Equal(map[string][]int{"a": {1, 2}}))
+	})
+
+	It("reads the JSON value from the reader when the argument is '-'", func() {
+		p, err := readPayload("-", strings.NewReader(`"value"`))
+		Expect(err).ShouldNot(HaveOccurred())
+		defer p.Close()
+
+		Expect(p.Value()).To(Equal("value"))
+	})
+
+	It("returns a nil payload when the argument is empty", func() {
+		p, err := readPayload("", nil)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(p).To(BeNil())
+	})
+
+	It("returns an error if the argument is not valid JSON", func() {
+		_, err := readPayload(`{`, nil)
+
+		Expect(err).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("payloadJSON", func() {
+	It("returns the JSON representation of the payload", func() {
+		p := rinq.NewPayload(map[string]interface{}{"a": 1})
+		defer p.Close()
+
+		v, err := payloadJSON(p)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(v).To(MatchJSON(`{"a":1}`))
+	})
+
+	It("returns null for a nil payload", func() {
+		v, err := payloadJSON(nil)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(v)).To(Equal("null"))
+	})
+})
+
+var _ = Describe("writeFailure", func() {
+	It("writes the failure as JSON", func() {
+		p := rinq.NewPayload([]int{1})
+		defer p.Close()
+
+		var buf bytes.Buffer
+		err := writeFailure(&buf, rinq.Failure{Type: "type", Message: "message", Payload: p})
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(buf.String()).To(MatchJSON(`{"failure":{"type":"type","message":"message","payload":[1]}}`))
+	})
+})
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/capture"
+	"github.com/rinq/rinq-go/src/rinq/metadata"
+	"github.com/rinq/rinq-go/src/rinq/trace"
+)
+
+// replayJSON is the JSON representation of the result of replaying a single
+// captured call.
+type replayJSON struct {
+	MessageID string   `json:"message_id"`
+	Namespace string   `json:"namespace"`
+	Command   string   `json:"command"`
+	Match     bool     `json:"match"`
+	Diff      []string `json:"diff,omitempty"`
+}
+
+// replayer re-issues captured calls and compares their responses.
+type replayer struct {
+	// call re-issues the command described by r.
+	call func(ctx context.Context, r capture.Record) (*rinq.Payload, error)
+
+	// realtime, if true, preserves the original interval between calls.
+	realtime bool
+
+	replayed, differed, skipped int
+}
+
+// replay re-issues each call read from in, and writes the result of each to w.
+func (rp *replayer) replay(ctx context.Context, in *capture.Reader, w io.Writer) error {
+	var prev time.Time
+
+	for {
+		r, err := in.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		// only calls and handled requests include a response to compare
+		if r.Kind != capture.Call && r.Kind != capture.Request {
+			rp.skipped++
+			continue
+		}
+
+		if rp.realtime && !prev.IsZero() {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(r.StartedAt.Sub(prev)):
+			}
+		}
+		prev = r.StartedAt
+
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		v := replayJSON{
+			MessageID: r.MessageID,
+			Namespace: r.Namespace,
+			Command:   r.Command,
+		}
+
+		p, err := rp.call(ctx, r)
+		v.Diff = capture.Compare(r, p, err)
+		v.Match = len(v.Diff) == 0
+		p.Close()
+
+		rp.replayed++
+		if !v.Match {
+			rp.differed++
+		}
+
+		if err := writeJSON(w, v); err != nil {
+			return err
+		}
+	}
+}
+
+// runReplay implements the "replay" command.
+func runReplay(ctx context.Context, e *env, args []string) error {
+	rp := &replayer{}
+
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.BoolVar(&rp.realtime, "realtime", false, "preserve the original interval between calls")
+
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if fs.NArg() != 1 {
+		return errUsage
+	}
+
+	var r io.Reader = e.stdin
+	if fs.Arg(0) != "-" {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	return withSession(e, func(sess rinq.Session) error {
+		rp.call = func(ctx context.Context, r capture.Record) (*rinq.Payload, error) {
+			ctx, cancel := context.WithTimeout(ctx, e.timeout)
+			defer cancel()
+
+			if r.TraceID != "" {
+				ctx = trace.With(ctx, r.TraceID)
+			}
+
+			if len(r.Metadata) != 0 {
+				ctx = metadata.With(ctx, r.Metadata)
+			}
+
+			out := r.Request.Payload()
+			defer out.Close()
+
+			return sess.Call(ctx, r.Namespace, r.Command, out)
+		}
+
+		if err := rp.replay(ctx, capture.NewReader(r), e.stdout); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(
+			e.stderr,
+			"replayed %d call(s), %d differed, %d record(s) skipped\n",
+			rp.replayed,
+			rp.differed,
+			rp.skipped,
+		)
+
+		if rp.differed != 0 {
+			return errFailed
+		}
+
+		return nil
+	})
+}
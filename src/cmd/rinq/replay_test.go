@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/capture"
+)
+
+var _ = Describe("replayer", func() {
+	var (
+		file  *bytes.Buffer
+		calls []capture.Record
+		rp    *replayer
+	)
+
+	BeforeEach(func() {
+		file = &bytes.Buffer{}
+		calls = nil
+
+		w := capture.NewWriter(file)
+
+		for i, kind := range []capture.Kind{capture.Call, capture.Notification, capture.Request} {
+			p := rinq.NewPayload(i)
+			r := capture.Record{
+				Kind:      kind,
+				MessageID: "58AEE146-191C.45@3#" + string(rune('1'+i)),
+				Namespace: "ns",
+				Command:   "cmd",
+				Request:   capture.NewPayload(p),
+			}
+			r.SetResult(p, nil)
+			p.Close()
+
+			w.Record(r)
+		}
+
+		rp = &replayer{
+			call: func(_ context.Context, r capture.Record) (*rinq.Payload, error) {
+				calls = append(calls, r)
+				return r.Request.Payload(), nil
+			},
+		}
+	})
+
+	It("re-issues calls and requests, skipping other records", func() {
+		var out bytes.Buffer
+		err := rp.replay(context.Background(), capture.NewReader(file), &out)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(calls).To(HaveLen(2))
+		Expect(calls[0].Kind).To(Equal(capture.Call))
+		Expect(calls[1].Kind).To(Equal(capture.Request))
+		Expect(rp.replayed).To(Equal(2))
+		Expect(rp.differed).To(Equal(0))
+		Expect(rp.skipped).To(Equal(1))
+		Expect(out.String()).To(Equal(
+			`{"message_id":"58AEE146-191C.45@3#1","namespace":"ns","command":"cmd","match":true}` + "\n" +
+				`{"message_id":"58AEE146-191C.45@3#3","namespace":"ns","command":"cmd","match":true}` + "\n",
+		))
+	})
+
+	It("reports responses that differ", func() {
+		rp.call = func(context.Context, capture.Record) (*rinq.Payload, error) {
+			return nil, rinq.NewFailure("type", "message", nil)
+		}
+
+		var out bytes.Buffer
+		err := rp.replay(context.Background(), capture.NewReader(file), &out)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(rp.differed).To(Equal(2))
+
+		var v replayJSON
+		Expect(json.NewDecoder(&out).Decode(&v)).To(Succeed())
+		Expect(v.Match).To(BeFalse())
+		Expect(v.Diff).To(ConsistOf("outcome: expected success, got 'type' failure"))
+	})
+})
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// runSession implements the "session" command.
+func runSession(ctx context.Context, e *env, args []string) error {
+	var (
+		watch    bool
+		interval time.Duration
+	)
+
+	fs := flag.NewFlagSet("session", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.BoolVar(&watch, "watch", false, "continue to write the session's attributes each time they change")
+	fs.DurationVar(&interval, "interval", time.Second, "how often to check for changes when watching")
+
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if fs.NArg() != 1 || interval <= 0 {
+		return errUsage
+	}
+
+	id, err := ident.ParseSessionID(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	peer, err := e.dial()
+	if err != nil {
+		return err
+	}
+	defer stop(peer)
+
+	inspect := func() (rinq.SessionInfo, error) {
+		ctx, cancel := context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+
+		return peer.InspectSession(ctx, id)
+	}
+
+	info, err := inspect()
+	if err != nil {
+		return err
+	}
+
+	writeSessionInfo(e.stdout, info)
+
+	if !watch {
+		return nil
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		next, err := inspect()
+		if rinq.IsNotFound(err) {
+			fmt.Fprintf(e.stdout, "%s destroyed\n", id)
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if next.Ref != info.Ref {
+			info = next
+			fmt.Fprintln(e.stdout)
+			writeSessionInfo(e.stdout, info)
+		}
+	}
+}
+
+// writeSessionInfo writes the revision and attributes in info to w, with one
+// line for each namespace.
+func writeSessionInfo(w io.Writer, info rinq.SessionInfo) {
+	fmt.Fprintln(w, info.Ref)
+
+	for _, ns := range info.Namespaces() {
+		fmt.Fprintln(w, info.String(ns))
+	}
+}
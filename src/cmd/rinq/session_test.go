@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+var _ = Describe("writeSessionInfo", func() {
+	It("writes the session ref followed by each namespace", func() {
+		id, err := ident.ParseSessionID("58AEE146-191C.45")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		var buf bytes.Buffer
+		writeSessionInfo(&buf, rinq.SessionInfo{
+			Ref: id.At(3),
+			Attrs: map[string][]rinq.Attr{
+				"ns2": {rinq.Freeze("b", "2")},
+				"ns1": {rinq.Set("a", "1")},
+			},
+		})
+
+		Expect(buf.String()).To(Equal(
+			"58AEE146-191C.45@3\n" +
+				"ns1::{a=1}\n" +
+				"ns2::{b@2}\n",
+		))
+	})
+})
@@ -1,8 +1,12 @@
 package attributes
 
 import (
+	"sort"
+
 	"github.com/rinq/rinq-go/src/internal/x/bufferpool"
+	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/constraint"
+	"github.com/rinq/rinq-go/src/rinq/ident"
 )
 
 // Catalog is a namespaced collection of attributes.
@@ -29,6 +33,52 @@ func (c Catalog) MatchConstraint(ns string, con constraint.Constraint) bool {
 	return isMatch.(bool)
 }
 
+// Each calls fn for each attribute in each namespace of the catalog. Iteration
+// stops when fn returns false.
+func (c Catalog) Each(fn func(rinq.Attr) bool) {
+	for _, t := range c {
+		for _, attr := range t {
+			if !fn(attr.Attr) {
+				return
+			}
+		}
+	}
+}
+
+// NamespacesAt returns the sorted names of the namespaces that contained at
+// least one non-empty attribute at revision rev.
+//
+// ok is false if the namespaces can not be determined because attributes that
+// existed at rev have since been modified.
+func (c Catalog) NamespacesAt(rev ident.Revision) (names []string, ok bool) {
+	for ns, t := range c {
+		found, stale := false, false
+
+		for _, v := range t {
+			if v.CreatedAt > rev {
+				continue
+			}
+
+			if v.UpdatedAt > rev {
+				stale = true
+			} else if v.Value != "" {
+				found = true
+				break
+			}
+		}
+
+		if found {
+			names = append(names, ns)
+		} else if stale {
+			return nil, false
+		}
+	}
+
+	sort.Strings(names)
+
+	return names, true
+}
+
 // IsEmpty returns true if there are no attributes in the catalog.
 func (c Catalog) IsEmpty() bool {
 	for _, t := range c {
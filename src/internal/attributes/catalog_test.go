@@ -238,6 +238,84 @@ var _ = Describe("Catalog", func() {
 		)
 	})
 
+	Describe("Each", func() {
+		cat := Catalog{
+			"ns1": {
+				"a": {Attr: rinq.Set("a", "1")},
+			},
+			"ns2": {
+				"b": {Attr: rinq.Set("b", "2")},
+			},
+		}
+
+		It("calls the function for each attribute in each namespace", func() {
+			var attrs []rinq.Attr
+			cat.Each(func(attr rinq.Attr) bool {
+				attrs = append(attrs, attr)
+				return true
+			})
+
+			Expect(attrs).To(ConsistOf(
+				rinq.Set("a", "1"),
+				rinq.Set("b", "2"),
+			))
+		})
+
+		It("stops iteration if the function returns false", func() {
+			var attrs []rinq.Attr
+			cat.Each(func(attr rinq.Attr) bool {
+				attrs = append(attrs, attr)
+				return false
+			})
+
+			Expect(len(attrs)).To(Equal(1))
+		})
+	})
+
+	Describe("NamespacesAt", func() {
+		cat := Catalog{
+			"ns2": {
+				"a": {Attr: rinq.Set("a", "1"), CreatedAt: 1, UpdatedAt: 1},
+			},
+			"ns1": {
+				"b": {Attr: rinq.Set("b", "2"), CreatedAt: 2, UpdatedAt: 2},
+			},
+			"ns3": {},
+			"ns4": {
+				"c": {Attr: rinq.Set("c", ""), CreatedAt: 2, UpdatedAt: 3},
+			},
+		}
+
+		It("returns the sorted names of namespaces with attributes at the revision", func() {
+			names, ok := cat.NamespacesAt(3)
+			Expect(ok).To(BeTrue())
+			Expect(names).To(Equal([]string{"ns1", "ns2"}))
+		})
+
+		It("omits namespaces with attributes created after the revision", func() {
+			names, ok := cat.NamespacesAt(1)
+			Expect(ok).To(BeTrue())
+			Expect(names).To(Equal([]string{"ns2"}))
+		})
+
+		It("omits namespaces in which every attribute has been cleared", func() {
+			names, ok := cat.NamespacesAt(3)
+			Expect(ok).To(BeTrue())
+			Expect(names).NotTo(ContainElement("ns4"))
+		})
+
+		It("returns false if attributes have been modified since the revision", func() {
+			_, ok := cat.NamespacesAt(2)
+			Expect(ok).To(BeFalse())
+		})
+
+		It("returns an empty slice at revision zero", func() {
+			names, ok := cat.NamespacesAt(0)
+			Expect(ok).To(BeTrue())
+			Expect(names).To(BeEmpty())
+		})
+	})
+
 	Describe("IsEmpty", func() {
 		It("returns true when the catalog is empty", func() {
 			Expect(Catalog{}.IsEmpty()).To(BeTrue())
@@ -0,0 +1,65 @@
+package attributes
+
+import (
+	"sort"
+
+	"github.com/rinq/rinq-go/src/internal/x/bufferpool"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// DiffSet is a collection of diffs representing a change to attributes in
+// several namespaces that produced a single revision.
+type DiffSet struct {
+	Revision ident.Revision
+	Diffs    []*Diff // sorted by namespace
+}
+
+// NewDiffSet returns a new DiffSet for the given revision.
+func NewDiffSet(rev ident.Revision) *DiffSet {
+	return &DiffSet{
+		Revision: rev,
+	}
+}
+
+// Add adds a diff to the set.
+func (s *DiffSet) Add(d *Diff) {
+	s.Diffs = append(s.Diffs, d)
+
+	sort.Slice(s.Diffs, func(i, j int) bool {
+		return s.Diffs[i].Namespace < s.Diffs[j].Namespace
+	})
+}
+
+// IsEmpty returns true if none of the diffs contain any attributes.
+func (s *DiffSet) IsEmpty() bool {
+	for _, d := range s.Diffs {
+		if !d.IsEmpty() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *DiffSet) String() string {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+
+	for _, d := range s.Diffs {
+		if d.IsEmpty() {
+			continue
+		}
+
+		if buf.Len() != 0 {
+			buf.WriteRune(' ')
+		}
+
+		buf.WriteString(d.String())
+	}
+
+	if buf.Len() == 0 {
+		return "{}"
+	}
+
+	return buf.String()
+}
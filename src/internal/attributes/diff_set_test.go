@@ -0,0 +1,70 @@
+package attributes_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rinq/rinq-go/src/internal/attributes"
+	"github.com/rinq/rinq-go/src/rinq"
+)
+
+var _ = Describe("DiffSet", func() {
+	Describe("Add", func() {
+		It("keeps the diffs sorted by namespace", func() {
+			s := NewDiffSet(1)
+			b := NewDiff("b", 1)
+			a := NewDiff("a", 1)
+
+			s.Add(b)
+			s.Add(a)
+
+			Expect(s.Diffs).To(Equal([]*Diff{a, b}))
+		})
+	})
+
+	Describe("IsEmpty", func() {
+		It("returns true if there are no diffs", func() {
+			s := NewDiffSet(1)
+			Expect(s.IsEmpty()).To(BeTrue())
+		})
+
+		It("returns true if all of the diffs are empty", func() {
+			s := NewDiffSet(1)
+			s.Add(NewDiff("a", 1))
+			Expect(s.IsEmpty()).To(BeTrue())
+		})
+
+		It("returns false if any of the diffs contain attributes", func() {
+			d := NewDiff("b", 1)
+			d.Append(VAttr{Attr: rinq.Set("a", "1")})
+
+			s := NewDiffSet(1)
+			s.Add(NewDiff("a", 1))
+			s.Add(d)
+
+			Expect(s.IsEmpty()).To(BeFalse())
+		})
+	})
+
+	Describe("String", func() {
+		It("returns braces when the set is empty", func() {
+			s := NewDiffSet(1)
+			s.Add(NewDiff("a", 1))
+			Expect(s.String()).To(Equal("{}"))
+		})
+
+		It("renders each non-empty diff in namespace order", func() {
+			a := NewDiff("a", 2)
+			a.Append(VAttr{Attr: rinq.Set("x", "1"), CreatedAt: 2})
+
+			b := NewDiff("b", 2)
+			b.Append(VAttr{Attr: rinq.Set("y", "2"), CreatedAt: 1})
+
+			s := NewDiffSet(2)
+			s.Add(b)
+			s.Add(NewDiff("c", 2))
+			s.Add(a)
+
+			Expect(s.String()).To(Equal("a::{+x=1} b::{y=2}"))
+		})
+	})
+})
@@ -1,6 +1,8 @@
 package attributes
 
 import (
+	"time"
+
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
 )
@@ -11,4 +13,26 @@ type VAttr struct {
 
 	CreatedAt ident.Revision `json:"cr,omitempty"`
 	UpdatedAt ident.Revision `json:"ur,omitempty"`
+
+	// ExpiresAt is the time at which the attribute expires, as a Unix time in
+	// nanoseconds. It is zero if the attribute does not expire.
+	ExpiresAt int64 `json:"x,omitempty"`
+}
+
+// IsExpiredAt returns true if the attribute has a value that has expired as
+// of t.
+func (a VAttr) IsExpiredAt(t time.Time) bool {
+	return a.ExpiresAt != 0 && a.Value != "" && t.UnixNano() >= a.ExpiresAt
+}
+
+// AttrAt returns the attribute as it reads at time t. Its value is empty if
+// it has expired, as the owning peer clears expired attributes.
+func (a VAttr) AttrAt(t time.Time) rinq.Attr {
+	attr := a.Attr
+
+	if a.IsExpiredAt(t) {
+		attr.Value = ""
+	}
+
+	return attr
 }
@@ -1,7 +1,10 @@
 package attributes
 
 import (
+	"time"
+
 	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
 )
 
 // VTable is a collection of attributes with revision information.
@@ -36,3 +39,27 @@ func (t VTable) Clone() VTable {
 
 	return c
 }
+
+// At returns the attributes in t as they were at revision rev.
+//
+// Attributes that had not yet been created at rev are omitted, and attributes
+// that have expired as of now have empty values. ok is false if any attribute
+// has been updated since rev, in which case its value at rev is no longer
+// known.
+func (t VTable) At(rev ident.Revision, now time.Time) (table Table, ok bool) {
+	table = Table{}
+
+	for k, v := range t {
+		if v.CreatedAt > rev {
+			continue
+		}
+
+		if v.UpdatedAt > rev {
+			return nil, false
+		}
+
+		table[k] = v.AttrAt(now)
+	}
+
+	return table, true
+}
@@ -1,6 +1,8 @@
 package attributes_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	. "github.com/rinq/rinq-go/src/internal/attributes"
@@ -83,4 +85,53 @@ var _ = Describe("VTable", func() {
 			Expect(t).NotTo(BeNil())
 		})
 	})
+
+	Describe("At", func() {
+		BeforeEach(func() {
+			table = VTable{
+				"a": {Attr: rinq.Set("a", "1"), CreatedAt: 1, UpdatedAt: 1},
+				"b": {Attr: rinq.Set("b", "2"), CreatedAt: 1, UpdatedAt: 2},
+				"c": {Attr: rinq.Set("c", "3"), CreatedAt: 3, UpdatedAt: 3},
+			}
+		})
+
+		It("returns the attributes at the revision", func() {
+			t, ok := table.At(3, time.Now())
+
+			Expect(ok).To(BeTrue())
+			Expect(t).To(Equal(Table{
+				"a": rinq.Set("a", "1"),
+				"b": rinq.Set("b", "2"),
+				"c": rinq.Set("c", "3"),
+			}))
+		})
+
+		It("omits attributes created after the revision", func() {
+			t, ok := table.At(2, time.Now())
+
+			Expect(ok).To(BeTrue())
+			Expect(t).NotTo(HaveKey("c"))
+		})
+
+		It("returns empty values for attributes that have expired", func() {
+			now := time.Now()
+			table["a"] = VAttr{
+				Attr:      rinq.Set("a", "1"),
+				CreatedAt: 1,
+				UpdatedAt: 1,
+				ExpiresAt: now.UnixNano(),
+			}
+
+			t, ok := table.At(3, now)
+
+			Expect(ok).To(BeTrue())
+			Expect(t).To(HaveKeyWithValue("a", rinq.Set("a", "")))
+		})
+
+		It("returns false if an attribute has been updated since the revision", func() {
+			_, ok := table.At(1, time.Now())
+
+			Expect(ok).To(BeFalse())
+		})
+	})
 })
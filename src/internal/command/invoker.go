@@ -65,6 +65,9 @@ type Invoker interface {
 		payload *rinq.Payload,
 	) error
 
+	// Pending returns the number of calls that are awaiting a response.
+	Pending() int
+
 	// ExecuteMulticast sends a multicast command request to the all available
 	// peers and returns immediately.
 	ExecuteMulticast(
@@ -3,11 +3,12 @@ package command
 import (
 	"time"
 
-	"github.com/jmalloc/twelf/src/twelf"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/rinq/rinq-go/src/internal/opentr"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/redact"
 )
 
 // response wraps a "parent" response and performs logging and tracing when the
@@ -18,7 +19,8 @@ type response struct {
 
 	peerID    ident.PeerID
 	traceID   string
-	logger    twelf.Logger
+	logger    logging.Logger
+	redactor  redact.Redactor
 	span      opentracing.Span
 	startedAt time.Time
 }
@@ -29,7 +31,8 @@ func NewResponse(
 	res rinq.Response,
 	peerID ident.PeerID,
 	traceID string,
-	logger twelf.Logger,
+	logger logging.Logger,
+	redactor redact.Redactor,
 	span opentracing.Span,
 ) rinq.Response {
 	return &response{
@@ -39,6 +42,7 @@ func NewResponse(
 		peerID:    peerID,
 		traceID:   traceID,
 		logger:    logger,
+		redactor:  redactor,
 		span:      span,
 		startedAt: time.Now(),
 	}
@@ -56,25 +60,28 @@ func (r *response) Done(payload *rinq.Payload) {
 	r.res.Done(payload)
 	r.logSuccess(payload)
 
-	opentr.LogServerSuccess(r.span, payload)
+	opentr.LogServerSuccess(r.span, r.redactor, r.req.Namespace, r.req.Command, payload)
 }
 
 func (r *response) Error(err error) {
-	r.res.Error(err)
+	if failure, ok := rinq.FailureFromError(err); ok {
+		defer failure.Payload.Close()
 
-	if failure, ok := err.(rinq.Failure); ok {
+		err = failure
+		r.res.Error(err)
 		r.logFailure(failure.Type, failure.Payload)
 	} else {
+		r.res.Error(err)
 		r.logError(err)
 	}
 
-	opentr.LogServerError(r.span, err)
+	opentr.LogServerError(r.span, r.redactor, r.req.Namespace, r.req.Command, err)
 }
 
 func (r *response) Fail(f, t string, v ...interface{}) rinq.Failure {
 	err := r.res.Fail(f, t, v...)
 	r.logFailure(f, nil)
-	opentr.LogServerError(r.span, err)
+	opentr.LogServerError(r.span, r.redactor, r.req.Namespace, r.req.Command, err)
 
 	return err
 }
@@ -82,7 +89,7 @@ func (r *response) Fail(f, t string, v ...interface{}) rinq.Failure {
 func (r *response) Close() bool {
 	if r.res.Close() {
 		r.logSuccess(nil)
-		opentr.LogServerSuccess(r.span, nil)
+		opentr.LogServerSuccess(r.span, r.redactor, r.req.Namespace, r.req.Command, nil)
 		return true
 	}
 
@@ -90,13 +97,27 @@ func (r *response) Close() bool {
 }
 
 func (r *response) logSuccess(payload *rinq.Payload) {
+	elapsed := time.Since(r.startedAt)
+
 	r.logger.Log(
+		[]logging.Field{
+			logging.Peer(r.peerID),
+			logging.Namespace(r.req.Namespace),
+			logging.Command(r.req.Command),
+			logging.MessageID(r.req.ID),
+			logging.Session(r.req.ID.Ref.ID),
+			logging.Revision(r.req.ID.Ref.Rev),
+			logging.Duration(elapsed),
+			logging.InputSize(r.req.Payload.Len()),
+			logging.OutputSize(payload.Len()),
+			logging.TraceID(r.traceID),
+		},
 		"%s handled '%s::%s' command from %s successfully (%dms %d/i %d/o) [%s]",
 		r.peerID.ShortString(),
 		r.req.Namespace,
 		r.req.Command,
 		r.req.ID.Ref.ShortString(),
-		time.Since(r.startedAt)/time.Millisecond,
+		elapsed/time.Millisecond,
 		r.req.Payload.Len(),
 		payload.Len(),
 		r.traceID,
@@ -104,14 +125,29 @@ func (r *response) logSuccess(payload *rinq.Payload) {
 }
 
 func (r *response) logFailure(failureType string, payload *rinq.Payload) {
+	elapsed := time.Since(r.startedAt)
+
 	r.logger.Log(
+		[]logging.Field{
+			logging.Peer(r.peerID),
+			logging.Namespace(r.req.Namespace),
+			logging.Command(r.req.Command),
+			logging.MessageID(r.req.ID),
+			logging.Session(r.req.ID.Ref.ID),
+			logging.Revision(r.req.ID.Ref.Rev),
+			logging.FailureType(failureType),
+			logging.Duration(elapsed),
+			logging.InputSize(r.req.Payload.Len()),
+			logging.OutputSize(payload.Len()),
+			logging.TraceID(r.traceID),
+		},
 		"%s handled '%s::%s' command from %s: '%s' failure (%dms %d/i %d/o) [%s]",
 		r.peerID.ShortString(),
 		r.req.Namespace,
 		r.req.Command,
 		r.req.ID.Ref.ShortString(),
 		failureType,
-		time.Since(r.startedAt)/time.Millisecond,
+		elapsed/time.Millisecond,
 		r.req.Payload.Len(),
 		payload.Len(),
 		r.traceID,
@@ -119,14 +155,28 @@ func (r *response) logFailure(failureType string, payload *rinq.Payload) {
 }
 
 func (r *response) logError(err error) {
+	elapsed := time.Since(r.startedAt)
+
 	r.logger.Log(
+		[]logging.Field{
+			logging.Peer(r.peerID),
+			logging.Namespace(r.req.Namespace),
+			logging.Command(r.req.Command),
+			logging.MessageID(r.req.ID),
+			logging.Session(r.req.ID.Ref.ID),
+			logging.Revision(r.req.ID.Ref.Rev),
+			logging.Error(err),
+			logging.Duration(elapsed),
+			logging.InputSize(r.req.Payload.Len()),
+			logging.TraceID(r.traceID),
+		},
 		"%s handled '%s::%s' command from %s: '%s' error (%dms %d/i 0/o) [%s]",
 		r.peerID.ShortString(),
 		r.req.Namespace,
 		r.req.Command,
 		r.req.ID.Ref.ShortString(),
 		err,
-		time.Since(r.startedAt)/time.Millisecond,
+		elapsed/time.Millisecond,
 		r.req.Payload.Len(),
 		r.traceID,
 	)
@@ -11,4 +11,10 @@ type Server interface {
 
 	Listen(ns string, h rinq.CommandHandler) (bool, error)
 	Unlisten(ns string) (bool, error)
+
+	// Namespaces returns the namespaces that the server is listening to.
+	Namespaces() []string
+
+	// Pending returns the number of command requests currently being handled.
+	Pending() int
 }
@@ -27,11 +27,18 @@ func SharedPeer() rinq.Peer {
 }
 
 // NewPeer returns a new peer for use in functional tests.
-func NewPeer() rinq.Peer {
+//
+// opts are applied after the default functional test options.
+func NewPeer(opts ...options.Option) rinq.Peer {
 	peer, err := rinqamqp.DialEnv(
-		options.Logger(
-			&twelf.StandardLogger{CaptureDebug: true},
-		),
+		append(
+			[]options.Option{
+				options.Logger(
+					&twelf.StandardLogger{CaptureDebug: true},
+				),
+			},
+			opts...,
+		)...,
 	)
 
 	if err != nil {
@@ -0,0 +1,93 @@
+package introspection
+
+import (
+	"context"
+	"sync/atomic"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/rinq/rinq-go/src/internal/attributes"
+	"github.com/rinq/rinq-go/src/internal/command"
+	"github.com/rinq/rinq-go/src/internal/opentr"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/redact"
+	"github.com/rinq/rinq-go/src/rinq/trace"
+)
+
+// Client makes introspection requests to other peers.
+type Client struct {
+	peerID  ident.PeerID
+	invoker command.Invoker
+	tracer  opentracing.Tracer
+	seq     uint32
+}
+
+// NewClient returns a new introspection client.
+func NewClient(
+	peerID ident.PeerID,
+	invoker command.Invoker,
+	tracer opentracing.Tracer,
+) *Client {
+	return &Client{
+		peerID:  peerID,
+		invoker: invoker,
+		tracer:  tracer,
+	}
+}
+
+// Inspect fetches runtime diagnostic information from the target peer.
+func (c *Client) Inspect(
+	ctx context.Context,
+	target ident.PeerID,
+) (info rinq.PeerInfo, err error) {
+	msgID, traceID := c.nextMessageID(ctx)
+
+	span, ctx := opentr.ChildOf(ctx, c.tracer, ext.SpanKindRPCClient)
+	defer span.Finish()
+
+	opentr.SetupCommand(span, msgID, peerNamespace, infoCommand)
+	opentr.AddTraceID(span, traceID)
+	opentr.LogInvokerCall(span, redact.None, peerNamespace, infoCommand, attributes.Catalog{}, nil)
+
+	in, err := c.invoker.CallUnicast(
+		ctx,
+		msgID,
+		traceID,
+		target,
+		peerNamespace,
+		infoCommand,
+		nil,
+	)
+	defer in.Close()
+
+	if err == nil {
+		err = in.Decode(&info)
+	}
+
+	if err != nil {
+		opentr.LogInvokerError(span, redact.None, peerNamespace, infoCommand, err)
+		return
+	}
+
+	opentr.LogInvokerSuccess(span, redact.None, peerNamespace, infoCommand, in)
+
+	return
+}
+
+// nextMessageID returns a new message ID for an introspection request.
+//
+// Message IDs are allocated from revision 1 of the peer's zero session, so
+// that they do not collide with those used by the remote session client,
+// which allocates IDs from revision 0.
+func (c *Client) nextMessageID(ctx context.Context) (msgID ident.MessageID, traceID string) {
+	seq := atomic.AddUint32(&c.seq, 1)
+	msgID = c.peerID.Session(0).At(1).Message(seq)
+	traceID = trace.Get(ctx)
+
+	if traceID == "" {
+		traceID = msgID.String()
+	}
+
+	return
+}
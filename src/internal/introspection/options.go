@@ -0,0 +1,35 @@
+package introspection
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/rinq/rinq-go/src/rinq/options"
+)
+
+// DescribeOptions returns a human-readable description of opts, keyed by
+// option name, for inclusion in rinq.PeerInfo.
+func DescribeOptions(opts options.Options) map[string]string {
+	m := map[string]string{
+		"default-timeout":       opts.DefaultTimeout.String(),
+		"command-workers":       strconv.FormatUint(uint64(opts.CommandWorkers), 10),
+		"session-workers":       strconv.FormatUint(uint64(opts.SessionWorkers), 10),
+		"prune-interval":        opts.PruneInterval.String(),
+		"product":               opts.Product,
+		"logger":                fmt.Sprintf("%T", opts.StructuredLogger),
+		"debug":                 strconv.FormatBool(opts.StructuredLogger.IsDebug()),
+		"tracer":                fmt.Sprintf("%T", opts.Tracer),
+		"metrics":               fmt.Sprintf("%T", opts.Metrics),
+		"redactor":              fmt.Sprintf("%T", opts.Redactor),
+		"introspection":         strconv.FormatBool(opts.Introspection),
+		"session-migration":     strconv.FormatBool(opts.MigrationHandler != nil),
+		"compression":           string(opts.Compression),
+		"compression-threshold": strconv.FormatUint(uint64(opts.CompressionThreshold), 10),
+	}
+
+	if opts.Compression == options.NoCompression {
+		m["compression"] = "none"
+	}
+
+	return m
+}
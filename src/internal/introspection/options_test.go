@@ -0,0 +1,43 @@
+package introspection_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/internal/introspection"
+	"github.com/rinq/rinq-go/src/rinq/options"
+)
+
+var _ = Describe("DescribeOptions", func() {
+	It("describes the options", func() {
+		opts, err := options.NewOptions(
+			options.DefaultTimeout(3*time.Second),
+			options.CommandWorkers(7),
+			options.Product("my-app/1.0.0"),
+			options.Introspection(true),
+			options.Compression(options.Gzip, 1024),
+		)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		m := introspection.DescribeOptions(opts)
+
+		Expect(m).To(HaveKeyWithValue("default-timeout", "3s"))
+		Expect(m).To(HaveKeyWithValue("command-workers", "7"))
+		Expect(m).To(HaveKeyWithValue("product", "my-app/1.0.0"))
+		Expect(m).To(HaveKeyWithValue("introspection", "true"))
+		Expect(m).To(HaveKeyWithValue("session-migration", "false"))
+		Expect(m).To(HaveKeyWithValue("compression", "gzip"))
+		Expect(m).To(HaveKeyWithValue("compression-threshold", "1024"))
+		Expect(m).To(HaveKeyWithValue("tracer", "opentracing.NoopTracer"))
+	})
+
+	It("describes disabled compression as 'none'", func() {
+		opts, err := options.NewOptions()
+		Expect(err).ShouldNot(HaveOccurred())
+
+		m := introspection.DescribeOptions(opts)
+
+		Expect(m).To(HaveKeyWithValue("compression", "none"))
+	})
+})
@@ -0,0 +1,3 @@
+// Package introspection implements the internal "_peer" namespace, which
+// provides runtime diagnostic information about a peer to other peers.
+package introspection
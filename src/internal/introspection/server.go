@@ -0,0 +1,40 @@
+package introspection
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rinq/rinq-go/src/internal/command"
+	"github.com/rinq/rinq-go/src/rinq"
+)
+
+// Listen attaches the introspection service to the given command server.
+//
+// info is called to produce the information returned to each request.
+func Listen(
+	svr command.Server,
+	info func() rinq.PeerInfo,
+) error {
+	_, err := svr.Listen(
+		peerNamespace,
+		func(
+			ctx context.Context,
+			req rinq.Request,
+			res rinq.Response,
+		) {
+			defer req.Payload.Close()
+
+			switch req.Command {
+			case infoCommand:
+				payload := rinq.NewPayload(info())
+				defer payload.Close()
+
+				res.Done(payload)
+			default:
+				res.Error(errors.New("unknown command"))
+			}
+		},
+	)
+
+	return err
+}
@@ -0,0 +1,9 @@
+package introspection
+
+const (
+	peerNamespace = "_peer"
+)
+
+const (
+	infoCommand = "info"
+)
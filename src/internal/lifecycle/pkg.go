@@ -0,0 +1,3 @@
+// Package lifecycle delivers the events that describe changes to local
+// sessions to the channels returned by Peer.SessionEvents().
+package lifecycle
@@ -0,0 +1,130 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rinq/rinq-go/src/internal/x/syncx"
+	"github.com/rinq/rinq-go/src/rinq"
+)
+
+// Stream delivers session events to any number of subscribers.
+//
+// Events are published to the stream without blocking, and delivered to each
+// subscriber in the order in which they were published. The zero value is
+// ready to use.
+type Stream struct {
+	mutex       sync.Mutex
+	subscribers map[*subscriber]struct{}
+	closed      bool
+}
+
+// Subscribe returns a channel that receives each event published after the
+// call. The channel is closed when ctx is canceled, or once the stream is
+// closed and all queued events have been delivered.
+func (s *Stream) Subscribe(ctx context.Context) <-chan rinq.SessionEvent {
+	sub := &subscriber{
+		out: make(chan rinq.SessionEvent),
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		close(sub.out)
+		return sub.out
+	}
+
+	if s.subscribers == nil {
+		s.subscribers = map[*subscriber]struct{}{}
+	}
+	s.subscribers[sub] = struct{}{}
+
+	go func() {
+		sub.run(ctx)
+
+		s.mutex.Lock()
+		delete(s.subscribers, sub)
+		s.mutex.Unlock()
+	}()
+
+	return sub.out
+}
+
+// IsActive returns true if there is at least one subscriber. It allows
+// publishers to avoid building events that would not be delivered.
+func (s *Stream) IsActive() bool {
+	if s == nil {
+		return false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.subscribers) != 0
+}
+
+// Publish queues events for delivery to each subscriber.
+func (s *Stream) Publish(events ...rinq.SessionEvent) {
+	if s == nil || len(events) == 0 {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for sub := range s.subscribers {
+		sub.push(events)
+	}
+}
+
+// Close closes the channels of all subscribers once any queued events have
+// been delivered. Channels returned by subsequent calls to Subscribe() are
+// closed immediately.
+func (s *Stream) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+
+	for sub := range s.subscribers {
+		sub.close()
+	}
+}
+
+// subscriber queues events for delivery to a single channel.
+type subscriber struct {
+	out   chan rinq.SessionEvent
+	queue syncx.Queue
+}
+
+// push queues events for delivery.
+func (s *subscriber) push(events []rinq.SessionEvent) {
+	values := make([]interface{}, len(events))
+	for i, e := range events {
+		values[i] = e
+	}
+
+	s.queue.Push(values...)
+}
+
+// close stops the subscriber once any queued events have been delivered.
+func (s *subscriber) close() {
+	s.queue.Close()
+}
+
+// run delivers queued events to s.out until ctx is canceled, or the
+// subscriber is closed and all queued events have been delivered. s.out is
+// closed when run returns.
+func (s *subscriber) run(ctx context.Context) {
+	defer close(s.out)
+
+	s.queue.Run(ctx, func(v interface{}) bool {
+		select {
+		case s.out <- v.(rinq.SessionEvent):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
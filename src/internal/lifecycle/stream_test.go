@@ -0,0 +1,118 @@
+package lifecycle_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rinq/rinq-go/src/internal/lifecycle"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+var _ = Describe("Stream", func() {
+	var (
+		ctx     context.Context
+		cancel  func()
+		subject *Stream
+		ref     ident.Ref
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		subject = &Stream{}
+		ref = ident.NewPeerID().Session(1).At(1)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	event := func(t rinq.SessionEventType) rinq.SessionEvent {
+		return rinq.SessionEvent{Type: t, Ref: ref}
+	}
+
+	Describe("Publish", func() {
+		It("delivers events to each subscriber in order", func() {
+			a := subject.Subscribe(ctx)
+			b := subject.Subscribe(ctx)
+
+			subject.Publish(event(rinq.SessionCreated), event(rinq.SessionUpdated))
+
+			for _, c := range []<-chan rinq.SessionEvent{a, b} {
+				Eventually(c).Should(Receive(Equal(event(rinq.SessionCreated))))
+				Eventually(c).Should(Receive(Equal(event(rinq.SessionUpdated))))
+			}
+		})
+
+		It("does not deliver events published before the subscription", func() {
+			subject.Publish(event(rinq.SessionCreated))
+
+			c := subject.Subscribe(ctx)
+
+			Consistently(c).ShouldNot(Receive())
+		})
+
+		It("does not block when events are not being received", func() {
+			subject.Subscribe(ctx)
+
+			for i := 0; i < 100; i++ {
+				subject.Publish(event(rinq.SessionUpdated))
+			}
+		})
+
+		It("can be called on a nil stream", func() {
+			subject = nil
+
+			subject.Publish(event(rinq.SessionCreated))
+		})
+	})
+
+	Describe("IsActive", func() {
+		It("returns false if there are no subscribers", func() {
+			Expect(subject.IsActive()).To(BeFalse())
+		})
+
+		It("returns true if there are subscribers", func() {
+			subject.Subscribe(ctx)
+
+			Expect(subject.IsActive()).To(BeTrue())
+		})
+
+		It("returns false once the subscribers' contexts are canceled", func() {
+			subject.Subscribe(ctx)
+			cancel()
+
+			Eventually(subject.IsActive).Should(BeFalse())
+		})
+	})
+
+	Describe("Subscribe", func() {
+		It("closes the channel when the context is canceled", func() {
+			c := subject.Subscribe(ctx)
+			cancel()
+
+			Eventually(c).Should(BeClosed())
+		})
+
+		It("returns a closed channel if the stream is closed", func() {
+			subject.Close()
+
+			c := subject.Subscribe(ctx)
+
+			Expect(c).To(BeClosed())
+		})
+	})
+
+	Describe("Close", func() {
+		It("closes the channel after queued events are delivered", func() {
+			c := subject.Subscribe(ctx)
+
+			subject.Publish(event(rinq.SessionDestroyed))
+			subject.Close()
+
+			Eventually(c).Should(Receive(Equal(event(rinq.SessionDestroyed))))
+			Eventually(c).Should(BeClosed())
+		})
+	})
+})
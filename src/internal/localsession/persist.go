@@ -0,0 +1,102 @@
+package localsession
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rinq/rinq-go/src/internal/attributes"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/sessionstore"
+)
+
+// toState returns the persisted representation of a session at ref with the
+// given attributes.
+func toState(ref ident.Ref, attrs attributes.Catalog) sessionstore.Session {
+	s := sessionstore.Session{
+		ID:  ref.ID,
+		Rev: ref.Rev,
+	}
+
+	for ns, t := range attrs {
+		if len(t) == 0 {
+			continue
+		}
+
+		if s.Attrs == nil {
+			s.Attrs = map[string][]sessionstore.Attr{}
+		}
+
+		list := make([]sessionstore.Attr, 0, len(t))
+		for _, attr := range t {
+			list = append(list, sessionstore.Attr{
+				Attr:      attr.Attr,
+				CreatedAt: attr.CreatedAt,
+				UpdatedAt: attr.UpdatedAt,
+				ExpiresAt: attr.ExpiresAt,
+			})
+		}
+
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Key < list[j].Key
+		})
+
+		s.Attrs[ns] = list
+	}
+
+	return s
+}
+
+// state returns the persisted representation of the session at ref with the
+// given attributes, including the session's expiry. It must be called with the
+// mutex held.
+func (s *Session) state(ref ident.Ref, attrs attributes.Catalog) sessionstore.Session {
+	state := toState(ref, attrs)
+	state.IdleTimeout = s.expiry.IdleTimeout
+
+	if !s.expiresAt.IsZero() {
+		state.ExpiresAt = s.expiresAt.UnixNano()
+	}
+
+	return state
+}
+
+// restoreExpiry starts the expiry timers of a session with the persisted
+// state in state. The idle timeout is measured from the time the session is
+// restored. It must be called with the mutex held for writing.
+func (s *Session) restoreExpiry(state sessionstore.Session) {
+	now := time.Now()
+	s.expiry.IdleTimeout = state.IdleTimeout
+	s.activeAt = now
+
+	if state.ExpiresAt != 0 {
+		s.expiresAt = time.Unix(0, state.ExpiresAt)
+		s.expiry.TTL = s.expiresAt.Sub(now)
+		s.ttlTimer = time.AfterFunc(s.expiry.TTL, s.expire)
+	}
+
+	if state.IdleTimeout != 0 {
+		s.idleTimer = time.AfterFunc(state.IdleTimeout, s.expire)
+	}
+}
+
+// fromState returns the attribute catalog of a persisted session.
+func fromState(s sessionstore.Session) attributes.Catalog {
+	cat := attributes.Catalog{}
+
+	for ns, list := range s.Attrs {
+		t := attributes.VTable{}
+
+		for _, attr := range list {
+			t[attr.Key] = attributes.VAttr{
+				Attr:      attr.Attr,
+				CreatedAt: attr.CreatedAt,
+				UpdatedAt: attr.UpdatedAt,
+				ExpiresAt: attr.ExpiresAt,
+			}
+		}
+
+		cat[ns] = t
+	}
+
+	return cat
+}
@@ -2,12 +2,13 @@ package localsession
 
 import (
 	"context"
+	"time"
 
-	"github.com/jmalloc/twelf/src/twelf"
 	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/internal/namespaces"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
 	"github.com/rinq/rinq-go/src/rinq/trace"
 )
 
@@ -15,7 +16,7 @@ type revision struct {
 	ref     ident.Ref
 	session *Session
 	attrs   attributes.Catalog
-	logger  twelf.Logger
+	logger  logging.Logger
 }
 
 func (r *revision) SessionID() ident.SessionID {
@@ -46,7 +47,8 @@ func (r *revision) Get(ctx context.Context, ns, key string) (rinq.Attr, error) {
 		return rinq.Attr{}, rinq.StaleFetchError{Ref: r.ref}
 	}
 
-	return attr.Attr, nil
+	// The attribute may have expired before the expiry timer has cleared it.
+	return attr.AttrAt(time.Now()), nil
 }
 
 func (r *revision) GetMany(ctx context.Context, ns string, keys ...string) (rinq.AttrTable, error) {
@@ -54,6 +56,7 @@ func (r *revision) GetMany(ctx context.Context, ns string, keys ...string) (rinq
 
 	attrs := r.attrs[ns]
 	table := attributes.Table{}
+	now := time.Now()
 
 	for _, key := range keys {
 		attr, ok := attrs[key]
@@ -63,7 +66,7 @@ func (r *revision) GetMany(ctx context.Context, ns string, keys ...string) (rinq
 			table[key] = rinq.Attr{Key: key}
 		} else if attr.UpdatedAt <= r.ref.Rev {
 			// The attribute was updated before this revision, it's still valid.
-			table[key] = attr.Attr
+			table[key] = attr.AttrAt(now)
 		} else {
 			return nil, rinq.StaleFetchError{Ref: r.ref}
 		}
@@ -72,6 +75,26 @@ func (r *revision) GetMany(ctx context.Context, ns string, keys ...string) (rinq
 	return table, nil
 }
 
+func (r *revision) GetAll(ctx context.Context, ns string) (rinq.AttrTable, error) {
+	namespaces.MustValidate(ns)
+
+	table, ok := r.attrs[ns].At(r.ref.Rev, time.Now())
+	if !ok {
+		return nil, rinq.StaleFetchError{Ref: r.ref}
+	}
+
+	return table, nil
+}
+
+func (r *revision) Namespaces(ctx context.Context) ([]string, error) {
+	names, ok := r.attrs.NamespacesAt(r.ref.Rev)
+	if !ok {
+		return nil, rinq.StaleFetchError{Ref: r.ref}
+	}
+
+	return names, nil
+}
+
 func (r *revision) Update(ctx context.Context, ns string, attrs ...rinq.Attr) (rinq.Revision, error) {
 	namespaces.MustValidate(ns)
 
@@ -79,7 +102,23 @@ func (r *revision) Update(ctx context.Context, ns string, attrs ...rinq.Attr) (r
 		return r, nil
 	}
 
-	rev, diff, err := r.session.TryUpdate(r.ref.Rev, ns, attrs)
+	rev, diff, _, err := r.session.TryUpdate(r.ref.Rev, ns, nil, attrs, r.source(ctx))
+	if err != nil {
+		return r, err
+	}
+
+	logUpdate(ctx, r.logger, r.ref.ID.At(diff.Revision), diff)
+
+	return rev, nil
+}
+
+func (r *revision) UpdateIf(ctx context.Context, ns string, conds []rinq.Condition, attrs ...rinq.Attr) (rinq.Revision, error) {
+	namespaces.MustValidate(ns)
+	if len(conds) == 0 {
+		panic("at least one condition is required")
+	}
+
+	rev, diff, _, err := r.session.TryUpdate(r.ref.Rev, ns, conds, attrs, r.source(ctx))
 	if err != nil {
 		return r, err
 	}
@@ -89,10 +128,34 @@ func (r *revision) Update(ctx context.Context, ns string, attrs ...rinq.Attr) (r
 	return rev, nil
 }
 
+func (r *revision) UpdateMany(ctx context.Context, attrs map[string][]rinq.Attr) (rinq.Revision, error) {
+	for ns := range attrs {
+		namespaces.MustValidate(ns)
+	}
+
+	if len(attrs) == 0 {
+		return r, nil
+	}
+
+	changes := make(map[string]attributes.List, len(attrs))
+	for ns, l := range attrs {
+		changes[ns] = l
+	}
+
+	rev, diffs, err := r.session.TryUpdateMany(r.ref.Rev, changes, r.source(ctx))
+	if err != nil {
+		return r, err
+	}
+
+	logUpdate(ctx, r.logger, r.ref.ID.At(diffs.Revision), diffs)
+
+	return rev, nil
+}
+
 func (r *revision) Clear(ctx context.Context, ns string) (rinq.Revision, error) {
 	namespaces.MustValidate(ns)
 
-	rev, diff, err := r.session.TryClear(r.ref.Rev, ns)
+	rev, diff, err := r.session.TryClear(r.ref.Rev, ns, r.source(ctx))
 	if err != nil {
 		return r, err
 	}
@@ -102,8 +165,17 @@ func (r *revision) Clear(ctx context.Context, ns string) (rinq.Revision, error)
 	return rev, nil
 }
 
+func (r *revision) Watch(ctx context.Context, ns string, keys ...string) (<-chan rinq.Change, error) {
+	// an empty namespace watches every namespace.
+	if ns != "" {
+		namespaces.MustValidate(ns)
+	}
+
+	return r.session.Watch(ctx, r.ref.Rev, ns, keys)
+}
+
 func (r *revision) Destroy(ctx context.Context) error {
-	first, err := r.session.TryDestroy(r.ref.Rev)
+	first, err := r.session.TryDestroy(r.ref.Rev, r.source(ctx))
 	if err != nil {
 		return err
 	}
@@ -114,3 +186,9 @@ func (r *revision) Destroy(ctx context.Context) error {
 
 	return nil
 }
+
+// source returns the source of session events caused by operations on this
+// revision.
+func (r *revision) source(ctx context.Context) rinq.SessionEventSource {
+	return r.session.localSource(trace.Get(ctx))
+}
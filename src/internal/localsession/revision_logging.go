@@ -2,21 +2,27 @@ package localsession
 
 import (
 	"context"
+	"fmt"
 
-	"github.com/jmalloc/twelf/src/twelf"
 	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
 	"github.com/rinq/rinq-go/src/rinq/trace"
 )
 
 func logUpdate(
 	ctx context.Context,
-	logger twelf.Logger,
+	logger logging.Logger,
 	ref ident.Ref,
-	diff *attributes.Diff,
+	diff fmt.Stringer, // *attributes.Diff or *attributes.DiffSet
 ) {
 	if traceID := trace.Get(ctx); traceID != "" {
 		logger.Log(
+			[]logging.Field{
+				logging.Session(ref.ID),
+				logging.Revision(ref.Rev),
+				logging.TraceID(traceID),
+			},
 			"%s session updated %s [%s]",
 			ref.ShortString(),
 			diff,
@@ -24,6 +30,10 @@ func logUpdate(
 		)
 	} else {
 		logger.Log(
+			[]logging.Field{
+				logging.Session(ref.ID),
+				logging.Revision(ref.Rev),
+			},
 			"%s session updated %s",
 			ref.ShortString(),
 			diff,
@@ -33,12 +43,17 @@ func logUpdate(
 
 func logClear(
 	ctx context.Context,
-	logger twelf.Logger,
+	logger logging.Logger,
 	ref ident.Ref,
 	diff *attributes.Diff,
 ) {
 	if traceID := trace.Get(ctx); traceID != "" {
 		logger.Log(
+			[]logging.Field{
+				logging.Session(ref.ID),
+				logging.Revision(ref.Rev),
+				logging.TraceID(traceID),
+			},
 			"%s session cleared %s [%s]",
 			ref.ShortString(),
 			diff,
@@ -46,6 +61,10 @@ func logClear(
 		)
 	} else {
 		logger.Log(
+			[]logging.Field{
+				logging.Session(ref.ID),
+				logging.Revision(ref.Rev),
+			},
 			"%s session cleared %s",
 			ref.ShortString(),
 			diff,
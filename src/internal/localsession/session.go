@@ -5,19 +5,23 @@ import (
 	"sync"
 	"time"
 
-	"github.com/jmalloc/twelf/src/twelf"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/internal/command"
+	"github.com/rinq/rinq-go/src/internal/lifecycle"
 	"github.com/rinq/rinq-go/src/internal/namespaces"
 	"github.com/rinq/rinq-go/src/internal/notify"
 	"github.com/rinq/rinq-go/src/internal/opentr"
 	"github.com/rinq/rinq-go/src/internal/revisions"
+	"github.com/rinq/rinq-go/src/internal/watch"
 	"github.com/rinq/rinq-go/src/internal/x/syncx"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/constraint"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/redact"
+	"github.com/rinq/rinq-go/src/rinq/sessionstore"
 	"github.com/rinq/rinq-go/src/rinq/trace"
 )
 
@@ -31,39 +35,164 @@ type Session struct {
 	invoker  command.Invoker
 	notifier notify.Notifier
 	listener notify.Listener
-	logger   twelf.Logger
+	logger   logging.Logger
 	tracer   opentracing.Tracer
+	redactor redact.Redactor
+	store    sessionstore.Store
+	events   *lifecycle.Stream
 
 	mutex       sync.RWMutex
 	ref         ident.Ref
 	msgSeq      uint32
 	isDestroyed bool
+	isClosed    bool // destroyed without removing the persisted state
+	isExporting bool // closed by Export(), awaiting the outcome of migration
+	isMigrated  bool // closed because the session was migrated to another peer
 	attrs       attributes.Catalog
+	watchers    map[*watch.Watcher]struct{}
+	expiry      rinq.Expiry
+	expiresAt   time.Time   // zero if there is no TTL
+	activeAt    time.Time   // time of the most recent activity
+	ttlTimer    *time.Timer // nil if there is no TTL
+	idleTimer   *time.Timer // nil if there is no idle timeout
+	attrTimer   *time.Timer // nil if no attributes expire
+	attrsExpire int64       // earliest attribute expiry, zero if none
 	calls       sync.WaitGroup
 	done        chan struct{}
 }
 
-// NewSession returns a new local session.
+// NewSession returns a new local session, and persists its initial state to
+// store.
 func NewSession(
 	id ident.SessionID,
 	invoker command.Invoker,
 	notifier notify.Notifier,
 	listener notify.Listener,
-	logger twelf.Logger,
+	logger logging.Logger,
 	tracer opentracing.Tracer,
+	redactor redact.Redactor,
+	store sessionstore.Store,
+	events *lifecycle.Stream,
 ) *Session {
 	logCreated(logger, id)
 
-	return &Session{
+	s := &Session{
 		invoker:  invoker,
 		notifier: notifier,
 		listener: listener,
 		logger:   logger,
 		tracer:   tracer,
+		redactor: redactor,
+		store:    store,
+		events:   events,
 
 		ref:  id.At(0),
 		done: make(chan struct{}),
 	}
+
+	if err := store.Save(toState(s.ref, nil)); err != nil {
+		logPersistError(logger, s.ref, err)
+	}
+
+	s.publish(rinq.SessionCreated, s.localSource(""))
+
+	return s
+}
+
+// RestoreSession returns a local session with the persisted state in state.
+// Its expiry timers are restarted, so a session whose TTL has already elapsed
+// is destroyed immediately.
+//
+// Unlike NewSession() and AdoptSession(), no SessionCreated event is
+// published, as the session already existed before the peer restarted.
+func RestoreSession(
+	state sessionstore.Session,
+	invoker command.Invoker,
+	notifier notify.Notifier,
+	listener notify.Listener,
+	logger logging.Logger,
+	tracer opentracing.Tracer,
+	redactor redact.Redactor,
+	store sessionstore.Store,
+	events *lifecycle.Stream,
+) *Session {
+	s := &Session{
+		invoker:  invoker,
+		notifier: notifier,
+		listener: listener,
+		logger:   logger,
+		tracer:   tracer,
+		redactor: redactor,
+		store:    store,
+		events:   events,
+
+		ref:   state.ID.At(state.Rev),
+		attrs: fromState(state),
+		done:  make(chan struct{}),
+	}
+
+	logRestored(logger, s.ref, s.attrs)
+
+	s.mutex.Lock()
+	s.restoreExpiry(state)
+	s.scheduleAttrExpiry()
+	s.mutex.Unlock()
+
+	return s
+}
+
+// AdoptSession returns a local session with the state of a session that has
+// been migrated from another peer, and persists that state to store.
+//
+// The session is assigned a new ID, but retains the revision, attributes and
+// expiry in state.
+func AdoptSession(
+	id ident.SessionID,
+	state sessionstore.Session,
+	invoker command.Invoker,
+	notifier notify.Notifier,
+	listener notify.Listener,
+	logger logging.Logger,
+	tracer opentracing.Tracer,
+	redactor redact.Redactor,
+	store sessionstore.Store,
+	events *lifecycle.Stream,
+) *Session {
+	s := &Session{
+		invoker:  invoker,
+		notifier: notifier,
+		listener: listener,
+		logger:   logger,
+		tracer:   tracer,
+		redactor: redactor,
+		store:    store,
+		events:   events,
+
+		ref:   id.At(state.Rev),
+		attrs: fromState(state),
+		done:  make(chan struct{}),
+	}
+
+	saved := toState(s.ref, s.attrs)
+	saved.ExpiresAt = state.ExpiresAt
+	saved.IdleTimeout = state.IdleTimeout
+
+	if err := store.Save(saved); err != nil {
+		logPersistError(logger, s.ref, err)
+	}
+
+	logAdopted(logger, s.ref, state.ID, s.attrs)
+
+	s.publish(rinq.SessionCreated, rinq.SessionEventSource{Peer: state.ID.Peer})
+
+	// the timers are started after the SessionCreated event is published, as
+	// a session with an elapsed TTL is destroyed immediately.
+	s.mutex.Lock()
+	s.restoreExpiry(state)
+	s.scheduleAttrExpiry()
+	s.mutex.Unlock()
+
+	return s
 }
 
 // ID implements rinq.Session.ID()
@@ -98,6 +227,7 @@ func (s *Session) Call(ctx context.Context, ns, cmd string, out *rinq.Payload) (
 	}
 
 	msgID, traceID := s.nextMessageID(ctx)
+	s.touch()
 	attrs := s.attrs // capture for logging/tracing while mutex is locked
 
 	s.calls.Add(1)
@@ -112,16 +242,16 @@ func (s *Session) Call(ctx context.Context, ns, cmd string, out *rinq.Payload) (
 
 	opentr.SetupCommand(span, msgID, ns, cmd)
 	opentr.AddTraceID(span, traceID)
-	opentr.LogInvokerCall(span, attrs, out)
+	opentr.LogInvokerCall(span, s.redactor, ns, cmd, attrs, out)
 
 	start := time.Now()
 	in, err := s.invoker.CallBalanced(ctx, msgID, traceID, ns, cmd, out)
-	elapsed := time.Since(start) / time.Millisecond
+	elapsed := time.Since(start)
 
 	if err == nil {
-		opentr.LogInvokerSuccess(span, in)
+		opentr.LogInvokerSuccess(span, s.redactor, ns, cmd, in)
 	} else {
-		opentr.LogInvokerError(span, err)
+		opentr.LogInvokerError(span, s.redactor, ns, cmd, err)
 	}
 
 	logCall(s.logger, msgID, ns, cmd, elapsed, out, in, err, traceID)
@@ -141,18 +271,19 @@ func (s *Session) CallAsync(ctx context.Context, ns, cmd string, out *rinq.Paylo
 	}
 
 	msgID, traceID := s.nextMessageID(ctx)
+	s.touch()
 
 	span, ctx := opentr.ChildOf(ctx, s.tracer, ext.SpanKindRPCClient)
 	defer span.Finish()
 
 	opentr.SetupCommand(span, msgID, ns, cmd)
 	opentr.AddTraceID(span, traceID)
-	opentr.LogInvokerCallAsync(span, s.attrs, out)
+	opentr.LogInvokerCallAsync(span, s.redactor, ns, cmd, s.attrs, out)
 
 	err := s.invoker.CallBalancedAsync(ctx, msgID, traceID, ns, cmd, out)
 
 	if err != nil {
-		opentr.LogInvokerError(span, err)
+		opentr.LogInvokerError(span, s.redactor, ns, cmd, err)
 	}
 
 	logAsyncRequest(s.logger, msgID, ns, cmd, out, err, traceID)
@@ -188,9 +319,9 @@ func (s *Session) SetAsyncHandler(h rinq.AsyncHandler) error {
 			opentr.AddTraceID(span, trace.Get(ctx))
 
 			if err == nil {
-				opentr.LogInvokerSuccess(span, in)
+				opentr.LogInvokerSuccess(span, s.redactor, ns, cmd, in)
 			} else {
-				opentr.LogInvokerError(span, err)
+				opentr.LogInvokerError(span, s.redactor, ns, cmd, err)
 			}
 
 			logAsyncResponse(ctx, s.logger, msgID, ns, cmd, in, err)
@@ -214,18 +345,19 @@ func (s *Session) Execute(ctx context.Context, ns, cmd string, p *rinq.Payload)
 	}
 
 	msgID, traceID := s.nextMessageID(ctx)
+	s.touch()
 
 	span, ctx := opentr.ChildOf(ctx, s.tracer, ext.SpanKindRPCClient)
 	defer span.Finish()
 
 	opentr.SetupCommand(span, msgID, ns, cmd)
 	opentr.AddTraceID(span, traceID)
-	opentr.LogInvokerExecute(span, s.attrs, p)
+	opentr.LogInvokerExecute(span, s.redactor, ns, cmd, s.attrs, p)
 
 	err := s.invoker.ExecuteBalanced(ctx, msgID, traceID, ns, cmd, p)
 
 	if err != nil {
-		opentr.LogInvokerError(span, err)
+		opentr.LogInvokerError(span, s.redactor, ns, cmd, err)
 	}
 
 	logExecute(s.logger, msgID, ns, cmd, p, err, traceID)
@@ -249,13 +381,14 @@ func (s *Session) Notify(ctx context.Context, ns, t string, target ident.Session
 	}
 
 	msgID, traceID := s.nextMessageID(ctx)
+	s.touch()
 
 	span, ctx := opentr.ChildOf(ctx, s.tracer, ext.SpanKindProducer)
 	defer span.Finish()
 
 	opentr.SetupNotification(span, msgID, ns, t)
 	opentr.AddTraceID(span, traceID)
-	opentr.LogNotifierUnicast(span, s.attrs, target, p)
+	opentr.LogNotifierUnicast(span, s.redactor, ns, t, s.attrs, target, p)
 
 	err := s.notifier.NotifyUnicast(ctx, msgID, traceID, target, ns, t, p)
 
@@ -280,13 +413,14 @@ func (s *Session) NotifyMany(ctx context.Context, ns, t string, con constraint.C
 	}
 
 	msgID, traceID := s.nextMessageID(ctx)
+	s.touch()
 
 	span, ctx := opentr.ChildOf(ctx, s.tracer, ext.SpanKindProducer)
 	defer span.Finish()
 
 	opentr.SetupNotification(span, msgID, ns, t)
 	opentr.AddTraceID(span, traceID)
-	opentr.LogNotifierMulticast(span, s.attrs, con, p)
+	opentr.LogNotifierMulticast(span, s.redactor, ns, t, s.attrs, con, p)
 
 	err := s.notifier.NotifyMulticast(ctx, msgID, traceID, con, ns, t, p)
 
@@ -334,7 +468,7 @@ func (s *Session) Listen(ns string, h rinq.NotificationHandler) error {
 
 			opentr.SetupNotification(span, n.ID, n.Namespace, n.Type)
 			opentr.AddTraceID(span, traceID)
-			opentr.LogListenerReceived(span, ref, n)
+			opentr.LogListenerReceived(span, s.redactor, ref, n)
 
 			logNotifyRecv(s.logger, ref, n, traceID)
 
@@ -373,13 +507,50 @@ func (s *Session) Unlisten(ns string) error {
 	return nil
 }
 
+// SetExpiry implements rinq.Session.SetExpiry()
+func (s *Session) SetExpiry(e rinq.Expiry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isDestroyed {
+		return rinq.NotFoundError{ID: s.ref.ID}
+	}
+
+	s.stopTimers()
+
+	now := time.Now()
+	s.expiry = e
+	s.activeAt = now
+	s.expiresAt = time.Time{}
+
+	if e.TTL != 0 {
+		s.expiresAt = now.Add(e.TTL)
+		s.ttlTimer = time.AfterFunc(e.TTL, s.expire)
+	}
+
+	if e.IdleTimeout != 0 {
+		s.idleTimer = time.AfterFunc(e.IdleTimeout, s.expire)
+	}
+
+	// stopTimers() also stops the attribute expiry timer.
+	s.scheduleAttrExpiry()
+
+	if err := s.store.Save(s.state(s.ref, s.attrs)); err != nil {
+		logPersistError(s.logger, s.ref, err)
+	}
+
+	logExpiry(s.logger, s.ref, e)
+
+	return nil
+}
+
 // Destroy implements rinq.Session.Destroy()
 func (s *Session) Destroy() {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	if !s.isDestroyed {
-		s.destroy()
+		s.destroy(s.localSource(""))
 		logSessionDestroy(s.logger, s.ref, s.attrs, "")
 	}
 }
@@ -4,26 +4,29 @@ import (
 	"context"
 	"time"
 
-	"github.com/jmalloc/twelf/src/twelf"
 	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/constraint"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
 	"github.com/rinq/rinq-go/src/rinq/trace"
 )
 
 func logCreated(
-	logger twelf.Logger,
+	logger logging.Logger,
 	id ident.SessionID,
 ) {
 	logger.Log(
+		[]logging.Field{
+			logging.Session(id),
+		},
 		"%s session created",
 		id.At(0).ShortString(),
 	)
 }
 
 func logCall(
-	logger twelf.Logger,
+	logger logging.Logger,
 	msgID ident.MessageID,
 	ns string,
 	cmd string,
@@ -36,47 +39,84 @@ func logCall(
 	switch e := err.(type) {
 	case nil:
 		logger.Log(
+			[]logging.Field{
+				logging.MessageID(msgID),
+				logging.Namespace(ns),
+				logging.Command(cmd),
+				logging.Duration(elapsed),
+				logging.OutputSize(out.Len()),
+				logging.InputSize(in.Len()),
+				logging.TraceID(traceID),
+			},
 			"%s called '%s::%s' command: success (%dms, %d/o %d/i) [%s]",
 			msgID.ShortString(),
 			ns,
 			cmd,
-			elapsed,
+			elapsed/time.Millisecond,
 			out.Len(),
 			in.Len(),
 			traceID,
 		)
 	case rinq.Failure:
 		logger.Log(
+			[]logging.Field{
+				logging.MessageID(msgID),
+				logging.Namespace(ns),
+				logging.Command(cmd),
+				logging.FailureType(e.Type),
+				logging.Duration(elapsed),
+				logging.OutputSize(out.Len()),
+				logging.InputSize(in.Len()),
+				logging.TraceID(traceID),
+			},
 			"%s called '%s::%s' command: '%s' failure (%dms, %d/o %d/i) [%s]",
 			msgID.ShortString(),
 			ns,
 			cmd,
 			e.Type,
-			elapsed,
+			elapsed/time.Millisecond,
 			out.Len(),
 			in.Len(),
 			traceID,
 		)
 	case rinq.CommandError:
 		logger.Log(
+			[]logging.Field{
+				logging.MessageID(msgID),
+				logging.Namespace(ns),
+				logging.Command(cmd),
+				logging.Error(e),
+				logging.Duration(elapsed),
+				logging.OutputSize(out.Len()),
+				logging.TraceID(traceID),
+			},
 			"%s called '%s::%s' command: '%s' error (%dms, %d/o 0/i) [%s]",
 			msgID.ShortString(),
 			ns,
 			cmd,
 			e,
-			elapsed,
+			elapsed/time.Millisecond,
 			out.Len(),
 			traceID,
 		)
 	default:
 		if err == context.DeadlineExceeded || err == context.Canceled {
 			logger.Log(
+				[]logging.Field{
+					logging.MessageID(msgID),
+					logging.Namespace(ns),
+					logging.Command(cmd),
+					logging.Error(err),
+					logging.Duration(elapsed),
+					logging.OutputSize(out.Len()),
+					logging.TraceID(traceID),
+				},
 				"%s called '%s::%s' command: %s (%dms, %d/o -/i) [%s]",
 				msgID.ShortString(),
 				ns,
 				cmd,
 				err,
-				elapsed,
+				elapsed/time.Millisecond,
 				out.Len(),
 				traceID,
 			)
@@ -85,7 +125,7 @@ func logCall(
 }
 
 func logAsyncRequest(
-	logger twelf.Logger,
+	logger logging.Logger,
 	msgID ident.MessageID,
 	ns string,
 	cmd string,
@@ -98,6 +138,13 @@ func logAsyncRequest(
 	}
 
 	logger.Log(
+		[]logging.Field{
+			logging.MessageID(msgID),
+			logging.Namespace(ns),
+			logging.Command(cmd),
+			logging.OutputSize(out.Len()),
+			logging.TraceID(traceID),
+		},
 		"%s called '%s::%s' command asynchronously (%d/o) [%s]",
 		msgID.ShortString(),
 		ns,
@@ -109,7 +156,7 @@ func logAsyncRequest(
 
 func logAsyncResponse(
 	ctx context.Context,
-	logger twelf.Logger,
+	logger logging.Logger,
 	msgID ident.MessageID,
 	ns string,
 	cmd string,
@@ -119,6 +166,13 @@ func logAsyncResponse(
 	switch e := err.(type) {
 	case nil:
 		logger.Log(
+			[]logging.Field{
+				logging.MessageID(msgID),
+				logging.Namespace(ns),
+				logging.Command(cmd),
+				logging.InputSize(in.Len()),
+				logging.TraceID(trace.Get(ctx)),
+			},
 			"%s called '%s::%s' command asynchronously: success (%d/i) [%s]",
 			msgID.ShortString(),
 			ns,
@@ -128,6 +182,14 @@ func logAsyncResponse(
 		)
 	case rinq.Failure:
 		logger.Log(
+			[]logging.Field{
+				logging.MessageID(msgID),
+				logging.Namespace(ns),
+				logging.Command(cmd),
+				logging.FailureType(e.Type),
+				logging.InputSize(in.Len()),
+				logging.TraceID(trace.Get(ctx)),
+			},
 			"%s called '%s::%s' command asynchronously: '%s' failure (%d/i) [%s]",
 			msgID.ShortString(),
 			ns,
@@ -138,6 +200,13 @@ func logAsyncResponse(
 		)
 	case rinq.CommandError:
 		logger.Log(
+			[]logging.Field{
+				logging.MessageID(msgID),
+				logging.Namespace(ns),
+				logging.Command(cmd),
+				logging.Error(e),
+				logging.TraceID(trace.Get(ctx)),
+			},
 			"%s called '%s::%s' command asynchronously: '%s' error (0/i) [%s]",
 			msgID.ShortString(),
 			ns,
@@ -149,7 +218,7 @@ func logAsyncResponse(
 }
 
 func logExecute(
-	logger twelf.Logger,
+	logger logging.Logger,
 	msgID ident.MessageID,
 	ns string,
 	cmd string,
@@ -162,6 +231,13 @@ func logExecute(
 	}
 
 	logger.Log(
+		[]logging.Field{
+			logging.MessageID(msgID),
+			logging.Namespace(ns),
+			logging.Command(cmd),
+			logging.OutputSize(out.Len()),
+			logging.TraceID(traceID),
+		},
 		"%s executed '%s::%s' command (%d/o) [%s]",
 		msgID.ShortString(),
 		ns,
@@ -172,7 +248,7 @@ func logExecute(
 }
 
 func logNotify(
-	logger twelf.Logger,
+	logger logging.Logger,
 	msgID ident.MessageID,
 	ns string,
 	t string,
@@ -186,6 +262,14 @@ func logNotify(
 	}
 
 	logger.Log(
+		[]logging.Field{
+			logging.MessageID(msgID),
+			logging.Namespace(ns),
+			logging.NotificationType(t),
+			logging.Any("target", target.String()),
+			logging.OutputSize(out.Len()),
+			logging.TraceID(traceID),
+		},
 		"%s sent '%s::%s' notification to %s (%d/o) [%s]",
 		msgID.ShortString(),
 		ns,
@@ -197,7 +281,7 @@ func logNotify(
 }
 
 func logNotifyMany(
-	logger twelf.Logger,
+	logger logging.Logger,
 	msgID ident.MessageID,
 	ns string,
 	t string,
@@ -211,6 +295,13 @@ func logNotifyMany(
 	}
 
 	logger.Log(
+		[]logging.Field{
+			logging.MessageID(msgID),
+			logging.Namespace(ns),
+			logging.NotificationType(t),
+			logging.OutputSize(out.Len()),
+			logging.TraceID(traceID),
+		},
 		"%s sent '%s::%s' notification to sessions matching %s (%d/o) [%s]",
 		msgID.ShortString(),
 		ns,
@@ -222,12 +313,22 @@ func logNotifyMany(
 }
 
 func logNotifyRecv(
-	logger twelf.Logger,
+	logger logging.Logger,
 	ref ident.Ref,
 	n rinq.Notification,
 	traceID string,
 ) {
 	logger.Log(
+		[]logging.Field{
+			logging.Session(ref.ID),
+			logging.Revision(ref.Rev),
+			logging.Namespace(n.Namespace),
+			logging.NotificationType(n.Type),
+			logging.Session(n.ID.Ref.ID),
+			logging.Revision(n.ID.Ref.Rev),
+			logging.InputSize(n.Payload.Len()),
+			logging.TraceID(traceID),
+		},
 		"%s received '%s::%s' notification from %s (%d/i) [%s]",
 		ref.ShortString(),
 		n.Namespace,
@@ -239,23 +340,50 @@ func logNotifyRecv(
 }
 
 func logListen(
-	logger twelf.Logger,
+	logger logging.Logger,
 	ref ident.Ref,
 	ns string,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Session(ref.ID),
+			logging.Revision(ref.Rev),
+			logging.Namespace(ns),
+		},
 		"%s started listening for notifications in '%s' namespace",
 		ref.ShortString(),
 		ns,
 	)
 }
 
+func logExpiry(
+	logger logging.Logger,
+	ref ident.Ref,
+	e rinq.Expiry,
+) {
+	logger.Debug(
+		[]logging.Field{
+			logging.Session(ref.ID),
+			logging.Revision(ref.Rev),
+		},
+		"%s session expiry set (ttl: %s, idle timeout: %s)",
+		ref.ShortString(),
+		e.TTL,
+		e.IdleTimeout,
+	)
+}
+
 func logUnlisten(
-	logger twelf.Logger,
+	logger logging.Logger,
 	ref ident.Ref,
 	ns string,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Session(ref.ID),
+			logging.Revision(ref.Rev),
+			logging.Namespace(ns),
+		},
 		"%s stopped listening for notifications in '%s' namespace",
 		ref.ShortString(),
 		ns,
@@ -263,19 +391,28 @@ func logUnlisten(
 }
 
 func logSessionDestroy(
-	logger twelf.Logger,
+	logger logging.Logger,
 	ref ident.Ref,
 	attrs attributes.Catalog,
 	traceID string,
 ) {
 	if traceID == "" {
 		logger.Log(
+			[]logging.Field{
+				logging.Session(ref.ID),
+				logging.Revision(ref.Rev),
+			},
 			"%s session destroyed %s",
 			ref.ShortString(),
 			attrs,
 		)
 	} else {
 		logger.Log(
+			[]logging.Field{
+				logging.Session(ref.ID),
+				logging.Revision(ref.Rev),
+				logging.TraceID(traceID),
+			},
 			"%s session destroyed %s [%s]",
 			ref.ShortString(),
 			attrs,
@@ -283,3 +420,70 @@ func logSessionDestroy(
 		)
 	}
 }
+
+func logPersistError(
+	logger logging.Logger,
+	ref ident.Ref,
+	err error,
+) {
+	logger.Log(
+		[]logging.Field{
+			logging.Session(ref.ID),
+			logging.Revision(ref.Rev),
+			logging.Error(err),
+		},
+		"%s session state could not be persisted: %s",
+		ref.ShortString(),
+		err,
+	)
+}
+
+func logRestored(
+	logger logging.Logger,
+	ref ident.Ref,
+	attrs attributes.Catalog,
+) {
+	logger.Log(
+		[]logging.Field{
+			logging.Session(ref.ID),
+			logging.Revision(ref.Rev),
+		},
+		"%s session restored %s",
+		ref.ShortString(),
+		attrs,
+	)
+}
+
+func logAdopted(
+	logger logging.Logger,
+	ref ident.Ref,
+	from ident.SessionID,
+	attrs attributes.Catalog,
+) {
+	logger.Log(
+		[]logging.Field{
+			logging.Session(ref.ID),
+			logging.Revision(ref.Rev),
+		},
+		"%s session migrated from %s %s",
+		ref.ShortString(),
+		from.ShortString(),
+		attrs,
+	)
+}
+
+func logAttrsExpired(
+	logger logging.Logger,
+	ref ident.Ref,
+	diffs *attributes.DiffSet,
+) {
+	logger.Log(
+		[]logging.Field{
+			logging.Session(ref.ID),
+			logging.Revision(ref.Rev),
+		},
+		"%s session attributes expired %s",
+		ref.ShortString(),
+		diffs,
+	)
+}
@@ -3,10 +3,14 @@ package localsession
 import (
 	"context"
 	"errors"
+	"sort"
+	"time"
 
 	"github.com/rinq/rinq-go/src/internal/attributes"
+	"github.com/rinq/rinq-go/src/internal/watch"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/sessionstore"
 	"github.com/rinq/rinq-go/src/rinq/trace"
 )
 
@@ -48,11 +52,81 @@ func (s *Session) AttrsIn(ns string) (ident.Ref, attributes.VTable) {
 }
 
 // TryUpdate adds or updates attributes in the ns namespace of the attribute
-// table and returns the new head revision.
+// table and returns the new head revision, along with the revision at which
+// each attribute in attrs was created, in the same order.
+//
+// If conds is nil, the operation fails if ref is not the current session-ref.
+// Otherwise, rev is not checked and the operation fails if any of the
+// conditions are not satisfied by the current attribute values.
+//
+// The operation also fails if attrs includes changes to frozen attributes, or
+// the session has been destroyed.
+func (s *Session) TryUpdate(
+	rev ident.Revision,
+	ns string,
+	conds []rinq.Condition,
+	attrs attributes.List,
+	src rinq.SessionEventSource,
+) (rinq.Revision, *attributes.Diff, []ident.Revision, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isDestroyed {
+		return nil, nil, nil, rinq.NotFoundError{ID: s.ref.ID}
+	}
+
+	if conds == nil {
+		if rev != s.ref.Rev {
+			return nil, nil, nil, rinq.StaleUpdateError{Ref: s.ref.ID.At(rev)}
+		}
+	} else {
+		for _, c := range conds {
+			if !c.IsSatisfiedBy(s.attrs[ns][c.Key].Attr) {
+				return nil, nil, nil, rinq.ConditionFailedError{Ref: s.ref.ID.At(rev), Condition: c}
+			}
+		}
+
+		rev = s.ref.Rev
+	}
+
+	nextRev := rev + 1
+	nextCat, diff, err := s.apply(s.attrs, nextRev, ns, attrs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := s.commit(nextRev, nextCat, rinq.SessionUpdated, src, diff); err != nil {
+		return nil, nil, nil, err
+	}
+
+	s.touch()
+
+	created := make([]ident.Revision, 0, len(attrs))
+	for _, attr := range attrs {
+		created = append(created, s.attrs[ns][attr.Key].CreatedAt)
+	}
+
+	return &revision{
+		s.ref,
+		s,
+		s.attrs,
+		s.logger,
+	}, diff, created, nil
+}
+
+// TryUpdateMany adds or updates attributes in several namespaces of the
+// attribute table, keyed by namespace, and returns the new head revision.
+//
+// All of the changes are made in a single revision; either all attributes are
+// updated, or the attribute table remains unchanged.
 //
 // The operation fails if ref is not the current session-ref, attrs includes
 // changes to frozen attributes, or the session has been destroyed.
-func (s *Session) TryUpdate(rev ident.Revision, ns string, attrs attributes.List) (rinq.Revision, *attributes.Diff, error) {
+func (s *Session) TryUpdateMany(
+	rev ident.Revision,
+	attrs map[string]attributes.List,
+	src rinq.SessionEventSource,
+) (rinq.Revision, *attributes.DiffSet, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -65,22 +139,82 @@ func (s *Session) TryUpdate(rev ident.Revision, ns string, attrs attributes.List
 	}
 
 	nextRev := rev + 1
-	nextAttrs := s.attrs[ns].Clone()
+	nextCat := s.attrs
+	diffs := attributes.NewDiffSet(nextRev)
+
+	// namespaces are applied in order, so that the result, including which
+	// error is reported, does not depend on map iteration order.
+	names := make([]string, 0, len(attrs))
+	for ns := range attrs {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+
+	for _, ns := range names {
+		var (
+			diff *attributes.Diff
+			err  error
+		)
+
+		nextCat, diff, err = s.apply(nextCat, nextRev, ns, attrs[ns])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		diffs.Add(diff)
+	}
+
+	if err := s.commit(nextRev, nextCat, rinq.SessionUpdated, src, diffs.Diffs...); err != nil {
+		return nil, nil, err
+	}
+
+	s.touch()
+
+	return &revision{
+		s.ref,
+		s,
+		s.attrs,
+		s.logger,
+	}, diffs, nil
+}
+
+// apply returns the result of updating the attributes in the ns namespace of
+// cat at revision nextRev, along with a diff describing the changes.
+//
+// Attributes with a TTL are stored with the time at which they expire. Setting
+// an attribute without a TTL removes any existing expiry.
+//
+// It fails if attrs includes changes to frozen attributes.
+func (s *Session) apply(
+	cat attributes.Catalog,
+	nextRev ident.Revision,
+	ns string,
+	attrs attributes.List,
+) (attributes.Catalog, *attributes.Diff, error) {
+	nextAttrs := cat[ns].Clone()
 	diff := attributes.NewDiff(ns, nextRev)
+	now := time.Now()
 
 	for _, attr := range attrs {
 		entry, exists := nextAttrs[attr.Key]
 
-		if attr.Value == entry.Value && attr.IsFrozen == entry.IsFrozen {
+		var expiresAt int64
+		if attr.TTL != 0 && !attr.IsFrozen && attr.Value != "" {
+			expiresAt = now.Add(attr.TTL).UnixNano()
+		}
+		attr.TTL = 0
+
+		if attr == entry.Attr && expiresAt == entry.ExpiresAt {
 			continue
 		}
 
 		if entry.IsFrozen {
-			return nil, nil, rinq.FrozenAttributesError{Ref: s.ref.ID.At(rev)}
+			return nil, nil, rinq.FrozenAttributesError{Ref: s.ref.ID.At(nextRev - 1)}
 		}
 
 		entry.Attr = attr
 		entry.UpdatedAt = nextRev
+		entry.ExpiresAt = expiresAt
 		if !exists {
 			entry.CreatedAt = nextRev
 		}
@@ -89,19 +223,42 @@ func (s *Session) TryUpdate(rev ident.Revision, ns string, attrs attributes.List
 		diff.Append(entry)
 	}
 
+	if diff.IsEmpty() {
+		return cat, diff, nil
+	}
+
+	return cat.WithNamespace(ns, nextAttrs), diff, nil
+}
+
+// commit persists cat as the attributes at nextRev, then makes it the
+// current state of the session. diffs describe the changes made to produce
+// the new revision, they are delivered to watchers, and published as events
+// of type t.
+//
+// It must be called with the mutex held for writing.
+func (s *Session) commit(
+	nextRev ident.Revision,
+	cat attributes.Catalog,
+	t rinq.SessionEventType,
+	src rinq.SessionEventSource,
+	diffs ...*attributes.Diff,
+) error {
+	if err := s.store.Save(s.state(s.ref.ID.At(nextRev), cat)); err != nil {
+		return err
+	}
+
 	s.ref.Rev = nextRev
 	s.msgSeq = 0
+	s.attrs = cat
+	s.scheduleAttrExpiry()
 
-	if !diff.IsEmpty() {
-		s.attrs = s.attrs.WithNamespace(ns, nextAttrs)
+	for _, diff := range diffs {
+		s.notifyWatchers(diff)
 	}
 
-	return &revision{
-		s.ref,
-		s,
-		s.attrs,
-		s.logger,
-	}, diff, nil
+	s.publish(t, src, diffs...)
+
+	return nil
 }
 
 // TryClear updates all attributes in the ns namespace of the attribute
@@ -109,7 +266,11 @@ func (s *Session) TryUpdate(rev ident.Revision, ns string, attrs attributes.List
 //
 // The operation fails if ref is not the current session-ref, there are any
 // frozen attributes, or the session has been destroyed.
-func (s *Session) TryClear(rev ident.Revision, ns string) (rinq.Revision, *attributes.Diff, error) {
+func (s *Session) TryClear(
+	rev ident.Revision,
+	ns string,
+	src rinq.SessionEventSource,
+) (rinq.Revision, *attributes.Diff, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -134,19 +295,24 @@ func (s *Session) TryClear(rev ident.Revision, ns string) (rinq.Revision, *attri
 
 			entry.Value = ""
 			entry.UpdatedAt = nextRev
+			entry.ExpiresAt = 0
 			diff.Append(entry)
 		}
 
 		nextAttrs[entry.Key] = entry
 	}
 
-	s.ref.Rev = nextRev
-	s.msgSeq = 0
-
+	nextCat := s.attrs
 	if !diff.IsEmpty() {
-		s.attrs = s.attrs.WithNamespace(ns, nextAttrs)
+		nextCat = s.attrs.WithNamespace(ns, nextAttrs)
 	}
 
+	if err := s.commit(nextRev, nextCat, rinq.SessionCleared, src, diff); err != nil {
+		return nil, nil, err
+	}
+
+	s.touch()
+
 	return &revision{
 		s.ref,
 		s,
@@ -155,13 +321,62 @@ func (s *Session) TryClear(rev ident.Revision, ns string) (rinq.Revision, *attri
 	}, diff, nil
 }
 
+// Watch returns a channel that receives the changes made to the ns namespace
+// after rev. If keys is non-empty, only changes to those keys are delivered.
+// If ns is empty, changes to all namespaces are delivered.
+//
+// Changes made between rev and the current revision are delivered first, as
+// a single change per namespace, in namespace order. The channel is closed
+// when ctx is canceled, or after the session is destroyed.
+func (s *Session) Watch(ctx context.Context, rev ident.Revision, ns string, keys []string) (<-chan rinq.Change, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isDestroyed {
+		return nil, rinq.NotFoundError{ID: s.ref.ID}
+	}
+
+	w := watch.New(ns, keys)
+
+	if ns == "" {
+		names := make([]string, 0, len(s.attrs))
+		for n := range s.attrs {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+
+		for _, n := range names {
+			if c, ok := watch.Since(s.ref, n, s.attrs[n], rev); ok {
+				w.Push(c)
+			}
+		}
+	} else if c, ok := watch.Since(s.ref, ns, s.attrs[ns], rev); ok {
+		w.Push(c)
+	}
+
+	if s.watchers == nil {
+		s.watchers = map[*watch.Watcher]struct{}{}
+	}
+	s.watchers[w] = struct{}{}
+
+	go func() {
+		w.Run(ctx)
+
+		s.mutex.Lock()
+		delete(s.watchers, w)
+		s.mutex.Unlock()
+	}()
+
+	return w.C(), nil
+}
+
 // TryDestroy destroys the session, preventing further updates.
 //
 // The operation fails if ref is not the current session-ref. It is not an
 // error to destroy an already-destroyed session.
 //
 // first is true if this call caused the session to be destroyed.
-func (s *Session) TryDestroy(rev ident.Revision) (bool, error) {
+func (s *Session) TryDestroy(rev ident.Revision, src rinq.SessionEventSource) (bool, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -173,19 +388,126 @@ func (s *Session) TryDestroy(rev ident.Revision) (bool, error) {
 		return false, nil
 	}
 
-	s.destroy()
+	s.destroy(src)
 
 	return true, nil
 }
 
+// Close destroys the session without removing its persisted state, so that
+// it can be restored by a future peer with the same peer ID. It is used when
+// the owning peer stops.
+func (s *Session) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isDestroyed {
+		s.isClosed = true
+		s.destroy(rinq.SessionEventSource{})
+	}
+}
+
+// Export closes the session so that it can be migrated to another peer, and
+// returns its state along with the namespaces in which it is listening for
+// notifications. ok is false if the session has already been destroyed.
+//
+// The session no longer accepts updates, but its watchers remain open and its
+// persisted state is retained until either Migrated() or NotMigrated() is
+// called.
+func (s *Session) Export() (state sessionstore.Session, listening []string, ok bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isDestroyed {
+		return
+	}
+
+	state = s.state(s.ref, s.attrs)
+	listening = s.listener.Namespaces(s.ref.ID)
+	sort.Strings(listening)
+
+	s.isClosed = true
+	s.isExporting = true
+	s.destroy(rinq.SessionEventSource{})
+
+	return state, listening, true
+}
+
+// Migrated completes the export of a session that has been adopted by another
+// peer. Watchers are closed without being sent a destroyed change, as the
+// session continues to exist on the peer that it was migrated to.
+func (s *Session) Migrated() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isExporting {
+		s.isExporting = false
+		s.isMigrated = true
+		s.release()
+	}
+}
+
+// NotMigrated completes the export of a session that could not be migrated to
+// another peer. The session is destroyed as usual, its persisted state is
+// removed and a SessionDestroyed event is published.
+func (s *Session) NotMigrated() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isExporting {
+		s.isExporting = false
+		s.isClosed = false
+		s.remove(s.localSource(""))
+		s.release()
+		logSessionDestroy(s.logger, s.ref, s.attrs, "")
+	}
+}
+
 // destroy marks the session as destroyed removes any callbacks registered with
 // the command and notification subsystems.
-func (s *Session) destroy() {
+//
+// Unless the session is being closed, a SessionDestroyed event is published
+// with the given source.
+func (s *Session) destroy(src rinq.SessionEventSource) {
 	s.isDestroyed = true
+	s.stopTimers()
+
+	if !s.isClosed {
+		s.remove(src)
+	}
 
 	s.invoker.SetAsyncHandler(s.ref.ID, nil)
 	_ = s.listener.UnlistenAll(s.ref.ID)
 
+	// an exported session is released by Migrated() or NotMigrated(), once
+	// the outcome of the migration is known.
+	if !s.isExporting {
+		s.release()
+	}
+}
+
+// remove deletes the persisted state of a destroyed session, and publishes a
+// SessionDestroyed event with the given source. It must be called with the
+// mutex held for writing.
+func (s *Session) remove(src rinq.SessionEventSource) {
+	if err := s.store.Delete(s.ref.ID); err != nil {
+		logPersistError(s.logger, s.ref, err)
+	}
+
+	s.publish(rinq.SessionDestroyed, src)
+}
+
+// release closes the watchers of a destroyed session, and closes the done
+// channel once all pending calls have finished. It must be called with the
+// mutex held for writing.
+func (s *Session) release() {
+	for w := range s.watchers {
+		if s.isMigrated {
+			w.Close()
+		} else {
+			w.Push(watch.Destroyed(s.ref))
+		}
+	}
+
 	go func() {
 		// close the done channel only after all pending calls have finished
 		s.calls.Wait()
@@ -193,6 +515,240 @@ func (s *Session) destroy() {
 	}()
 }
 
+// touch records activity on the session, postponing its idle timeout. It must
+// be called with the mutex held for writing.
+func (s *Session) touch() {
+	if s.idleTimer != nil {
+		s.activeAt = time.Now()
+		s.idleTimer.Reset(s.expiry.IdleTimeout)
+	}
+}
+
+// expire destroys the session if its TTL has elapsed, or it has been idle for
+// longer than its idle timeout. It is called by the expiry timers.
+func (s *Session) expire() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isDestroyed {
+		return
+	}
+
+	// the timers may fire concurrently with a call to touch() or SetExpiry(),
+	// so the deadlines are checked again while the mutex is held.
+	now := time.Now()
+	expired := !s.expiresAt.IsZero() && !now.Before(s.expiresAt)
+
+	if !expired && s.idleTimer != nil {
+		expired = now.Sub(s.activeAt) >= s.expiry.IdleTimeout
+	}
+
+	if expired {
+		s.destroy(s.localSource(""))
+		logSessionDestroy(s.logger, s.ref, s.attrs, "")
+	}
+}
+
+// stopTimers stops the expiry timers. It must be called with the mutex held
+// for writing.
+func (s *Session) stopTimers() {
+	if s.ttlTimer != nil {
+		s.ttlTimer.Stop()
+		s.ttlTimer = nil
+	}
+
+	if s.idleTimer != nil {
+		s.idleTimer.Stop()
+		s.idleTimer = nil
+	}
+
+	if s.attrTimer != nil {
+		s.attrTimer.Stop()
+		s.attrTimer = nil
+	}
+}
+
+// ClearExpired clears the values of any attributes that have expired, in a
+// new revision. It is called by the attribute expiry timer, and before the
+// session's attributes are fetched by other peers, so that they do not
+// observe expired values while the timer is pending.
+//
+// Only the read lock is acquired unless an attribute has actually expired.
+func (s *Session) ClearExpired() {
+	now := time.Now()
+
+	s.mutex.RLock()
+	next := s.attrsExpire
+	s.mutex.RUnlock()
+
+	if next == 0 || now.UnixNano() < next {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isDestroyed {
+		return
+	}
+	nextRev := s.ref.Rev + 1
+	nextCat := s.attrs
+	diffs := attributes.NewDiffSet(nextRev)
+
+	for ns, attrs := range s.attrs {
+		var nextAttrs attributes.VTable
+		diff := attributes.NewDiff(ns, nextRev)
+
+		for key, entry := range attrs {
+			if !entry.IsExpiredAt(now) {
+				continue
+			}
+
+			if nextAttrs == nil {
+				nextAttrs = attrs.Clone()
+			}
+
+			entry.Value = ""
+			entry.UpdatedAt = nextRev
+			entry.ExpiresAt = 0
+			nextAttrs[key] = entry
+			diff.Append(entry)
+		}
+
+		if nextAttrs != nil {
+			nextCat = nextCat.WithNamespace(ns, nextAttrs)
+			diffs.Add(diff)
+		}
+	}
+
+	if diffs.IsEmpty() {
+		s.scheduleAttrExpiry()
+		return
+	}
+
+	// expiry is not activity, so the session is not touched.
+	if err := s.commit(nextRev, nextCat, rinq.SessionUpdated, s.localSource(""), diffs.Diffs...); err != nil {
+		logPersistError(s.logger, s.ref, err)
+
+		// try again later, rather than leaving the attributes unexpired.
+		if s.attrTimer != nil {
+			s.attrTimer.Stop()
+		}
+		s.attrTimer = time.AfterFunc(time.Second, s.ClearExpired)
+
+		return
+	}
+
+	logAttrsExpired(s.logger, s.ref, diffs)
+}
+
+// scheduleAttrExpiry starts a timer that clears expired attributes when the
+// earliest-expiring attribute expires, replacing any existing timer. It must
+// be called with the mutex held for writing.
+func (s *Session) scheduleAttrExpiry() {
+	if s.attrTimer != nil {
+		s.attrTimer.Stop()
+		s.attrTimer = nil
+	}
+
+	s.attrsExpire = 0
+
+	if s.isDestroyed {
+		return
+	}
+
+	var next int64
+
+	for _, attrs := range s.attrs {
+		for _, entry := range attrs {
+			if entry.ExpiresAt == 0 || entry.Value == "" {
+				continue
+			}
+
+			if next == 0 || entry.ExpiresAt < next {
+				next = entry.ExpiresAt
+			}
+		}
+	}
+
+	s.attrsExpire = next
+
+	if next != 0 {
+		s.attrTimer = time.AfterFunc(
+			time.Until(time.Unix(0, next)),
+			s.ClearExpired,
+		)
+	}
+}
+
+// notifyWatchers queues the changes in diff for delivery to the session's
+// watchers. It must be called with the mutex held.
+func (s *Session) notifyWatchers(diff *attributes.Diff) {
+	if diff.IsEmpty() {
+		return
+	}
+
+	c := watch.FromDiff(s.ref.ID, diff)
+
+	for w := range s.watchers {
+		w.Push(c)
+	}
+}
+
+// publish queues events of type t for delivery to the subscribers of the
+// peer's session event stream. An event is published for each non-empty diff,
+// or if no diffs are given, a single event for the current revision.
+//
+// It must be called with the mutex held, so that events are published in
+// revision order.
+func (s *Session) publish(
+	t rinq.SessionEventType,
+	src rinq.SessionEventSource,
+	diffs ...*attributes.Diff,
+) {
+	if !s.events.IsActive() {
+		return
+	}
+
+	if len(diffs) == 0 {
+		s.events.Publish(rinq.SessionEvent{
+			Type:   t,
+			Ref:    s.ref,
+			Source: src,
+		})
+
+		return
+	}
+
+	events := make([]rinq.SessionEvent, 0, len(diffs))
+
+	for _, diff := range diffs {
+		if diff.IsEmpty() {
+			continue
+		}
+
+		c := watch.FromDiff(s.ref.ID, diff)
+
+		events = append(events, rinq.SessionEvent{
+			Type:      t,
+			Ref:       c.Ref,
+			Namespace: c.Namespace,
+			Attrs:     c.Attrs,
+			Source:    src,
+		})
+	}
+
+	s.events.Publish(events...)
+}
+
+// localSource returns the source of an event caused by the owning peer.
+func (s *Session) localSource(traceID string) rinq.SessionEventSource {
+	return rinq.SessionEventSource{
+		Peer:    s.ref.ID.Peer,
+		TraceID: traceID,
+	}
+}
+
 // nextMessageID returns a new unique message ID generated from the current
 // session-ref.
 //
@@ -6,22 +6,32 @@ import (
 	"github.com/rinq/rinq-go/src/internal/revisions"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/sessionstore"
 )
 
 // Store is a collection of local sessions which provides an implementation
 // of revisions.Store.
 type Store struct {
+	persistence sessionstore.Store
+
 	mutex    sync.RWMutex
 	sessions map[ident.SessionID]*Session
 }
 
-// NewStore returns a new session store.
-func NewStore() *Store {
+// NewStore returns a new session store. The state of sessions in the store is
+// persisted to p.
+func NewStore(p sessionstore.Store) *Store {
 	return &Store{
-		sessions: map[ident.SessionID]*Session{},
+		persistence: p,
+		sessions:    map[ident.SessionID]*Session{},
 	}
 }
 
+// Persistence returns the store used to persist the state of sessions.
+func (s *Store) Persistence() sessionstore.Store {
+	return s.persistence
+}
+
 // Add adds a session to the store.
 func (s *Store) Add(sess *Session) {
 	s.mutex.Lock()
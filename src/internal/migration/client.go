@@ -0,0 +1,91 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/rinq/rinq-go/src/internal/command"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/trace"
+)
+
+// Client migrates sessions to other peers.
+type Client struct {
+	peerID  ident.PeerID
+	invoker command.Invoker
+	seq     uint32
+}
+
+// NewClient returns a new migration client.
+func NewClient(
+	peerID ident.PeerID,
+	invoker command.Invoker,
+) *Client {
+	return &Client{
+		peerID:  peerID,
+		invoker: invoker,
+	}
+}
+
+// Migrate hands sessions to the first available peer with session migration
+// enabled. It returns the new ID of each session, in the same order as
+// sessions.
+func (c *Client) Migrate(
+	ctx context.Context,
+	sessions []Session,
+) ([]ident.SessionID, error) {
+	msgID, traceID := c.nextMessageID(ctx)
+
+	out := rinq.NewPayload(adoptRequest{
+		Sessions: sessions,
+	})
+	defer out.Close()
+
+	in, err := c.invoker.CallBalanced(
+		ctx,
+		msgID,
+		traceID,
+		migrationNamespace,
+		adoptCommand,
+		out,
+	)
+	defer in.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rsp adoptResponse
+	if err := in.Decode(&rsp); err != nil {
+		return nil, err
+	}
+
+	if len(rsp.IDs) != len(sessions) {
+		return nil, fmt.Errorf(
+			"expected %d session IDs in migration response, got %d",
+			len(sessions),
+			len(rsp.IDs),
+		)
+	}
+
+	return rsp.IDs, nil
+}
+
+// nextMessageID returns a new message ID for a migration request.
+//
+// Message IDs are allocated from revision 2 of the peer's zero session, so
+// that they do not collide with those used by the remote session client or
+// the introspection client, which allocate IDs from revisions 0 and 1.
+func (c *Client) nextMessageID(ctx context.Context) (msgID ident.MessageID, traceID string) {
+	seq := atomic.AddUint32(&c.seq, 1)
+	msgID = c.peerID.Session(0).At(2).Message(seq)
+	traceID = trace.Get(ctx)
+
+	if traceID == "" {
+		traceID = msgID.String()
+	}
+
+	return
+}
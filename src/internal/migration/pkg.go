@@ -0,0 +1,3 @@
+// Package migration implements the internal "_migrate" namespace, which is
+// used to hand the sessions owned by a stopping peer to another peer.
+package migration
@@ -0,0 +1,59 @@
+package migration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rinq/rinq-go/src/internal/command"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// Adopter recreates a session migrated from another peer as a local session,
+// and returns its new ID.
+type Adopter func(ctx context.Context, s Session) ident.SessionID
+
+// Listen attaches the migration service to the given command server.
+//
+// adopt is called for each session that is migrated to this peer.
+func Listen(
+	svr command.Server,
+	adopt Adopter,
+) error {
+	_, err := svr.Listen(
+		migrationNamespace,
+		func(
+			ctx context.Context,
+			req rinq.Request,
+			res rinq.Response,
+		) {
+			defer req.Payload.Close()
+
+			if req.Command != adoptCommand {
+				res.Error(errors.New("unknown command"))
+				return
+			}
+
+			var args adoptRequest
+			if err := req.Payload.Decode(&args); err != nil {
+				res.Error(err)
+				return
+			}
+
+			rsp := adoptResponse{
+				IDs: make([]ident.SessionID, len(args.Sessions)),
+			}
+
+			for i, s := range args.Sessions {
+				rsp.IDs[i] = adopt(ctx, s)
+			}
+
+			payload := rinq.NewPayload(rsp)
+			defer payload.Close()
+
+			res.Done(payload)
+		},
+	)
+
+	return err
+}
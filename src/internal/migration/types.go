@@ -0,0 +1,32 @@
+package migration
+
+import (
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/sessionstore"
+)
+
+const (
+	migrationNamespace = "_migrate"
+)
+
+const (
+	adoptCommand = "adopt"
+)
+
+// Session is the state of a session that is being migrated.
+type Session struct {
+	// State contains the session's ID, revision, attributes and expiry.
+	State sessionstore.Session `json:"s"`
+
+	// Listening contains the namespaces in which the session was listening
+	// for notifications.
+	Listening []string `json:"l,omitempty"`
+}
+
+type adoptRequest struct {
+	Sessions []Session `json:"ss"`
+}
+
+type adoptResponse struct {
+	IDs []ident.SessionID `json:"ids"`
+}
@@ -13,4 +13,11 @@ type Listener interface {
 	Listen(id ident.SessionID, ns string, h rinq.NotificationHandler) (bool, error)
 	Unlisten(id ident.SessionID, ns string) (bool, error)
 	UnlistenAll(id ident.SessionID) error
+
+	// Namespaces returns the namespaces in which the session with the given
+	// ID is listening, in no particular order.
+	Namespaces(id ident.SessionID) []string
+
+	// Pending returns the number of notifications currently being handled.
+	Pending() int
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/redact"
 )
 
 var (
@@ -41,13 +42,16 @@ func SetupCommand(
 // LogInvokerCall logs information about a "call" style invocation to s.
 func LogInvokerCall(
 	s opentracing.Span,
+	r redact.Redactor,
+	ns string,
+	cmd string,
 	attrs attributes.Catalog,
 	p *rinq.Payload,
 ) {
-	fields := []log.Field{
-		invokerCallEvent,
-		log.Int("size", p.Len()),
-	}
+	fields := append(
+		[]log.Field{invokerCallEvent},
+		payloadFields(s, r, ns, cmd, p)...,
+	)
 
 	if !attrs.IsEmpty() {
 		fields = append(fields, lazyString("attributes", attrs.String))
@@ -59,13 +63,16 @@ func LogInvokerCall(
 // LogInvokerCallAsync logs information about a "call-sync" style invocation to s.
 func LogInvokerCallAsync(
 	s opentracing.Span,
+	r redact.Redactor,
+	ns string,
+	cmd string,
 	attrs attributes.Catalog,
 	p *rinq.Payload,
 ) {
-	fields := []log.Field{
-		invokerCallAsyncEvent,
-		log.Int("size", p.Len()),
-	}
+	fields := append(
+		[]log.Field{invokerCallAsyncEvent},
+		payloadFields(s, r, ns, cmd, p)...,
+	)
 
 	if !attrs.IsEmpty() {
 		fields = append(fields, lazyString("attributes", attrs.String))
@@ -77,13 +84,16 @@ func LogInvokerCallAsync(
 // LogInvokerExecute logs information about an "execute" style invoation to s.
 func LogInvokerExecute(
 	s opentracing.Span,
+	r redact.Redactor,
+	ns string,
+	cmd string,
 	attrs attributes.Catalog,
 	p *rinq.Payload,
 ) {
-	fields := []log.Field{
-		invokerExecuteEvent,
-		log.Int("size", p.Len()),
-	}
+	fields := append(
+		[]log.Field{invokerExecuteEvent},
+		payloadFields(s, r, ns, cmd, p)...,
+	)
 
 	if !attrs.IsEmpty() {
 		fields = append(fields, lazyString("attributes", attrs.String))
@@ -93,25 +103,43 @@ func LogInvokerExecute(
 }
 
 // LogInvokerSuccess logs information about a successful command response to s.
-func LogInvokerSuccess(s opentracing.Span, p *rinq.Payload) {
+func LogInvokerSuccess(
+	s opentracing.Span,
+	r redact.Redactor,
+	ns string,
+	cmd string,
+	p *rinq.Payload,
+) {
 	s.LogFields(
-		successEvent,
-		log.Int("size", p.Len()),
+		append(
+			[]log.Field{successEvent},
+			payloadFields(s, r, ns, cmd, p)...,
+		)...,
 	)
 }
 
 // LogInvokerError logs information about err to s.
-func LogInvokerError(s opentracing.Span, err error) {
+func LogInvokerError(
+	s opentracing.Span,
+	r redact.Redactor,
+	ns string,
+	cmd string,
+	err error,
+) {
 	ext.Error.Set(s, true)
 
 	switch e := err.(type) {
 	case rinq.Failure:
 		s.LogFields(
-			invokerFailureEvent,
-			log.String("error.kind", e.Type),
-			log.String("message", e.Message),
-			invokerErrorSourceServer,
-			log.Int("size", e.Payload.Len()),
+			append(
+				[]log.Field{
+					invokerFailureEvent,
+					log.String("error.kind", e.Type),
+					log.String("message", e.Message),
+					invokerErrorSourceServer,
+				},
+				payloadFields(s, r, ns, cmd, e.Payload)...,
+			)...,
 		)
 
 	case rinq.CommandError:
@@ -131,31 +159,58 @@ func LogInvokerError(s opentracing.Span, err error) {
 }
 
 // LogServerRequest logs information about an incoming command request to s.
-func LogServerRequest(s opentracing.Span, peerID ident.PeerID, p *rinq.Payload) {
+func LogServerRequest(
+	s opentracing.Span,
+	r redact.Redactor,
+	peerID ident.PeerID,
+	req rinq.Request,
+) {
 	s.LogFields(
-		serverRequestEvent,
-		log.String("server", peerID.String()),
-		log.Int("size", p.Len()),
+		append(
+			[]log.Field{
+				serverRequestEvent,
+				log.String("server", peerID.String()),
+			},
+			payloadFields(s, r, req.Namespace, req.Command, req.Payload)...,
+		)...,
 	)
 }
 
 // LogServerSuccess logs information about a successful command response to s.
-func LogServerSuccess(s opentracing.Span, p *rinq.Payload) {
+func LogServerSuccess(
+	s opentracing.Span,
+	r redact.Redactor,
+	ns string,
+	cmd string,
+	p *rinq.Payload,
+) {
 	s.LogFields(
-		serverResponseEvent,
-		log.Int("size", p.Len()),
+		append(
+			[]log.Field{serverResponseEvent},
+			payloadFields(s, r, ns, cmd, p)...,
+		)...,
 	)
 }
 
 // LogServerError logs information about err to s.
-func LogServerError(s opentracing.Span, err error) {
+func LogServerError(
+	s opentracing.Span,
+	r redact.Redactor,
+	ns string,
+	cmd string,
+	err error,
+) {
 	switch e := err.(type) {
 	case rinq.Failure:
 		s.LogFields(
-			serverResponseEvent,
-			log.String("error.kind", e.Type),
-			log.String("message", e.Message),
-			log.Int("size", e.Payload.Len()),
+			append(
+				[]log.Field{
+					serverResponseEvent,
+					log.String("error.kind", e.Type),
+					log.String("message", e.Message),
+				},
+				payloadFields(s, r, ns, cmd, e.Payload)...,
+			)...,
 		)
 
 	default:
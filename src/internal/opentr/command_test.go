@@ -9,8 +9,11 @@ import (
 	. "github.com/rinq/rinq-go/src/internal/opentr"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/redact"
 )
 
+var redactor = redact.Suppress()
+
 var _ = Describe("SetupCommand", func() {
 	It("sets the operation name", func() {
 		span := &mockSpan{}
@@ -51,7 +54,7 @@ var _ = Describe("LogInvokerCall", func() {
 		p := rinq.NewPayloadFromBytes(make([]byte, 4))
 		defer p.Close()
 
-		LogInvokerCall(span, attrs, p)
+		LogInvokerCall(span, redactor, "<ns>", "<cmd>", attrs, p)
 
 		Expect(span.log).To(Equal(
 			[]map[string]interface{}{
@@ -59,6 +62,7 @@ var _ = Describe("LogInvokerCall", func() {
 					"event":      "call",
 					"attributes": "ns::{foo@bar}",
 					"size":       4,
+					"payload":    "<4 bytes redacted>",
 				},
 			},
 		))
@@ -80,7 +84,7 @@ var _ = Describe("LogInvokerCallAsync", func() {
 		p := rinq.NewPayloadFromBytes(make([]byte, 4))
 		defer p.Close()
 
-		LogInvokerCallAsync(span, attrs, p)
+		LogInvokerCallAsync(span, redactor, "<ns>", "<cmd>", attrs, p)
 
 		Expect(span.log).To(Equal(
 			[]map[string]interface{}{
@@ -88,6 +92,7 @@ var _ = Describe("LogInvokerCallAsync", func() {
 					"event":      "call-async",
 					"attributes": "ns::{foo@bar}",
 					"size":       4,
+					"payload":    "<4 bytes redacted>",
 				},
 			},
 		))
@@ -109,7 +114,7 @@ var _ = Describe("LogInvokerExecute", func() {
 		p := rinq.NewPayloadFromBytes(make([]byte, 4))
 		defer p.Close()
 
-		LogInvokerExecute(span, attrs, p)
+		LogInvokerExecute(span, redactor, "<ns>", "<cmd>", attrs, p)
 
 		Expect(span.log).To(Equal(
 			[]map[string]interface{}{
@@ -117,6 +122,7 @@ var _ = Describe("LogInvokerExecute", func() {
 					"event":      "execute",
 					"attributes": "ns::{foo@bar}",
 					"size":       4,
+					"payload":    "<4 bytes redacted>",
 				},
 			},
 		))
@@ -130,13 +136,14 @@ var _ = Describe("LogInvokerSuccess", func() {
 		p := rinq.NewPayloadFromBytes(make([]byte, 4))
 		defer p.Close()
 
-		LogInvokerSuccess(span, p)
+		LogInvokerSuccess(span, redactor, "<ns>", "<cmd>", p)
 
 		Expect(span.log).To(Equal(
 			[]map[string]interface{}{
 				{
-					"event": "success",
-					"size":  4,
+					"event":   "success",
+					"size":    4,
+					"payload": "<4 bytes redacted>",
 				},
 			},
 		))
@@ -153,7 +160,7 @@ var _ = Describe("LogInvokerError", func() {
 
 		It("logs the appropriate fields", func() {
 			span := &mockSpan{}
-			LogInvokerError(span, err)
+			LogInvokerError(span, redactor, "<ns>", "<cmd>", err)
 
 			Expect(span.log).To(Equal(
 				[]map[string]interface{}{
@@ -163,6 +170,7 @@ var _ = Describe("LogInvokerError", func() {
 						"message":      "<message>",
 						"error.source": "server",
 						"size":         4,
+						"payload":      "<4 bytes redacted>",
 					},
 				},
 			))
@@ -170,7 +178,7 @@ var _ = Describe("LogInvokerError", func() {
 
 		It("sets the error tag", func() {
 			span := &mockSpan{}
-			LogInvokerError(span, err)
+			LogInvokerError(span, redactor, "<ns>", "<cmd>", err)
 
 			Expect(span.tags["error"]).To(BeTrue())
 		})
@@ -181,7 +189,7 @@ var _ = Describe("LogInvokerError", func() {
 
 		It("logs the appropriate fields", func() {
 			span := &mockSpan{}
-			LogInvokerError(span, err)
+			LogInvokerError(span, redactor, "<ns>", "<cmd>", err)
 
 			Expect(span.log).To(Equal(
 				[]map[string]interface{}{
@@ -196,7 +204,7 @@ var _ = Describe("LogInvokerError", func() {
 
 		It("sets the error tag", func() {
 			span := &mockSpan{}
-			LogInvokerError(span, err)
+			LogInvokerError(span, redactor, "<ns>", "<cmd>", err)
 
 			Expect(span.tags["error"]).To(BeTrue())
 		})
@@ -207,7 +215,7 @@ var _ = Describe("LogInvokerError", func() {
 
 		It("logs the appropriate fields", func() {
 			span := &mockSpan{}
-			LogInvokerError(span, err)
+			LogInvokerError(span, redactor, "<ns>", "<cmd>", err)
 
 			Expect(span.log).To(Equal(
 				[]map[string]interface{}{
@@ -222,7 +230,7 @@ var _ = Describe("LogInvokerError", func() {
 
 		It("sets the error tag", func() {
 			span := &mockSpan{}
-			LogInvokerError(span, err)
+			LogInvokerError(span, redactor, "<ns>", "<cmd>", err)
 
 			Expect(span.tags["error"]).To(BeTrue())
 		})
@@ -238,14 +246,15 @@ var _ = Describe("LogServerRequest", func() {
 		p := rinq.NewPayloadFromBytes(make([]byte, 4))
 		defer p.Close()
 
-		LogServerRequest(span, peerID, p)
+		LogServerRequest(span, redactor, peerID, rinq.Request{Namespace: "<ns>", Command: "<cmd>", Payload: p})
 
 		Expect(span.log).To(Equal(
 			[]map[string]interface{}{
 				{
-					"event":  "request",
-					"server": peerID.String(),
-					"size":   4,
+					"event":   "request",
+					"server":  peerID.String(),
+					"size":    4,
+					"payload": "<4 bytes redacted>",
 				},
 			},
 		))
@@ -259,13 +268,14 @@ var _ = Describe("LogServerSuccess", func() {
 		p := rinq.NewPayloadFromBytes(make([]byte, 4))
 		defer p.Close()
 
-		LogServerSuccess(span, p)
+		LogServerSuccess(span, redactor, "<ns>", "<cmd>", p)
 
 		Expect(span.log).To(Equal(
 			[]map[string]interface{}{
 				{
-					"event": "response",
-					"size":  4,
+					"event":   "response",
+					"size":    4,
+					"payload": "<4 bytes redacted>",
 				},
 			},
 		))
@@ -282,7 +292,7 @@ var _ = Describe("LogServerError", func() {
 
 		It("logs the appropriate fields", func() {
 			span := &mockSpan{}
-			LogServerError(span, err)
+			LogServerError(span, redactor, "<ns>", "<cmd>", err)
 
 			Expect(span.log).To(Equal(
 				[]map[string]interface{}{
@@ -291,6 +301,7 @@ var _ = Describe("LogServerError", func() {
 						"error.kind": "<type>",
 						"message":    "<message>",
 						"size":       4,
+						"payload":    "<4 bytes redacted>",
 					},
 				},
 			))
@@ -298,7 +309,7 @@ var _ = Describe("LogServerError", func() {
 
 		It("does not set the error tag", func() {
 			span := &mockSpan{}
-			LogServerError(span, err)
+			LogServerError(span, redactor, "<ns>", "<cmd>", err)
 
 			Expect(span.tags["error"]).To(BeNil())
 		})
@@ -309,7 +320,7 @@ var _ = Describe("LogServerError", func() {
 
 		It("logs the appropriate fields", func() {
 			span := &mockSpan{}
-			LogServerError(span, err)
+			LogServerError(span, redactor, "<ns>", "<cmd>", err)
 
 			Expect(span.log).To(Equal(
 				[]map[string]interface{}{
@@ -323,7 +334,7 @@ var _ = Describe("LogServerError", func() {
 
 		It("sets the error tag", func() {
 			span := &mockSpan{}
-			LogServerError(span, err)
+			LogServerError(span, redactor, "<ns>", "<cmd>", err)
 
 			Expect(span.tags["error"]).To(BeTrue())
 		})
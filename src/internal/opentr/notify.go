@@ -8,6 +8,7 @@ import (
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/constraint"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/redact"
 )
 
 var (
@@ -34,15 +35,20 @@ func SetupNotification(
 // LogNotifierUnicast logs information about a unicast notification to s.
 func LogNotifierUnicast(
 	s opentracing.Span,
+	r redact.Redactor,
+	ns string,
+	t string,
 	attrs attributes.Catalog,
 	target ident.SessionID,
 	p *rinq.Payload,
 ) {
-	fields := []log.Field{
-		notifierUnicastEvent,
-		log.String("target", target.String()),
-		log.Int("size", p.Len()),
-	}
+	fields := append(
+		[]log.Field{
+			notifierUnicastEvent,
+			log.String("target", target.String()),
+		},
+		payloadFields(s, r, ns, t, p)...,
+	)
 
 	if len(attrs) > 0 {
 		fields = append(fields, lazyString("attributes", attrs.String))
@@ -54,15 +60,20 @@ func LogNotifierUnicast(
 // LogNotifierMulticast logs informatin about a multicast notification to s.
 func LogNotifierMulticast(
 	s opentracing.Span,
+	r redact.Redactor,
+	ns string,
+	t string,
 	attrs attributes.Catalog,
 	con constraint.Constraint,
 	p *rinq.Payload,
 ) {
-	fields := []log.Field{
-		notifierMulticastEvent,
-		log.String("constraint", con.String()),
-		log.Int("size", p.Len()),
-	}
+	fields := append(
+		[]log.Field{
+			notifierMulticastEvent,
+			log.String("constraint", con.String()),
+		},
+		payloadFields(s, r, ns, t, p)...,
+	)
 
 	if len(attrs) > 0 {
 		fields = append(fields, lazyString("attributes", attrs.String))
@@ -82,13 +93,20 @@ func LogNotifierError(s opentracing.Span, err error) {
 }
 
 // LogListenerReceived logs information about a received notification to s.
-func LogListenerReceived(s opentracing.Span, ref ident.Ref, n rinq.Notification) {
-	fields := []log.Field{
-		listenerReceiveEvent,
-		log.String("recipient", ref.String()),
-		log.Bool("multicast", n.IsMulticast),
-		log.Int("size", n.Payload.Len()),
-	}
+func LogListenerReceived(
+	s opentracing.Span,
+	r redact.Redactor,
+	ref ident.Ref,
+	n rinq.Notification,
+) {
+	fields := append(
+		[]log.Field{
+			listenerReceiveEvent,
+			log.String("recipient", ref.String()),
+			log.Bool("multicast", n.IsMulticast),
+		},
+		payloadFields(s, r, n.Namespace, n.Type, n.Payload)...,
+	)
 
 	if n.IsMulticast {
 		fields = append(
@@ -0,0 +1,38 @@
+package opentr
+
+import (
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/redact"
+)
+
+// payloadFields returns the log fields that describe p, which was sent or
+// received as part of the command or notification with the given namespace
+// and name.
+//
+// The content of the payload is rendered by r, and is omitted if p is empty
+// or s is not recorded by a tracer.
+func payloadFields(
+	s opentracing.Span,
+	r redact.Redactor,
+	ns string,
+	name string,
+	p *rinq.Payload,
+) []log.Field {
+	fields := []log.Field{
+		log.Int("size", p.Len()),
+	}
+
+	if p.Len() != 0 && isRecorded(s) {
+		fields = append(fields, log.String("payload", r.Redact(ns, name, p)))
+	}
+
+	return fields
+}
+
+// isRecorded returns false if s belongs to a no-op tracer.
+func isRecorded(s opentracing.Span) bool {
+	_, ok := s.Tracer().(opentracing.NoopTracer)
+	return !ok
+}
@@ -1,6 +1,7 @@
 package opentr
 
 import (
+	"sort"
 	"strings"
 
 	opentracing "github.com/opentracing/opentracing-go"
@@ -16,6 +17,7 @@ const (
 	updateOp  = "session update"
 	clearOp   = "session clear"
 	destroyOp = "session destroy"
+	dumpOp    = "session dump"
 )
 
 var (
@@ -23,6 +25,7 @@ var (
 	updateEvent  = log.String("event", "update")
 	clearEvent   = log.String("event", "clear")
 	destroyEvent = log.String("event", "destroy")
+	dumpEvent    = log.String("event", "dump")
 )
 
 func setupSessionCommand(s opentracing.Span, op string, sessID ident.SessionID) {
@@ -101,6 +104,52 @@ func LogSessionUpdateSuccess(s opentracing.Span, rev ident.Revision, diff *attri
 	s.LogFields(fields...)
 }
 
+// SetupSessionUpdateMany configures s as an attribute update operation that
+// affects several namespaces.
+func SetupSessionUpdateMany(s opentracing.Span, attrs map[string]attributes.List, sessID ident.SessionID) {
+	namespaces := make([]string, 0, len(attrs))
+	for ns := range attrs {
+		namespaces = append(namespaces, ns)
+	}
+
+	sort.Strings(namespaces)
+
+	setupSessionCommand(s, updateOp, sessID)
+	s.SetTag("namespace", strings.Join(namespaces, ", "))
+}
+
+// LogSessionUpdateManyRequest logs information about a session update
+// attempt that affects several namespaces to s.
+func LogSessionUpdateManyRequest(s opentracing.Span, rev ident.Revision, attrs map[string]attributes.List) {
+	fields := []log.Field{
+		updateEvent,
+		log.Uint32("rev", uint32(rev)),
+	}
+
+	for ns, l := range attrs {
+		if !l.IsEmpty() {
+			fields = append(fields, lazyString("changes."+ns, l.String))
+		}
+	}
+
+	s.LogFields(fields...)
+}
+
+// LogSessionUpdateManySuccess logs information about a successful session
+// update that affects several namespaces to s.
+func LogSessionUpdateManySuccess(s opentracing.Span, rev ident.Revision, diffs *attributes.DiffSet) {
+	fields := []log.Field{
+		successEvent,
+		log.Uint32("rev", uint32(rev)),
+	}
+
+	if !diffs.IsEmpty() {
+		fields = append(fields, lazyString("diff", diffs.String))
+	}
+
+	s.LogFields(fields...)
+}
+
 // SetupSessionClear configures s as an attribute update operation.
 func SetupSessionClear(s opentracing.Span, ns string, sessID ident.SessionID) {
 	setupSessionCommand(s, clearOp, sessID)
@@ -152,6 +201,32 @@ func LogSessionDestroySuccess(s opentracing.Span) {
 	)
 }
 
+// SetupSessionDump configures s as an operation that fetches all attributes.
+func SetupSessionDump(s opentracing.Span, sessID ident.SessionID) {
+	setupSessionCommand(s, dumpOp, sessID)
+}
+
+// LogSessionDumpRequest logs information about a session dump attempt to s.
+func LogSessionDumpRequest(s opentracing.Span) {
+	s.LogFields(
+		dumpEvent,
+	)
+}
+
+// LogSessionDumpSuccess logs information about a successful session dump to s.
+func LogSessionDumpSuccess(s opentracing.Span, rev ident.Revision, attrs attributes.Catalog) {
+	fields := []log.Field{
+		successEvent,
+		log.Uint32("rev", uint32(rev)),
+	}
+
+	if !attrs.IsEmpty() {
+		fields = append(fields, lazyString("attributes", attrs.String))
+	}
+
+	s.LogFields(fields...)
+}
+
 // LogSessionError logs information about an error during a session operation.
 func LogSessionError(s opentracing.Span, err error) {
 	switch e := err.(type) {
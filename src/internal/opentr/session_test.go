@@ -146,6 +146,87 @@ var _ = Describe("LogSessionUpdateSuccess", func() {
 	})
 })
 
+var _ = Describe("SetupSessionUpdateMany", func() {
+	It("sets the operation name", func() {
+		span := &mockSpan{}
+
+		SetupSessionUpdateMany(span, nil, ident.SessionID{})
+
+		Expect(span.operationName).To(Equal("session update"))
+	})
+
+	It("sets the appropriate tags", func() {
+		span := &mockSpan{}
+
+		sessID := ident.NewPeerID().Session(1)
+
+		SetupSessionUpdateMany(
+			span,
+			map[string]attributes.List{
+				"ns2": nil,
+				"ns1": nil,
+			},
+			sessID,
+		)
+
+		Expect(span.tags).To(Equal(map[string]interface{}{
+			"subsystem": "session",
+			"session":   sessID.String(),
+			"namespace": "ns1, ns2",
+		}))
+	})
+})
+
+var _ = Describe("LogSessionUpdateManyRequest", func() {
+	It("logs the appropriate fields", func() {
+		span := &mockSpan{}
+
+		attrs := map[string]attributes.List{
+			"ns1": {rinq.Set("a", "1")},
+			"ns2": {rinq.Set("b", "2")},
+		}
+
+		LogSessionUpdateManyRequest(span, 23, attrs)
+
+		Expect(span.log).To(Equal(
+			[]map[string]interface{}{
+				{
+					"event":       "update",
+					"rev":         uint32(23),
+					"changes.ns1": "{a=1}",
+					"changes.ns2": "{b=2}",
+				},
+			},
+		))
+	})
+})
+
+var _ = Describe("LogSessionUpdateManySuccess", func() {
+	It("logs the appropriate fields", func() {
+		span := &mockSpan{}
+
+		diff := attributes.NewDiff("ns", 23)
+		diff.Append(
+			attributes.VAttr{Attr: rinq.Set("a", "1")},
+		)
+
+		diffs := attributes.NewDiffSet(23)
+		diffs.Add(diff)
+
+		LogSessionUpdateManySuccess(span, 23, diffs)
+
+		Expect(span.log).To(Equal(
+			[]map[string]interface{}{
+				{
+					"event": "success",
+					"rev":   uint32(23),
+					"diff":  diffs.String(),
+				},
+			},
+		))
+	})
+})
+
 var _ = Describe("SetupSessionClear", func() {
 	It("sets the operation name", func() {
 		span := &mockSpan{}
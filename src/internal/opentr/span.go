@@ -5,6 +5,7 @@ import (
 
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
+	"github.com/rinq/rinq-go/src/internal/oteltr"
 	"github.com/rinq/rinq-go/src/rinq"
 )
 
@@ -58,11 +59,22 @@ func childSpanFromContext(
 	if parent != nil {
 		opts = append(opts, rel(parent.Context()))
 		tracer = parent.Tracer()
+	} else if sc, ok := oteltr.SpanContextFromContext(ctx, tracer); ok {
+		opts = append(opts, rel(sc))
 	}
 
 	opts = append(opts, CommonSpanOptions...)
 
 	span := tracer.StartSpan("", opts...)
 
-	return span, opentracing.ContextWithSpan(ctx, span)
+	return span, ContextWithSpan(ctx, span)
+}
+
+// ContextWithSpan returns a new context derived from ctx that contains span.
+//
+// If span is an OpenTelemetry span, it is also made available to application
+// code that uses OpenTelemetry directly.
+func ContextWithSpan(ctx context.Context, span opentracing.Span) context.Context {
+	ctx = opentracing.ContextWithSpan(ctx, span)
+	return oteltr.ContextWithSpan(ctx, span)
 }
@@ -0,0 +1,97 @@
+package oteltr
+
+import (
+	"fmt"
+
+	"github.com/opentracing/opentracing-go/log"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// attributeOf returns an OpenTelemetry attribute equivalent to an OpenTracing
+// tag.
+func attributeOf(k string, v interface{}) attribute.KeyValue {
+	switch x := v.(type) {
+	case string:
+		return attribute.String(k, x)
+	case bool:
+		return attribute.Bool(k, x)
+	case int:
+		return attribute.Int(k, x)
+	case int8:
+		return attribute.Int64(k, int64(x))
+	case int16:
+		return attribute.Int64(k, int64(x))
+	case int32:
+		return attribute.Int64(k, int64(x))
+	case int64:
+		return attribute.Int64(k, x)
+	case uint8:
+		return attribute.Int64(k, int64(x))
+	case uint16:
+		return attribute.Int64(k, int64(x))
+	case uint32:
+		return attribute.Int64(k, int64(x))
+	case float32:
+		return attribute.Float64(k, float64(x))
+	case float64:
+		return attribute.Float64(k, x)
+	default:
+		return attribute.String(k, fmt.Sprint(v))
+	}
+}
+
+// encoder is an OpenTracing log encoder that builds OpenTelemetry event
+// attributes.
+type encoder struct {
+	event string
+	attrs []attribute.KeyValue
+}
+
+func (e *encoder) EmitString(k, v string) {
+	if k == "event" && e.event == "" {
+		e.event = v
+		return
+	}
+
+	e.attrs = append(e.attrs, attribute.String(k, v))
+}
+
+func (e *encoder) EmitBool(k string, v bool) {
+	e.attrs = append(e.attrs, attribute.Bool(k, v))
+}
+
+func (e *encoder) EmitInt(k string, v int) {
+	e.attrs = append(e.attrs, attribute.Int(k, v))
+}
+
+func (e *encoder) EmitInt32(k string, v int32) {
+	e.attrs = append(e.attrs, attribute.Int64(k, int64(v)))
+}
+
+func (e *encoder) EmitInt64(k string, v int64) {
+	e.attrs = append(e.attrs, attribute.Int64(k, v))
+}
+
+func (e *encoder) EmitUint32(k string, v uint32) {
+	e.attrs = append(e.attrs, attribute.Int64(k, int64(v)))
+}
+
+func (e *encoder) EmitUint64(k string, v uint64) {
+	e.attrs = append(e.attrs, attribute.String(k, fmt.Sprint(v)))
+}
+
+func (e *encoder) EmitFloat32(k string, v float32) {
+	e.attrs = append(e.attrs, attribute.Float64(k, float64(v)))
+}
+
+func (e *encoder) EmitFloat64(k string, v float64) {
+	e.attrs = append(e.attrs, attribute.Float64(k, v))
+}
+
+func (e *encoder) EmitObject(k string, v interface{}) {
+	e.attrs = append(e.attrs, attributeOf(k, v))
+}
+
+func (e *encoder) EmitLazyLogger(v log.LazyLogger) {
+	v(e)
+}
@@ -0,0 +1,41 @@
+package oteltr
+
+import (
+	"context"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ContextWithSpan returns a new context derived from parent that contains the
+// OpenTelemetry span wrapped by s, if s was produced by a Tracer. This allows
+// application code that uses OpenTelemetry directly to create child spans of
+// spans produced by Rinq.
+func ContextWithSpan(parent context.Context, s opentracing.Span) context.Context {
+	if x, ok := s.(*span); ok {
+		return trace.ContextWithSpan(parent, x.span)
+	}
+
+	return parent
+}
+
+// SpanContextFromContext returns the OpenTelemetry span context in ctx, as an
+// OpenTracing span context suitable for use with t.
+//
+// ok is false if t is not a Tracer, or ctx does not contain a valid
+// OpenTelemetry span context.
+func SpanContextFromContext(
+	ctx context.Context,
+	t opentracing.Tracer,
+) (sc opentracing.SpanContext, ok bool) {
+	if _, ok := t.(*Tracer); !ok {
+		return nil, false
+	}
+
+	c := trace.SpanContextFromContext(ctx)
+	if !c.IsValid() {
+		return nil, false
+	}
+
+	return spanContext{c}, true
+}
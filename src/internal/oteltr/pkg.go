@@ -0,0 +1,4 @@
+// Package oteltr provides an implementation of the OpenTracing API on top of
+// an OpenTelemetry tracer, allowing Rinq's existing instrumentation to produce
+// OpenTelemetry spans.
+package oteltr
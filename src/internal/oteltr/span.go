@@ -0,0 +1,152 @@
+package oteltr
+
+import (
+	"fmt"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// span is an OpenTracing span that wraps an OpenTelemetry span.
+//
+// Baggage is not supported, baggage items are silently discarded.
+type span struct {
+	tracer *Tracer
+	span   trace.Span
+}
+
+func (s *span) Finish() {
+	s.span.End()
+}
+
+func (s *span) FinishWithOptions(opts opentracing.FinishOptions) {
+	for _, r := range opts.LogRecords {
+		s.logFields(r.Timestamp, r.Fields)
+	}
+
+	for _, d := range opts.BulkLogData {
+		r := d.ToLogRecord()
+		s.logFields(r.Timestamp, r.Fields)
+	}
+
+	if opts.FinishTime.IsZero() {
+		s.span.End()
+	} else {
+		s.span.End(trace.WithTimestamp(opts.FinishTime))
+	}
+}
+
+func (s *span) Context() opentracing.SpanContext {
+	return spanContext{s.span.SpanContext()}
+}
+
+func (s *span) SetOperationName(operationName string) opentracing.Span {
+	s.span.SetName(operationName)
+	return s
+}
+
+func (s *span) SetTag(key string, value interface{}) opentracing.Span {
+	switch key {
+	case string(ext.Error):
+		if b, _ := value.(bool); b {
+			s.setError()
+		}
+	case string(ext.SpanKind):
+		// The span kind of an OpenTelemetry span can only be set when it is
+		// started, it is retained as a regular attribute instead.
+		fallthrough
+	default:
+		s.span.SetAttributes(attributeOf(key, value))
+	}
+
+	return s
+}
+
+func (s *span) LogFields(fields ...log.Field) {
+	s.logFields(time.Time{}, fields)
+}
+
+func (s *span) LogKV(alternatingKeyValues ...interface{}) {
+	fields, err := log.InterleavedKVToFields(alternatingKeyValues...)
+	if err != nil {
+		fields = []log.Field{
+			log.Error(err),
+			log.String("function", "LogKV"),
+		}
+	}
+
+	s.logFields(time.Time{}, fields)
+}
+
+func (s *span) SetBaggageItem(restrictedKey, value string) opentracing.Span {
+	return s
+}
+
+func (s *span) BaggageItem(restrictedKey string) string {
+	return ""
+}
+
+func (s *span) Tracer() opentracing.Tracer {
+	return s.tracer
+}
+
+func (s *span) LogEvent(event string) {
+	s.LogFields(log.String("event", event))
+}
+
+func (s *span) LogEventWithPayload(event string, payload interface{}) {
+	s.LogFields(
+		log.String("event", event),
+		log.Object("payload", payload),
+	)
+}
+
+func (s *span) Log(d opentracing.LogData) {
+	r := d.ToLogRecord()
+	s.logFields(r.Timestamp, r.Fields)
+}
+
+// logFields adds an event to the span. The event name is taken from the
+// "event" field, if present. All other fields are added as event attributes.
+func (s *span) logFields(ts time.Time, fields []log.Field) {
+	enc := &encoder{}
+	for _, f := range fields {
+		f.Marshal(enc)
+	}
+
+	name := enc.event
+	if name == "" {
+		name = "log"
+	}
+
+	opts := []trace.EventOption{
+		trace.WithAttributes(enc.attrs...),
+	}
+
+	if !ts.IsZero() {
+		opts = append(opts, trace.WithTimestamp(ts))
+	}
+
+	s.span.AddEvent(name, opts...)
+}
+
+// setError marks the span as having failed.
+func (s *span) setError() {
+	s.span.SetStatus(codes.Error, "")
+}
+
+// spanContext is an OpenTracing span context that wraps an OpenTelemetry span
+// context.
+type spanContext struct {
+	sc trace.SpanContext
+}
+
+func (c spanContext) ForeachBaggageItem(func(k, v string) bool) {}
+
+func (c spanContext) String() string {
+	return fmt.Sprintf("%s:%s", c.sc.TraceID(), c.sc.SpanID())
+}
@@ -0,0 +1,182 @@
+package oteltr
+
+import (
+	"context"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/rinq/rinq-go/src/rinq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the OpenTelemetry instrumentation library
+// used for spans produced by Rinq.
+const InstrumentationName = "github.com/rinq/rinq-go"
+
+// Tracer is an OpenTracing tracer that produces OpenTelemetry spans.
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// NewTracer returns a new tracer that produces spans using the OpenTelemetry
+// tracer provider tp.
+//
+// Span contexts are propagated in the W3C Trace Context format.
+func NewTracer(tp trace.TracerProvider) *Tracer {
+	return &Tracer{
+		tracer: tp.Tracer(
+			InstrumentationName,
+			trace.WithInstrumentationVersion(rinq.Version),
+		),
+		propagator: propagation.TraceContext{},
+	}
+}
+
+// StartSpan starts a new span with the given operation name.
+func (t *Tracer) StartSpan(
+	operationName string,
+	opts ...opentracing.StartSpanOption,
+) opentracing.Span {
+	var o opentracing.StartSpanOptions
+	for _, opt := range opts {
+		opt.Apply(&o)
+	}
+
+	var (
+		ctx       = context.Background()
+		startOpts []trace.SpanStartOption
+		attrs     []attribute.KeyValue
+		isError   bool
+	)
+
+	for i, ref := range o.References {
+		c, ok := ref.ReferencedContext.(spanContext)
+		if !ok {
+			continue
+		}
+
+		if i == 0 {
+			ctx = trace.ContextWithSpanContext(ctx, c.sc)
+		} else {
+			startOpts = append(startOpts, trace.WithLinks(trace.Link{SpanContext: c.sc}))
+		}
+	}
+
+	for k, v := range o.Tags {
+		switch k {
+		case string(ext.SpanKind):
+			startOpts = append(startOpts, trace.WithSpanKind(spanKind(v)))
+		case string(ext.Error):
+			isError, _ = v.(bool)
+		default:
+			attrs = append(attrs, attributeOf(k, v))
+		}
+	}
+
+	if len(attrs) != 0 {
+		startOpts = append(startOpts, trace.WithAttributes(attrs...))
+	}
+
+	if !o.StartTime.IsZero() {
+		startOpts = append(startOpts, trace.WithTimestamp(o.StartTime))
+	}
+
+	_, s := t.tracer.Start(ctx, operationName, startOpts...)
+
+	sp := &span{tracer: t, span: s}
+
+	if isError {
+		sp.setError()
+	}
+
+	return sp
+}
+
+// Inject injects sc into carrier.
+//
+// Only the opentracing.TextMap and opentracing.HTTPHeaders formats are
+// supported.
+func (t *Tracer) Inject(sc opentracing.SpanContext, format interface{}, carrier interface{}) error {
+	switch format {
+	case opentracing.TextMap, opentracing.HTTPHeaders:
+	default:
+		return opentracing.ErrUnsupportedFormat
+	}
+
+	c, ok := sc.(spanContext)
+	if !ok {
+		return opentracing.ErrInvalidSpanContext
+	}
+
+	w, ok := carrier.(opentracing.TextMapWriter)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+
+	m := propagation.MapCarrier{}
+	t.propagator.Inject(
+		trace.ContextWithSpanContext(context.Background(), c.sc),
+		m,
+	)
+
+	for k, v := range m {
+		w.Set(k, v)
+	}
+
+	return nil
+}
+
+// Extract returns the span context encoded in carrier.
+//
+// Only the opentracing.TextMap and opentracing.HTTPHeaders formats are
+// supported.
+func (t *Tracer) Extract(format interface{}, carrier interface{}) (opentracing.SpanContext, error) {
+	switch format {
+	case opentracing.TextMap, opentracing.HTTPHeaders:
+	default:
+		return nil, opentracing.ErrUnsupportedFormat
+	}
+
+	r, ok := carrier.(opentracing.TextMapReader)
+	if !ok {
+		return nil, opentracing.ErrInvalidCarrier
+	}
+
+	m := propagation.MapCarrier{}
+	if err := r.ForeachKey(func(k, v string) error {
+		m.Set(k, v)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	sc := trace.SpanContextFromContext(
+		t.propagator.Extract(context.Background(), m),
+	)
+
+	if !sc.IsValid() {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+
+	return spanContext{sc}, nil
+}
+
+// spanKind returns the OpenTelemetry span kind equivalent to the OpenTracing
+// "span.kind" tag value v.
+func spanKind(v interface{}) trace.SpanKind {
+	switch v {
+	case ext.SpanKindRPCClientEnum, string(ext.SpanKindRPCClientEnum):
+		return trace.SpanKindClient
+	case ext.SpanKindRPCServerEnum, string(ext.SpanKindRPCServerEnum):
+		return trace.SpanKindServer
+	case ext.SpanKindProducerEnum, string(ext.SpanKindProducerEnum):
+		return trace.SpanKindProducer
+	case ext.SpanKindConsumerEnum, string(ext.SpanKindConsumerEnum):
+		return trace.SpanKindConsumer
+	default:
+		return trace.SpanKindInternal
+	}
+}
@@ -0,0 +1,176 @@
+package oteltr_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+	. "github.com/rinq/rinq-go/src/internal/oteltr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ = Describe("Tracer", func() {
+	var (
+		recorder *tracetest.SpanRecorder
+		subject  *Tracer
+	)
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		subject = NewTracer(
+			sdktrace.NewTracerProvider(
+				sdktrace.WithSpanProcessor(recorder),
+			),
+		)
+	})
+
+	Describe("StartSpan", func() {
+		It("produces an equivalent OpenTelemetry span", func() {
+			span := subject.StartSpan(
+				"",
+				ext.SpanKindRPCClient,
+				opentracing.Tag{Key: "component", Value: "rinq-go"},
+			)
+			span.SetOperationName("ns::cmd command")
+			span.SetTag("namespace", "ns")
+			span.SetTag("size", 123)
+			span.LogFields(
+				log.String("event", "call"),
+				log.Int("size", 456),
+			)
+			span.Finish()
+
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(1))
+
+			s := spans[0]
+			Expect(s.Name()).To(Equal("ns::cmd command"))
+			Expect(s.SpanKind()).To(Equal(trace.SpanKindClient))
+			Expect(s.InstrumentationLibrary().Name).To(Equal(InstrumentationName))
+			Expect(s.Attributes()).To(ConsistOf(
+				attribute.String("component", "rinq-go"),
+				attribute.String("namespace", "ns"),
+				attribute.Int("size", 123),
+			))
+
+			Expect(s.Events()).To(HaveLen(1))
+			Expect(s.Events()[0].Name).To(Equal("call"))
+			Expect(s.Events()[0].Attributes).To(ConsistOf(
+				attribute.Int("size", 456),
+			))
+		})
+
+		It("uses the first reference as the parent span", func() {
+			parent := subject.StartSpan("parent")
+			child := subject.StartSpan("child", opentracing.FollowsFrom(parent.Context()))
+			child.Finish()
+			parent.Finish()
+
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(2))
+			Expect(spans[0].Parent().SpanID()).To(Equal(spans[1].SpanContext().SpanID()))
+			Expect(spans[0].SpanContext().TraceID()).To(Equal(spans[1].SpanContext().TraceID()))
+		})
+
+		It("sets the span status when the error tag is set", func() {
+			span := subject.StartSpan("")
+			ext.Error.Set(span, true)
+			span.Finish()
+
+			Expect(recorder.Ended()[0].Status().Code).To(Equal(codes.Error))
+		})
+
+		It("encodes lazy log fields", func() {
+			span := subject.StartSpan("")
+			span.LogFields(
+				log.Lazy(func(e log.Encoder) {
+					e.EmitString("attributes", "{a=b}")
+				}),
+			)
+			span.Finish()
+
+			e := recorder.Ended()[0].Events()[0]
+			Expect(e.Name).To(Equal("log"))
+			Expect(e.Attributes).To(ConsistOf(
+				attribute.String("attributes", "{a=b}"),
+			))
+		})
+	})
+
+	Describe("Inject and Extract", func() {
+		It("round-trips the span context in the text-map format", func() {
+			span := subject.StartSpan("")
+			defer span.Finish()
+
+			carrier := opentracing.TextMapCarrier{}
+			err := subject.Inject(span.Context(), opentracing.TextMap, carrier)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(carrier).To(HaveKey("traceparent"))
+
+			sc, err := subject.Extract(opentracing.TextMap, carrier)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			child := subject.StartSpan("", opentracing.ChildOf(sc))
+			child.Finish()
+
+			s := recorder.Ended()[0]
+			Expect(s.Parent().IsRemote()).To(BeTrue())
+			Expect(s.SpanContext().TraceID()).To(Equal(
+				trace.SpanContextFromContext(
+					ContextWithSpan(context.Background(), span),
+				).TraceID(),
+			))
+		})
+
+		It("does not support the binary format", func() {
+			span := subject.StartSpan("")
+			defer span.Finish()
+
+			err := subject.Inject(span.Context(), opentracing.Binary, nil)
+			Expect(err).To(Equal(opentracing.ErrUnsupportedFormat))
+
+			_, err = subject.Extract(opentracing.Binary, nil)
+			Expect(err).To(Equal(opentracing.ErrUnsupportedFormat))
+		})
+
+		It("returns an error if there is no span context in the carrier", func() {
+			_, err := subject.Extract(opentracing.TextMap, opentracing.TextMapCarrier{})
+			Expect(err).To(Equal(opentracing.ErrSpanContextNotFound))
+		})
+	})
+
+	Describe("SpanContextFromContext", func() {
+		It("returns the OpenTelemetry span context in the context", func() {
+			_, parent := sdktrace.NewTracerProvider().Tracer("app").Start(context.Background(), "parent")
+			ctx := trace.ContextWithSpan(context.Background(), parent)
+
+			sc, ok := SpanContextFromContext(ctx, subject)
+			Expect(ok).To(BeTrue())
+
+			span := subject.StartSpan("", opentracing.ChildOf(sc))
+			span.Finish()
+
+			Expect(recorder.Ended()[0].Parent().SpanID()).To(Equal(parent.SpanContext().SpanID()))
+		})
+
+		It("returns false if the tracer is not an OpenTelemetry tracer", func() {
+			_, parent := sdktrace.NewTracerProvider().Tracer("app").Start(context.Background(), "parent")
+			ctx := trace.ContextWithSpan(context.Background(), parent)
+
+			_, ok := SpanContextFromContext(ctx, opentracing.NoopTracer{})
+			Expect(ok).To(BeFalse())
+		})
+
+		It("returns false if the context does not contain a span", func() {
+			_, ok := SpanContextFromContext(context.Background(), subject)
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
@@ -2,38 +2,76 @@ package remotesession
 
 import (
 	"context"
+	"math/rand"
+	"sort"
+	"sync"
 	"sync/atomic"
+	"time"
 
-	"github.com/jmalloc/twelf/src/twelf"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/internal/command"
 	"github.com/rinq/rinq-go/src/internal/opentr"
+	"github.com/rinq/rinq-go/src/internal/watch"
 	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
 	"github.com/rinq/rinq-go/src/rinq/trace"
 )
 
 type client struct {
 	peerID  ident.PeerID
 	invoker command.Invoker
-	logger  twelf.Logger
+	timeout time.Duration
+	logger  logging.Logger
 	tracer  opentracing.Tracer
 	seq     uint32
+
+	// nonce occupies the high 32 bits of each watch and search ID, so that
+	// the IDs used by this client do not collide with those used before a
+	// restart of a peer with the same ID.
+	nonce uint64
+
+	mutex    sync.Mutex
+	watchSeq uint32
+	watchers map[uint64]watcher
+	findSeq  uint32
+	finders  map[uint64]*finder
+}
+
+// watcher is a watcher of a remote session, along with the ID of the session
+// that it watches.
+type watcher struct {
+	sessID ident.SessionID
+	w      *watch.Watcher
+}
+
+// finder collects the results of a session search as they are sent by each
+// peer.
+type finder struct {
+	results chan []ident.Ref
+	done    chan struct{}
 }
 
 func newClient(
 	peerID ident.PeerID,
 	invoker command.Invoker,
-	logger twelf.Logger,
+	timeout time.Duration,
+	logger logging.Logger,
 	tracer opentracing.Tracer,
 ) *client {
 	return &client{
 		peerID:  peerID,
 		invoker: invoker,
+		timeout: timeout,
 		logger:  logger,
 		tracer:  tracer,
+		nonce:   uint64(rand.Uint32()) << 32,
+
+		watchers: map[uint64]watcher{},
+		finders:  map[uint64]*finder{},
 	}
 }
 
@@ -46,21 +84,54 @@ func (c *client) Fetch(
 	ident.Revision,
 	attributes.VList,
 	error,
+) {
+	rsp, err := c.fetch(ctx, sessID, fetchRequest{
+		Seq:       sessID.Seq,
+		Namespace: ns,
+		Keys:      keys,
+	})
+
+	return rsp.Rev, rsp.Attrs, err
+}
+
+// FetchAll fetches every attribute in the ns namespace, or every attribute in
+// every namespace if ns is empty.
+func (c *client) FetchAll(
+	ctx context.Context,
+	sessID ident.SessionID,
+	ns string,
+) (
+	ident.Revision,
+	attributes.Catalog,
+	error,
+) {
+	rsp, err := c.fetch(ctx, sessID, fetchRequest{
+		Seq:       sessID.Seq,
+		Namespace: ns,
+		All:       true,
+	})
+
+	return rsp.Rev, rsp.Catalog, err
+}
+
+func (c *client) fetch(
+	ctx context.Context,
+	sessID ident.SessionID,
+	req fetchRequest,
+) (
+	fetchResponse,
+	error,
 ) {
 	msgID, traceID := c.nextMessageID(ctx)
 
 	span, ctx := opentr.ChildOf(ctx, c.tracer, ext.SpanKindRPCClient)
 	defer span.Finish()
 
-	opentr.SetupSessionFetch(span, ns, sessID)
+	opentr.SetupSessionFetch(span, req.Namespace, sessID)
 	opentr.AddTraceID(span, traceID)
-	opentr.LogSessionFetchRequest(span, keys)
+	opentr.LogSessionFetchRequest(span, req.Keys)
 
-	out := rinq.NewPayload(fetchRequest{
-		Seq:       sessID.Seq,
-		Namespace: ns,
-		Keys:      keys,
-	})
+	out := rinq.NewPayload(req)
 	defer out.Close()
 
 	in, err := c.invoker.CallUnicast(
@@ -76,7 +147,7 @@ func (c *client) Fetch(
 
 	if err != nil {
 		opentr.LogSessionError(span, err)
-		return 0, nil, failureToError(sessID.At(0), err)
+		return fetchResponse{}, failureToError(sessID.At(0), err)
 	}
 
 	var rsp fetchResponse
@@ -85,18 +156,25 @@ func (c *client) Fetch(
 	if err != nil {
 		opentr.LogSessionError(span, err)
 
-		return 0, nil, err
+		return fetchResponse{}, err
 	}
 
-	opentr.LogSessionFetchSuccess(span, rsp.Rev, rsp.Attrs)
+	if req.All {
+		rebaseCatalog(rsp.Catalog, rsp.SentAt)
+		opentr.LogSessionFetchSuccess(span, rsp.Rev, rsp.Catalog)
+	} else {
+		rebase(rsp.Attrs, rsp.SentAt)
+		opentr.LogSessionFetchSuccess(span, rsp.Rev, rsp.Attrs)
+	}
 
-	return rsp.Rev, rsp.Attrs, nil
+	return rsp, nil
 }
 
 func (c *client) Update(
 	ctx context.Context,
 	ref ident.Ref,
 	ns string,
+	conds []rinq.Condition,
 	attrs attributes.List,
 ) (
 	ident.Revision,
@@ -117,6 +195,7 @@ func (c *client) Update(
 		Rev:       ref.Rev,
 		Namespace: ns,
 		Attrs:     attrs,
+		Conds:     conds,
 	})
 	defer out.Close()
 
@@ -133,7 +212,7 @@ func (c *client) Update(
 
 	if err != nil {
 		opentr.LogSessionError(span, err)
-		return 0, nil, failureToError(ref, err)
+		return 0, nil, failureToError(ref, err, conds...)
 	}
 
 	var rsp updateResponse
@@ -146,13 +225,23 @@ func (c *client) Update(
 	}
 
 	diff := attributes.NewDiff(ns, rsp.Rev)
+	now := time.Now()
 
 	for index, attr := range attrs {
+		// the expiry is calculated from the TTL as the owning peer does, but
+		// using this peer's clock.
+		var expiresAt int64
+		if attr.TTL != 0 && !attr.IsFrozen && attr.Value != "" {
+			expiresAt = now.Add(attr.TTL).UnixNano()
+		}
+		attr.TTL = 0
+
 		diff.Append(
 			attributes.VAttr{
 				Attr:      attr,
 				CreatedAt: rsp.CreatedRevs[index],
 				UpdatedAt: rsp.Rev,
+				ExpiresAt: expiresAt,
 			},
 		)
 	}
@@ -163,6 +252,72 @@ func (c *client) Update(
 	return rsp.Rev, diff.VList, nil
 }
 
+func (c *client) UpdateMany(
+	ctx context.Context,
+	ref ident.Ref,
+	attrs map[string]attributes.List,
+) (
+	ident.Revision,
+	*attributes.DiffSet,
+	error,
+) {
+	msgID, traceID := c.nextMessageID(ctx)
+
+	span, ctx := opentr.ChildOf(ctx, c.tracer, ext.SpanKindRPCClient)
+	defer span.Finish()
+
+	opentr.SetupSessionUpdateMany(span, attrs, ref.ID)
+	opentr.AddTraceID(span, traceID)
+	opentr.LogSessionUpdateManyRequest(span, ref.Rev, attrs)
+
+	out := rinq.NewPayload(updateManyRequest{
+		Seq:        ref.ID.Seq,
+		Rev:        ref.Rev,
+		Namespaces: attrs,
+	})
+	defer out.Close()
+
+	in, err := c.invoker.CallUnicast(
+		ctx,
+		msgID,
+		traceID,
+		ref.ID.Peer,
+		sessionNamespace,
+		updateManyCommand,
+		out,
+	)
+	defer in.Close()
+
+	if err != nil {
+		opentr.LogSessionError(span, err)
+		return 0, nil, failureToError(ref, err)
+	}
+
+	var rsp updateManyResponse
+	err = in.Decode(&rsp)
+
+	if err != nil {
+		opentr.LogSessionError(span, err)
+
+		return 0, nil, err
+	}
+
+	diffs := attributes.NewDiffSet(rsp.Rev)
+
+	for ns := range attrs {
+		rebase(rsp.Diffs[ns], rsp.SentAt)
+
+		diff := attributes.NewDiff(ns, rsp.Rev)
+		diff.Append(rsp.Diffs[ns]...)
+		diffs.Add(diff)
+	}
+
+	logUpdate(ctx, c.logger, c.peerID, ref.ID.At(rsp.Rev), diffs)
+	opentr.LogSessionUpdateManySuccess(span, rsp.Rev, diffs)
+
+	return rsp.Rev, diffs, nil
+}
+
 func (c *client) Clear(
 	ctx context.Context,
 	ref ident.Ref,
@@ -259,6 +414,438 @@ func (c *client) Destroy(
 	return nil
 }
 
+func (c *client) Dump(
+	ctx context.Context,
+	sessID ident.SessionID,
+) (
+	ident.Revision,
+	attributes.Catalog,
+	error,
+) {
+	msgID, traceID := c.nextMessageID(ctx)
+
+	span, ctx := opentr.ChildOf(ctx, c.tracer, ext.SpanKindRPCClient)
+	defer span.Finish()
+
+	opentr.SetupSessionDump(span, sessID)
+	opentr.AddTraceID(span, traceID)
+	opentr.LogSessionDumpRequest(span)
+
+	out := rinq.NewPayload(dumpRequest{
+		Seq: sessID.Seq,
+	})
+	defer out.Close()
+
+	in, err := c.invoker.CallUnicast(
+		ctx,
+		msgID,
+		traceID,
+		sessID.Peer,
+		sessionNamespace,
+		dumpCommand,
+		out,
+	)
+	defer in.Close()
+
+	if err != nil {
+		opentr.LogSessionError(span, err)
+		return 0, nil, failureToError(sessID.At(0), err)
+	}
+
+	var rsp dumpResponse
+	err = in.Decode(&rsp)
+
+	if err != nil {
+		opentr.LogSessionError(span, err)
+
+		return 0, nil, err
+	}
+
+	rebaseCatalog(rsp.Attrs, rsp.SentAt)
+	opentr.LogSessionDumpSuccess(span, rsp.Rev, rsp.Attrs)
+
+	return rsp.Rev, rsp.Attrs, nil
+}
+
+func (c *client) Watch(
+	ctx context.Context,
+	ref ident.Ref,
+	ns string,
+	keys []string,
+) (<-chan rinq.Change, error) {
+	w := watch.New(ns, keys)
+
+	// the watcher is registered before the request is sent, as the owning
+	// peer may push changes before it responds.
+	c.mutex.Lock()
+	c.watchSeq++
+	id := c.nonce | uint64(c.watchSeq)
+	c.watchers[id] = watcher{ref.ID, w}
+	c.mutex.Unlock()
+
+	msgID, traceID := c.nextMessageID(ctx)
+
+	out := rinq.NewPayload(watchRequest{
+		ID:        id,
+		Seq:       ref.ID.Seq,
+		Rev:       ref.Rev,
+		Namespace: ns,
+		Keys:      keys,
+	})
+	defer out.Close()
+
+	in, err := c.invoker.CallUnicast(
+		ctx,
+		msgID,
+		traceID,
+		ref.ID.Peer,
+		sessionNamespace,
+		watchCommand,
+		out,
+	)
+	defer in.Close()
+
+	if err != nil {
+		c.unregister(id)
+		return nil, failureToError(ref, err)
+	}
+
+	go func() {
+		w.Run(ctx)
+		c.unregister(id)
+
+		// if the watcher was stopped by the context, and not because the
+		// session was destroyed, tell the owning peer to stop sending changes.
+		if ctx.Err() != nil {
+			c.unwatch(ref.ID.Peer, id)
+		}
+	}()
+
+	return w.C(), nil
+}
+
+// unwatch asks the owning peer to stop sending changes to the watcher with
+// the given ID. Errors are ignored, as the owning peer also stops sending
+// changes once they are rejected.
+func (c *client) unwatch(peerID ident.PeerID, id uint64) {
+	ctx := context.Background()
+	msgID, traceID := c.nextMessageID(ctx)
+
+	out := rinq.NewPayload(unwatchRequest{ID: id})
+	defer out.Close()
+
+	in, _ := c.invoker.CallUnicast(
+		ctx,
+		msgID,
+		traceID,
+		peerID,
+		sessionNamespace,
+		unwatchCommand,
+		out,
+	)
+	in.Close()
+}
+
+// forget asks the owning peer to stop sending invalidations for a session that
+// is no longer cached. Errors are ignored, as the owning peer also stops
+// sending invalidations once they are rejected.
+func (c *client) forget(sessID ident.SessionID) {
+	ctx := context.Background()
+	msgID, traceID := c.nextMessageID(ctx)
+
+	out := rinq.NewPayload(forgetRequest{Seq: sessID.Seq})
+	defer out.Close()
+
+	in, _ := c.invoker.CallUnicast(
+		ctx,
+		msgID,
+		traceID,
+		sessID.Peer,
+		sessionNamespace,
+		forgetCommand,
+		out,
+	)
+	in.Close()
+}
+
+// Push sends a change to a session owned by this peer to the watcher with the
+// given ID on another peer.
+func (c *client) Push(
+	ctx context.Context,
+	peerID ident.PeerID,
+	id uint64,
+	change rinq.Change,
+) error {
+	msgID, traceID := c.nextMessageID(ctx)
+
+	out := rinq.NewPayload(changedRequest{
+		ID:          id,
+		Seq:         change.Ref.ID.Seq,
+		Rev:         change.Ref.Rev,
+		Namespace:   change.Namespace,
+		Attrs:       change.Attrs,
+		IsDestroyed: change.IsDestroyed,
+	})
+	defer out.Close()
+
+	in, err := c.invoker.CallUnicast(
+		ctx,
+		msgID,
+		traceID,
+		peerID,
+		sessionNamespace,
+		changedCommand,
+		out,
+	)
+	in.Close()
+
+	return err
+}
+
+// Invalidate informs a peer that has fetched a session owned by this peer that
+// the session has changed, so that it can invalidate its cache.
+func (c *client) Invalidate(
+	ctx context.Context,
+	peerID ident.PeerID,
+	change rinq.Change,
+) error {
+	msgID, traceID := c.nextMessageID(ctx)
+
+	req := invalidateRequest{
+		Seq:         change.Ref.ID.Seq,
+		Rev:         change.Ref.Rev,
+		Namespace:   change.Namespace,
+		IsDestroyed: change.IsDestroyed,
+	}
+
+	for _, attr := range change.Attrs {
+		req.Keys = append(req.Keys, attr.Key)
+	}
+
+	out := rinq.NewPayload(req)
+	defer out.Close()
+
+	in, err := c.invoker.CallUnicast(
+		ctx,
+		msgID,
+		traceID,
+		peerID,
+		sessionNamespace,
+		invalidateCommand,
+		out,
+	)
+	in.Close()
+
+	return err
+}
+
+// deliver queues a change pushed by the owning peer for delivery to the
+// watcher with the given ID. It returns false if there is no such watcher.
+func (c *client) deliver(id uint64, change rinq.Change) bool {
+	c.mutex.Lock()
+	e, ok := c.watchers[id]
+	c.mutex.Unlock()
+
+	if ok {
+		e.w.Push(change)
+	}
+
+	return ok
+}
+
+// closeWatchers closes the watchers of a session that has been migrated to
+// another peer, once any changes already pushed to them have been delivered.
+func (c *client) closeWatchers(sessID ident.SessionID) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, e := range c.watchers {
+		if e.sessID == sessID {
+			e.w.Close()
+		}
+	}
+}
+
+func (c *client) unregister(id uint64) {
+	c.mutex.Lock()
+	delete(c.watchers, id)
+	c.mutex.Unlock()
+}
+
+// Find searches all peers for sessions with attributes that match con.
+//
+// Results are collected until limit sessions have been found, or the ctx
+// deadline is met. If ctx has no deadline, the client's default timeout is
+// used. A limit of zero means there is no limit.
+func (c *client) Find(
+	ctx context.Context,
+	ns string,
+	con constraint.Constraint,
+	limit int,
+) ([]ident.Ref, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	f := &finder{
+		results: make(chan []ident.Ref),
+		done:    make(chan struct{}),
+	}
+
+	// the finder is registered before the request is sent, as peers may
+	// respond before ExecuteMulticast() returns.
+	c.mutex.Lock()
+	c.findSeq++
+	id := c.nonce | uint64(c.findSeq)
+	c.finders[id] = f
+	c.mutex.Unlock()
+
+	defer func() {
+		c.mutex.Lock()
+		delete(c.finders, id)
+		c.mutex.Unlock()
+		close(f.done)
+	}()
+
+	msgID, traceID := c.nextMessageID(ctx)
+
+	out := rinq.NewPayload(findRequest{
+		ID:         id,
+		Namespace:  ns,
+		Constraint: con,
+		Limit:      limit,
+	})
+	defer out.Close()
+
+	if err := c.invoker.ExecuteMulticast(
+		ctx,
+		msgID,
+		traceID,
+		sessionNamespace,
+		findCommand,
+		out,
+	); err != nil {
+		return nil, err
+	}
+
+	var refs []ident.Ref
+
+loop:
+	for limit == 0 || len(refs) < limit {
+		select {
+		case r := <-f.results:
+			refs = append(refs, r...)
+
+		case <-ctx.Done():
+			// meeting the deadline is the expected way for the search to end,
+			// as there is no way to know when every peer has responded.
+			if ctx.Err() != context.DeadlineExceeded {
+				return nil, ctx.Err()
+			}
+
+			break loop
+		}
+	}
+
+	if limit != 0 && len(refs) > limit {
+		refs = refs[:limit]
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		a, b := refs[i].ID, refs[j].ID
+		if a.Peer != b.Peer {
+			return a.Peer.String() < b.Peer.String()
+		}
+
+		return a.Seq < b.Seq
+	})
+
+	return refs, nil
+}
+
+// Found sends the results of a session search to the peer that requested it.
+func (c *client) Found(
+	ctx context.Context,
+	peerID ident.PeerID,
+	id uint64,
+	refs []ident.Ref,
+) error {
+	msgID, traceID := c.nextMessageID(ctx)
+
+	sessions := make([]foundSession, len(refs))
+	for i, ref := range refs {
+		sessions[i] = foundSession{ref.ID.Seq, ref.Rev}
+	}
+
+	out := rinq.NewPayload(foundRequest{
+		ID:       id,
+		Sessions: sessions,
+	})
+	defer out.Close()
+
+	in, err := c.invoker.CallUnicast(
+		ctx,
+		msgID,
+		traceID,
+		peerID,
+		sessionNamespace,
+		foundCommand,
+		out,
+	)
+	in.Close()
+
+	return err
+}
+
+// Moved informs all peers that sessions owned by this peer have been migrated
+// to another peer.
+func (c *client) Moved(
+	ctx context.Context,
+	moves map[ident.SessionID]ident.SessionID,
+) error {
+	msgID, traceID := c.nextMessageID(ctx)
+
+	sessions := make([]movedSession, 0, len(moves))
+	for from, to := range moves {
+		sessions = append(sessions, movedSession{from.Seq, to})
+	}
+
+	out := rinq.NewPayload(movedRequest{
+		Sessions: sessions,
+	})
+	defer out.Close()
+
+	return c.invoker.ExecuteMulticast(
+		ctx,
+		msgID,
+		traceID,
+		sessionNamespace,
+		movedCommand,
+		out,
+	)
+}
+
+// collect passes the results of a session search to the finder with the given
+// ID. It returns false if the search has already finished.
+func (c *client) collect(id uint64, refs []ident.Ref) bool {
+	c.mutex.Lock()
+	f, ok := c.finders[id]
+	c.mutex.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	select {
+	case f.results <- refs:
+		return true
+	case <-f.done:
+		return false
+	}
+}
+
 func (c *client) nextMessageID(ctx context.Context) (msgID ident.MessageID, traceID string) {
 	seq := atomic.AddUint32(&c.seq, 1)
 	msgID = c.peerID.Session(0).At(0).Message(seq)
@@ -2,21 +2,27 @@ package remotesession
 
 import (
 	"context"
+	"fmt"
 
-	"github.com/jmalloc/twelf/src/twelf"
-	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
 	"github.com/rinq/rinq-go/src/rinq/trace"
 )
 
 func logUpdate(
 	ctx context.Context,
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	ref ident.Ref,
-	diff *attributes.Diff,
+	diff fmt.Stringer, // *attributes.Diff or *attributes.DiffSet
 ) {
 	logger.Log(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Session(ref.ID),
+			logging.Revision(ref.Rev),
+			logging.TraceID(trace.Get(ctx)),
+		},
 		"%s updated remote session %s %s [%s]",
 		peerID.ShortString(),
 		ref.ShortString(),
@@ -27,12 +33,19 @@ func logUpdate(
 
 func logClear(
 	ctx context.Context,
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	ref ident.Ref,
 	ns string,
 ) {
 	logger.Log(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Session(ref.ID),
+			logging.Revision(ref.Rev),
+			logging.TraceID(trace.Get(ctx)),
+			logging.Namespace(ns),
+		},
 		"%s cleared remote session %s %s::{*} [%s]",
 		peerID.ShortString(),
 		ref.ShortString(),
@@ -43,11 +56,17 @@ func logClear(
 
 func logClose(
 	ctx context.Context,
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	ref ident.Ref,
 ) {
 	logger.Log(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Session(ref.ID),
+			logging.Revision(ref.Rev),
+			logging.TraceID(trace.Get(ctx)),
+		},
 		"%s destroyed remote session %s [%s]",
 		peerID.ShortString(),
 		ref.ShortString(),
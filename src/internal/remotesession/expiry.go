@@ -0,0 +1,55 @@
+package remotesession
+
+import (
+	"time"
+
+	"github.com/rinq/rinq-go/src/internal/attributes"
+)
+
+// rebase converts the expiry times of the attributes in l from the clock of
+// the owning peer to the clock of this peer. sentAt is the owning peer's time
+// when the attributes were sent.
+//
+// Only the time remaining until each attribute expires is taken from the
+// owning peer, so that a difference between the clocks of the two peers does
+// not change how long the attributes are cached.
+func rebase(l attributes.VList, sentAt int64) {
+	offset, ok := clockOffset(sentAt)
+	if !ok {
+		return
+	}
+
+	for i := range l {
+		if l[i].ExpiresAt != 0 {
+			l[i].ExpiresAt += offset
+		}
+	}
+}
+
+// rebaseCatalog is a variant of rebase() that converts the expiry times of
+// every attribute in cat.
+func rebaseCatalog(cat attributes.Catalog, sentAt int64) {
+	offset, ok := clockOffset(sentAt)
+	if !ok {
+		return
+	}
+
+	for _, t := range cat {
+		for key, attr := range t {
+			if attr.ExpiresAt != 0 {
+				attr.ExpiresAt += offset
+				t[key] = attr
+			}
+		}
+	}
+}
+
+// clockOffset returns the difference between this peer's clock and the
+// owning peer's clock, as of sentAt. ok is false if sentAt is zero.
+func clockOffset(sentAt int64) (offset int64, ok bool) {
+	if sentAt == 0 {
+		return 0, false
+	}
+
+	return time.Now().UnixNano() - sentAt, true
+}
@@ -1,16 +1,20 @@
 package remotesession
 
 import (
-	"github.com/jmalloc/twelf/src/twelf"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
 )
 
 func logCacheAdd(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	sessID ident.SessionID,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Session(sessID),
+		},
 		"%s discovered remote session %s ",
 		peerID.ShortString(),
 		sessID.ShortString(),
@@ -18,11 +22,15 @@ func logCacheAdd(
 }
 
 func logCacheMark(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	sessID ident.SessionID,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Session(sessID),
+		},
 		"%s marked remote session %s for removal from the store",
 		peerID.ShortString(),
 		sessID.ShortString(),
@@ -30,13 +38,73 @@ func logCacheMark(
 }
 
 func logCacheRemove(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	sessID ident.SessionID,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Session(sessID),
+		},
 		"%s removed remote session %s from the store",
 		peerID.ShortString(),
 		sessID.ShortString(),
 	)
 }
+
+func logCacheInvalidate(
+	logger logging.Logger,
+	peerID ident.PeerID,
+	ref ident.Ref,
+	ns string,
+	keys []string,
+) {
+	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Session(ref.ID),
+			logging.Revision(ref.Rev),
+			logging.Namespace(ns),
+		},
+		"%s invalidated %s::%v of remote session %s",
+		peerID.ShortString(),
+		ns,
+		keys,
+		ref.ShortString(),
+	)
+}
+
+func logCacheEvict(
+	logger logging.Logger,
+	peerID ident.PeerID,
+	sessID ident.SessionID,
+) {
+	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Session(sessID),
+		},
+		"%s evicted destroyed remote session %s from the store",
+		peerID.ShortString(),
+		sessID.ShortString(),
+	)
+}
+
+func logCacheMove(
+	logger logging.Logger,
+	peerID ident.PeerID,
+	from ident.SessionID,
+	to ident.SessionID,
+) {
+	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Session(to),
+		},
+		"%s moved remote session %s to %s after it was migrated",
+		peerID.ShortString(),
+		from.ShortString(),
+		to.ShortString(),
+	)
+}
@@ -2,6 +2,7 @@ package remotesession
 
 import (
 	"context"
+	"time"
 
 	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/internal/namespaces"
@@ -71,10 +72,84 @@ func (r *revision) GetMany(ctx context.Context, ns string, keys ...string) (rinq
 	return table, nil
 }
 
+func (r *revision) GetAll(ctx context.Context, ns string) (rinq.AttrTable, error) {
+	namespaces.MustValidate(ns)
+
+	if r.ref.Rev == 0 {
+		return attributes.Table{}, nil
+	}
+
+	attrs, err := r.session.FetchAll(ctx, ns)
+	if err != nil {
+		return nil, err
+	}
+
+	table, ok := attrs[ns].At(r.ref.Rev, time.Now())
+	if !ok {
+		return nil, rinq.StaleFetchError{Ref: r.ref}
+	}
+
+	return table, nil
+}
+
+func (r *revision) Namespaces(ctx context.Context) ([]string, error) {
+	if r.ref.Rev == 0 {
+		return nil, nil
+	}
+
+	attrs, err := r.session.FetchAll(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	names, ok := attrs.NamespacesAt(r.ref.Rev)
+	if !ok {
+		return nil, rinq.StaleFetchError{Ref: r.ref}
+	}
+
+	return names, nil
+}
+
 func (r *revision) Update(ctx context.Context, ns string, attrs ...rinq.Attr) (rinq.Revision, error) {
 	namespaces.MustValidate(ns)
 
-	rev, err := r.session.TryUpdate(ctx, r.ref.Rev, ns, attrs)
+	rev, err := r.session.TryUpdate(ctx, r.ref.Rev, ns, nil, attrs)
+	if err != nil {
+		return r, err
+	}
+
+	return rev, nil
+}
+
+func (r *revision) UpdateIf(ctx context.Context, ns string, conds []rinq.Condition, attrs ...rinq.Attr) (rinq.Revision, error) {
+	namespaces.MustValidate(ns)
+	if len(conds) == 0 {
+		panic("at least one condition is required")
+	}
+
+	rev, err := r.session.TryUpdate(ctx, r.ref.Rev, ns, conds, attrs)
+	if err != nil {
+		return r, err
+	}
+
+	return rev, nil
+}
+
+func (r *revision) UpdateMany(ctx context.Context, attrs map[string][]rinq.Attr) (rinq.Revision, error) {
+	for ns := range attrs {
+		namespaces.MustValidate(ns)
+	}
+
+	if len(attrs) == 0 {
+		return r, nil
+	}
+
+	changes := make(map[string]attributes.List, len(attrs))
+	for ns, l := range attrs {
+		changes[ns] = l
+	}
+
+	rev, err := r.session.TryUpdateMany(ctx, r.ref.Rev, changes)
 	if err != nil {
 		return r, err
 	}
@@ -93,6 +168,16 @@ func (r *revision) Clear(ctx context.Context, ns string) (rinq.Revision, error)
 	return rev, nil
 }
 
+func (r *revision) Watch(ctx context.Context, ns string, keys ...string) (<-chan rinq.Change, error) {
+	// an empty namespace watches every namespace.
+	if ns != "" {
+		namespaces.MustValidate(ns)
+	}
+
+	// the session's current ID is used, in case it has been migrated.
+	return r.session.client.Watch(ctx, r.session.ID().At(r.ref.Rev), ns, keys)
+}
+
 func (r *revision) Destroy(ctx context.Context) error {
 	return r.session.TryDestroy(ctx, r.ref.Rev)
 }
@@ -1,9 +1,11 @@
+//go:build !without_amqp && !without_functests
 // +build !without_amqp,!without_functests
 
 package remotesession_test
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -129,6 +131,31 @@ var _ = Describe("revision (functional)", func() {
 			Expect(attr.Value).To(Equal("1"))
 		})
 
+		It("does not return an expired attribute from the cache", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1").WithTTL(500*time.Millisecond))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			attr, err := remote.Get(ctx, ns, "a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attr).To(Equal(rinq.Set("a", "1")))
+
+			Eventually(func() bool {
+				_, err := remote.Get(ctx, ns, "a")
+				return rinq.ShouldRetry(err)
+			}, 5*time.Second).Should(BeTrue())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			attr, err = remote.Get(ctx, ns, "a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attr).To(Equal(rinq.Set("a", "")))
+		})
+
 		It("returns a stale fetch error if the attribute has been updated in a later revision", func() {
 			var err error
 			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
@@ -162,6 +189,27 @@ var _ = Describe("revision (functional)", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(rinq.IsNotFound(err)).To(BeTrue())
 		})
+
+		It("returns a stale fetch error once the owning peer has invalidated a cached attribute", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			attr, err := remote.Get(ctx, ns, "a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attr).To(Equal(rinq.Set("a", "1")))
+
+			local, err = local.Update(ctx, ns, rinq.Set("a", "2"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func() error {
+				_, err := remote.Get(ctx, ns, "a")
+				return err
+			}).Should(BeAssignableToTypeOf(rinq.StaleFetchError{}))
+		})
 	})
 
 	Describe("GetMany", func() {
@@ -275,7 +323,166 @@ var _ = Describe("revision (functional)", func() {
 		})
 	})
 
+	Describe("GetAll", func() {
+		It("returns an empty attribute table at revision zero", func() {
+			attrs, err := remote.GetAll(ctx, ns)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attrs.IsEmpty()).To(BeTrue())
+		})
+
+		It("returns all attributes in the namespace", func() {
+			var err error
+			local, err = local.UpdateMany(ctx, map[string][]rinq.Attr{
+				ns:        {rinq.Set("a", "1"), rinq.Set("b", "2")},
+				ns + "-x": {rinq.Set("c", "3")},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			attrs, err := remote.GetAll(ctx, ns)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attributes.ToMap(attrs)).To(Equal(
+				map[string]rinq.Attr{
+					"a": rinq.Set("a", "1"),
+					"b": rinq.Set("b", "2"),
+				},
+			))
+		})
+
+		It("omits attributes created after the revision", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			local, err = local.Update(ctx, ns, rinq.Set("b", "2"))
+			Expect(err).NotTo(HaveOccurred())
+
+			attrs, err := remote.GetAll(ctx, ns)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attributes.ToMap(attrs)).To(Equal(
+				map[string]rinq.Attr{
+					"a": rinq.Set("a", "1"),
+				},
+			))
+		})
+
+		It("returns a stale fetch error if an attribute has been updated in a later revision", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			local, err = local.Update(ctx, ns, rinq.Set("a", "2"))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = remote.GetAll(ctx, ns)
+			Expect(err).To(HaveOccurred())
+			Expect(rinq.ShouldRetry(err)).To(BeTrue())
+		})
+
+		It("returns a not found error if the session has been destroyed", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			session.Destroy()
+			<-session.Done()
+
+			_, err = remote.GetAll(ctx, ns)
+			Expect(err).To(HaveOccurred())
+			Expect(rinq.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Describe("Namespaces", func() {
+		It("returns an empty slice at revision zero", func() {
+			names, err := remote.Namespaces(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(BeEmpty())
+		})
+
+		It("returns the namespaces that contain attributes at the revision", func() {
+			var err error
+			local, err = local.UpdateMany(ctx, map[string][]rinq.Attr{
+				ns + "-x": {rinq.Set("a", "1")},
+				ns:        {rinq.Set("b", "2")},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			local, err = local.Update(ctx, ns+"-y", rinq.Set("c", "3"))
+			Expect(err).NotTo(HaveOccurred())
+
+			names, err := remote.Namespaces(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal([]string{ns, ns + "-x"}))
+		})
+
+		It("omits namespaces that have been cleared", func() {
+			var err error
+			local, err = local.UpdateMany(ctx, map[string][]rinq.Attr{
+				ns + "-x": {rinq.Set("a", "1")},
+				ns:        {rinq.Set("b", "2")},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			local, err = local.Clear(ctx, ns+"-x")
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			names, err := remote.Namespaces(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal([]string{ns}))
+		})
+
+		It("returns a not found error if the session has been destroyed", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			session.Destroy()
+			<-session.Done()
+
+			_, err = remote.Namespaces(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(rinq.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
 	Describe("Update", func() {
+		It("clears attributes in a new revision once their TTL elapses", func() {
+			var err error
+			remote, err = remote.Update(ctx, ns, rinq.Set("a", "1").WithTTL(50*time.Millisecond), rinq.Set("b", "2"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func() string {
+				attr, _ := session.CurrentRevision().Get(ctx, ns, "a")
+				return attr.Value
+			}).Should(BeEmpty())
+
+			attrs, err := session.CurrentRevision().GetMany(ctx, ns, "a", "b")
+			Expect(err).NotTo(HaveOccurred())
+			attr, _ := attrs.Get("b")
+			Expect(attr).To(Equal(rinq.Set("b", "2")))
+		})
+
 		It("returns a stale update error if session is at a later revision", func() {
 			var err error
 			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
@@ -297,6 +504,124 @@ var _ = Describe("revision (functional)", func() {
 		})
 	})
 
+	Describe("UpdateIf", func() {
+		It("updates the attributes if the conditions are satisfied, even if the revision is stale", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+			local, err = local.Update(ctx, ns, rinq.Set("c", "3"))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.UpdateIf(
+				ctx,
+				ns,
+				[]rinq.Condition{rinq.Expect("a", "1"), rinq.ExpectEmpty("b")},
+				rinq.Set("b", "2"),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			local, err = local.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			attr, err := local.Get(ctx, ns, "b")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attr).To(Equal(rinq.Set("b", "2")))
+		})
+
+		It("returns a condition failed error if a condition is not satisfied", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = remote.UpdateIf(
+				ctx,
+				ns,
+				[]rinq.Condition{rinq.ExpectEmpty("a")},
+				rinq.Set("a", "2"),
+			)
+			Expect(err).To(BeAssignableToTypeOf(rinq.ConditionFailedError{}))
+			Expect(err.(rinq.ConditionFailedError).Condition).To(Equal(rinq.ExpectEmpty("a")))
+			Expect(rinq.ShouldRetry(err)).To(BeFalse())
+		})
+
+		It("returns a not found error if the session has been destroyed", func() {
+			session.Destroy()
+			<-session.Done()
+
+			_, err := remote.UpdateIf(ctx, ns, []rinq.Condition{rinq.ExpectEmpty("a")})
+			Expect(err).To(HaveOccurred())
+			Expect(rinq.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Describe("UpdateMany", func() {
+		It("updates attributes in several namespaces in a single revision", func() {
+			other := functest.NewNamespace()
+
+			var err error
+			remote, err = remote.UpdateMany(
+				ctx,
+				map[string][]rinq.Attr{
+					ns:    {rinq.Set("a", "1")},
+					other: {rinq.Set("b", "2")},
+				},
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			local, err = local.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			a, err := local.Get(ctx, ns, "a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a).To(Equal(rinq.Set("a", "1")))
+
+			b, err := local.Get(ctx, other, "b")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(b).To(Equal(rinq.Set("b", "2")))
+
+			info, err := client.InspectSession(ctx, session.ID())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Ref.Rev).To(BeEquivalentTo(1))
+		})
+
+		It("does not update any namespace if an attribute is frozen", func() {
+			other := functest.NewNamespace()
+
+			var err error
+			local, err = local.Update(ctx, other, rinq.Freeze("b", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			remote, err = remote.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = remote.UpdateMany(
+				ctx,
+				map[string][]rinq.Attr{
+					ns:    {rinq.Set("a", "1")},
+					other: {rinq.Set("b", "2")},
+				},
+			)
+			Expect(err).To(BeAssignableToTypeOf(rinq.FrozenAttributesError{}))
+
+			local, err = local.Refresh(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			a, err := local.Get(ctx, ns, "a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.Value).To(BeEmpty())
+		})
+
+		It("returns a stale update error if session is at a later revision", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = remote.UpdateMany(ctx, map[string][]rinq.Attr{ns: {rinq.Set("a", "2")}})
+			Expect(err).To(HaveOccurred())
+			Expect(rinq.ShouldRetry(err)).To(BeTrue())
+		})
+	})
+
 	Describe("Clear", func() {
 		It("clears the attributes", func() {
 			var err error
@@ -360,6 +685,122 @@ var _ = Describe("revision (functional)", func() {
 		})
 	})
 
+	Describe("Watch", func() {
+		var (
+			watchCtx context.Context
+			cancel   func()
+		)
+
+		BeforeEach(func() {
+			watchCtx, cancel = context.WithCancel(ctx)
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		It("delivers changes made after the watch is started", func() {
+			changes, err := remote.Watch(watchCtx, ns)
+			Expect(err).NotTo(HaveOccurred())
+
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			var c rinq.Change
+			Eventually(changes).Should(Receive(&c))
+			Expect(c.Ref.ID).To(Equal(session.ID()))
+			Expect(c.Ref.Rev).To(BeEquivalentTo(1))
+			Expect(c.Namespace).To(Equal(ns))
+			Expect(c.Attrs).To(Equal([]rinq.Attr{rinq.Set("a", "1")}))
+		})
+
+		It("delivers changes made between the revision and the head revision first", func() {
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+			local, err = local.Update(ctx, ns, rinq.Set("b", "2"))
+			Expect(err).NotTo(HaveOccurred())
+
+			changes, err := remote.Watch(watchCtx, ns)
+			Expect(err).NotTo(HaveOccurred())
+
+			var c rinq.Change
+			Eventually(changes).Should(Receive(&c))
+			Expect(c.Ref.Rev).To(BeEquivalentTo(2))
+			Expect(c.Attrs).To(Equal([]rinq.Attr{rinq.Set("a", "1"), rinq.Set("b", "2")}))
+		})
+
+		It("delivers changes to every namespace if the namespace is empty", func() {
+			other := functest.NewNamespace()
+
+			var err error
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			changes, err := remote.Watch(watchCtx, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			local, err = local.Update(ctx, other, rinq.Set("b", "2"))
+			Expect(err).NotTo(HaveOccurred())
+
+			var c rinq.Change
+			Eventually(changes).Should(Receive(&c))
+			Expect(c.Ref.Rev).To(BeEquivalentTo(1))
+			Expect(c.Namespace).To(Equal(ns))
+			Expect(c.Attrs).To(Equal([]rinq.Attr{rinq.Set("a", "1")}))
+
+			Eventually(changes).Should(Receive(&c))
+			Expect(c.Ref.Rev).To(BeEquivalentTo(2))
+			Expect(c.Namespace).To(Equal(other))
+			Expect(c.Attrs).To(Equal([]rinq.Attr{rinq.Set("b", "2")}))
+		})
+
+		It("only delivers changes to the given keys", func() {
+			changes, err := remote.Watch(watchCtx, ns, "b")
+			Expect(err).NotTo(HaveOccurred())
+
+			local, err = local.Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).NotTo(HaveOccurred())
+			local, err = local.Update(ctx, ns, rinq.Set("a", "2"), rinq.Set("b", "3"))
+			Expect(err).NotTo(HaveOccurred())
+
+			var c rinq.Change
+			Eventually(changes).Should(Receive(&c))
+			Expect(c.Ref.Rev).To(BeEquivalentTo(2))
+			Expect(c.Attrs).To(Equal([]rinq.Attr{rinq.Set("b", "3")}))
+		})
+
+		It("closes the channel after the session is destroyed", func() {
+			changes, err := remote.Watch(watchCtx, ns)
+			Expect(err).NotTo(HaveOccurred())
+
+			session.Destroy()
+
+			var c rinq.Change
+			Eventually(changes).Should(Receive(&c))
+			Expect(c.IsDestroyed).To(BeTrue())
+			Eventually(changes).Should(BeClosed())
+		})
+
+		It("closes the channel when the context is canceled", func() {
+			changes, err := remote.Watch(watchCtx, ns)
+			Expect(err).NotTo(HaveOccurred())
+
+			cancel()
+
+			Eventually(changes).Should(BeClosed())
+		})
+
+		It("returns a not found error if the session has been destroyed", func() {
+			session.Destroy()
+			<-session.Done()
+
+			_, err := remote.Watch(watchCtx, ns)
+			Expect(err).To(HaveOccurred())
+			Expect(rinq.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
 	Describe("Destroy", func() {
 		It("returns a stale update error if session is at a later revision", func() {
 			var err error
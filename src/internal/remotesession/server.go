@@ -3,35 +3,74 @@ package remotesession
 import (
 	"context"
 	"errors"
+	"sync"
+	"time"
 
-	"github.com/jmalloc/twelf/src/twelf"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/internal/command"
 	"github.com/rinq/rinq-go/src/internal/localsession"
 	"github.com/rinq/rinq-go/src/internal/opentr"
+	"github.com/rinq/rinq-go/src/internal/x/syncx"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
 	"github.com/rinq/rinq-go/src/rinq/trace"
 )
 
 type server struct {
 	peerID   ident.PeerID
 	sessions *localsession.Store
-	logger   twelf.Logger
+	remote   Store
+	client   *client
+	logger   logging.Logger
+
+	mutex    sync.Mutex
+	watches  map[watchKey]context.CancelFunc
+	fetchers map[ident.SessionID]*fetchers
+}
+
+// fetchers is the set of peers that have fetched a local session, and
+// therefore need to be told when it changes.
+type fetchers struct {
+	id     ident.SessionID
+	peers  map[ident.PeerID]*invalidator
+	cancel context.CancelFunc
+}
+
+// invalidator sends the changes to a session to one of the peers that has
+// fetched it, in the order they occur.
+type invalidator struct {
+	queue  syncx.Queue
+	cancel context.CancelFunc
+}
+
+// watchKey identifies a watcher on another peer.
+type watchKey struct {
+	Peer ident.PeerID
+	ID   uint64
 }
 
 // Listen attaches a new remote session service to the given command server.
+//
+// remote is the store used by this peer to access remote sessions. Changes
+// pushed by other peers are delivered to the watchers created by its
+// revisions.
 func Listen(
 	svr command.Server,
 	peerID ident.PeerID,
 	sessions *localsession.Store,
-	logger twelf.Logger,
+	remote Store,
+	logger logging.Logger,
 ) error {
 	s := &server{
 		peerID:   peerID,
 		sessions: sessions,
+		remote:   remote,
+		client:   remote.sessionClient(),
 		logger:   logger,
+		watches:  map[watchKey]context.CancelFunc{},
+		fetchers: map[ident.SessionID]*fetchers{},
 	}
 
 	_, err := svr.Listen(sessionNamespace, s.handle)
@@ -50,10 +89,30 @@ func (s *server) handle(
 		s.fetch(ctx, req, res)
 	case updateCommand:
 		s.update(ctx, req, res)
+	case updateManyCommand:
+		s.updateMany(ctx, req, res)
 	case clearCommand:
 		s.clear(ctx, req, res)
 	case destroyCommand:
 		s.destroy(ctx, req, res)
+	case dumpCommand:
+		s.dump(ctx, req, res)
+	case watchCommand:
+		s.watch(ctx, req, res)
+	case unwatchCommand:
+		s.unwatch(ctx, req, res)
+	case changedCommand:
+		s.changed(ctx, req, res)
+	case invalidateCommand:
+		s.invalidate(ctx, req, res)
+	case forgetCommand:
+		s.forget(ctx, req, res)
+	case findCommand:
+		s.find(ctx, req, res)
+	case foundCommand:
+		s.found(ctx, req, res)
+	case movedCommand:
+		s.moved(ctx, req, res)
 	default:
 		res.Error(errors.New("unknown command"))
 	}
@@ -87,8 +146,22 @@ func (s *server) fetch(
 		return
 	}
 
+	s.track(sess, req.ID.Ref.ID.Peer)
+
+	// clear any attributes that have expired but are still awaiting the expiry
+	// timer, so that their values are not returned.
+	sess.ClearExpired()
+
+	if args.All {
+		s.fetchAll(sess, args.Namespace, span, res)
+		return
+	}
+
 	ref, attrs := sess.AttrsIn(args.Namespace)
-	rsp := fetchResponse{Rev: ref.Rev}
+	rsp := fetchResponse{
+		Rev:    ref.Rev,
+		SentAt: time.Now().UnixNano(),
+	}
 	count := len(args.Keys)
 
 	if count != 0 {
@@ -108,6 +181,39 @@ func (s *server) fetch(
 	opentr.LogSessionFetchSuccess(span, rsp.Rev, rsp.Attrs)
 }
 
+// fetchAll responds with every attribute in the ns namespace of sess, or with
+// every attribute in every namespace if ns is empty.
+func (s *server) fetchAll(
+	sess *localsession.Session,
+	ns string,
+	span opentracing.Span,
+	res rinq.Response,
+) {
+	rsp := fetchResponse{
+		SentAt: time.Now().UnixNano(),
+	}
+
+	if ns == "" {
+		var ref ident.Ref
+		ref, rsp.Catalog = sess.Attrs()
+		rsp.Rev = ref.Rev
+	} else {
+		ref, attrs := sess.AttrsIn(ns)
+		rsp.Rev = ref.Rev
+
+		if !attrs.IsEmpty() {
+			rsp.Catalog = attributes.Catalog{ns: attrs}
+		}
+	}
+
+	payload := rinq.NewPayload(rsp)
+	defer payload.Close()
+
+	res.Done(payload)
+
+	opentr.LogSessionFetchSuccess(span, rsp.Rev, rsp.Catalog)
+}
+
 func (s *server) update(
 	ctx context.Context,
 	req rinq.Request,
@@ -136,7 +242,9 @@ func (s *server) update(
 		return
 	}
 
-	_, diff, err := sess.TryUpdate(args.Rev, args.Namespace, args.Attrs)
+	s.track(sess, req.ID.Ref.ID.Peer)
+
+	_, diff, created, err := sess.TryUpdate(args.Rev, args.Namespace, args.Conds, args.Attrs, source(ctx, req))
 	if err != nil {
 		res.Error(errorToFailure(err))
 		opentr.LogSessionError(span, err)
@@ -147,15 +255,66 @@ func (s *server) update(
 
 	rsp := updateResponse{
 		Rev:         diff.Revision,
-		CreatedRevs: make([]ident.Revision, 0, len(args.Attrs)),
+		CreatedRevs: created,
 	}
-	_, attrs := sess.AttrsIn(args.Namespace)
 
-	for _, attr := range args.Attrs {
-		rsp.CreatedRevs = append(
-			rsp.CreatedRevs,
-			attrs[attr.Key].CreatedAt,
-		)
+	payload := rinq.NewPayload(rsp)
+	defer payload.Close()
+
+	res.Done(payload)
+
+	opentr.LogSessionUpdateSuccess(span, rsp.Rev, diff)
+}
+
+func (s *server) updateMany(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	span := opentracing.SpanFromContext(ctx)
+
+	var args updateManyRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		opentr.LogSessionError(span, err)
+		return
+	}
+
+	sessID := s.peerID.Session(args.Seq)
+
+	opentr.SetupSessionUpdateMany(span, args.Namespaces, sessID)
+	opentr.AddTraceID(span, trace.Get(ctx))
+	opentr.LogSessionUpdateManyRequest(span, args.Rev, args.Namespaces)
+
+	sess, ok := s.sessions.Get(sessID)
+	if !ok {
+		err := res.Fail(notFoundFailure, "")
+		opentr.LogSessionError(span, err)
+		return
+	}
+
+	s.track(sess, req.ID.Ref.ID.Peer)
+
+	_, diffs, err := sess.TryUpdateMany(args.Rev, args.Namespaces, source(ctx, req))
+	if err != nil {
+		res.Error(errorToFailure(err))
+		opentr.LogSessionError(span, err)
+		return
+	}
+
+	logRemoteUpdate(ctx, s.logger, sessID.At(diffs.Revision), req.ID.Ref.ID.Peer, diffs)
+
+	rsp := updateManyResponse{
+		Rev:    diffs.Revision,
+		Diffs:  map[string]attributes.VList{},
+		SentAt: time.Now().UnixNano(),
+	}
+
+	for _, diff := range diffs.Diffs {
+		if !diff.IsEmpty() {
+			rsp.Diffs[diff.Namespace] = diff.VList
+		}
 	}
 
 	payload := rinq.NewPayload(rsp)
@@ -163,7 +322,7 @@ func (s *server) update(
 
 	res.Done(payload)
 
-	opentr.LogSessionUpdateSuccess(span, rsp.Rev, diff)
+	opentr.LogSessionUpdateManySuccess(span, rsp.Rev, diffs)
 }
 
 func (s *server) clear(
@@ -194,7 +353,9 @@ func (s *server) clear(
 		return
 	}
 
-	_, diff, err := sess.TryClear(args.Rev, args.Namespace)
+	s.track(sess, req.ID.Ref.ID.Peer)
+
+	_, diff, err := sess.TryClear(args.Rev, args.Namespace, source(ctx, req))
 	if err != nil {
 		res.Error(errorToFailure(err))
 		opentr.LogSessionError(span, err)
@@ -243,7 +404,7 @@ func (s *server) destroy(
 		return
 	}
 
-	first, err := sess.TryDestroy(args.Rev)
+	first, err := sess.TryDestroy(args.Rev, source(ctx, req))
 	if err != nil {
 		res.Error(errorToFailure(err))
 		opentr.LogSessionError(span, err)
@@ -258,3 +419,460 @@ func (s *server) destroy(
 
 	opentr.LogSessionDestroySuccess(span)
 }
+
+func (s *server) dump(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	span := opentracing.SpanFromContext(ctx)
+
+	var args dumpRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		opentr.LogSessionError(span, err)
+		return
+	}
+
+	sessID := s.peerID.Session(args.Seq)
+
+	opentr.SetupSessionDump(span, sessID)
+	opentr.AddTraceID(span, trace.Get(ctx))
+	opentr.LogSessionDumpRequest(span)
+
+	sess, ok := s.sessions.Get(sessID)
+	if !ok {
+		err := res.Fail(notFoundFailure, "")
+		opentr.LogSessionError(span, err)
+		return
+	}
+
+	s.track(sess, req.ID.Ref.ID.Peer)
+
+	ref, attrs := sess.Attrs()
+	rsp := dumpResponse{
+		Rev:    ref.Rev,
+		Attrs:  attrs,
+		SentAt: time.Now().UnixNano(),
+	}
+
+	payload := rinq.NewPayload(rsp)
+	defer payload.Close()
+
+	res.Done(payload)
+
+	opentr.LogSessionDumpSuccess(span, rsp.Rev, rsp.Attrs)
+}
+
+func (s *server) watch(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	var args watchRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		return
+	}
+
+	sessID := s.peerID.Session(args.Seq)
+
+	sess, ok := s.sessions.Get(sessID)
+	if !ok {
+		_ = res.Fail(notFoundFailure, "")
+		return
+	}
+
+	key := watchKey{req.ID.Ref.ID.Peer, args.ID}
+
+	// the watch outlives the request, it is stopped when the watching peer
+	// sends an "unwatch" command, or the session is destroyed.
+	watchCtx, cancel := context.WithCancel(context.Background())
+
+	changes, err := sess.Watch(watchCtx, args.Rev, args.Namespace, args.Keys)
+	if err != nil {
+		cancel()
+		res.Error(errorToFailure(err))
+		return
+	}
+
+	s.mutex.Lock()
+	s.watches[key] = cancel
+	s.mutex.Unlock()
+
+	logRemoteWatch(ctx, s.logger, sessID.At(args.Rev), key.Peer, args.Namespace)
+
+	go s.push(watchCtx, cancel, key, changes)
+
+	res.Close()
+}
+
+// push sends each change on the changes channel to the watcher on another
+// peer. Any failure to push a change stops the watch.
+//
+// If the session is migrated, changes is closed without a destroyed change.
+// The watching peer closes its watcher when it is informed of the move.
+func (s *server) push(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	key watchKey,
+	changes <-chan rinq.Change,
+) {
+	defer func() {
+		s.mutex.Lock()
+		delete(s.watches, key)
+		s.mutex.Unlock()
+
+		cancel()
+	}()
+
+	for c := range changes {
+		if err := s.client.Push(ctx, key.Peer, key.ID, c); err != nil {
+			logWatchPushError(s.logger, c.Ref, key.Peer, err)
+			return
+		}
+	}
+}
+
+func (s *server) unwatch(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	var args unwatchRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		return
+	}
+
+	key := watchKey{req.ID.Ref.ID.Peer, args.ID}
+
+	s.mutex.Lock()
+	cancel, ok := s.watches[key]
+	s.mutex.Unlock()
+
+	if ok {
+		cancel()
+	}
+
+	res.Close()
+}
+
+func (s *server) changed(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	var args changedRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		return
+	}
+
+	change := rinq.Change{
+		Ref:         req.ID.Ref.ID.Peer.Session(args.Seq).At(args.Rev),
+		Namespace:   args.Namespace,
+		Attrs:       args.Attrs,
+		IsDestroyed: args.IsDestroyed,
+	}
+
+	if !s.client.deliver(args.ID, change) {
+		_ = res.Fail(notFoundFailure, "")
+		return
+	}
+
+	res.Close()
+}
+
+func (s *server) invalidate(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	var args invalidateRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		return
+	}
+
+	ref := req.ID.Ref.ID.Peer.Session(args.Seq).At(args.Rev)
+
+	if !s.remote.invalidate(ref, args.Namespace, args.Keys, args.IsDestroyed) {
+		// the session is no longer cached, the owning peer stops sending
+		// invalidations to this peer.
+		_ = res.Fail(notFoundFailure, "")
+		return
+	}
+
+	res.Close()
+}
+
+func (s *server) forget(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	var args forgetRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		return
+	}
+
+	sessID := s.peerID.Session(args.Seq)
+	peerID := req.ID.Ref.ID.Peer
+
+	s.mutex.Lock()
+	f, ok := s.fetchers[sessID]
+	var inv *invalidator
+	if ok {
+		inv = f.peers[peerID]
+	}
+	s.mutex.Unlock()
+
+	if inv != nil {
+		s.untrack(f, peerID, inv)
+	}
+
+	res.Close()
+}
+
+// track records that a peer has fetched sess, so that it is informed when
+// sess is modified or destroyed. It has no effect if the peer is already
+// tracked.
+//
+// The peer is tracked until it forgets the session, or fails to accept an
+// invalidation, such as when it has stopped. The session is watched for as
+// long as any peer is tracked.
+func (s *server) track(sess *localsession.Session, peerID ident.PeerID) {
+	if peerID == s.peerID {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	id := sess.ID()
+
+	f, ok := s.fetchers[id]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		ref, _ := sess.Attrs()
+
+		changes, err := sess.Watch(ctx, ref.Rev, "", nil)
+		if err != nil {
+			// the session has been destroyed
+			cancel()
+			return
+		}
+
+		f = &fetchers{
+			id:     id,
+			peers:  map[ident.PeerID]*invalidator{},
+			cancel: cancel,
+		}
+		s.fetchers[id] = f
+
+		go s.queueInvalidations(f, changes)
+	} else if _, ok := f.peers[peerID]; ok {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	inv := &invalidator{cancel: cancel}
+	f.peers[peerID] = inv
+
+	go s.pushInvalidations(ctx, f, peerID, inv)
+}
+
+// queueInvalidations queues each change on the changes channel for delivery
+// to the peers that have fetched the session.
+func (s *server) queueInvalidations(
+	f *fetchers,
+	changes <-chan rinq.Change,
+) {
+	for c := range changes {
+		s.mutex.Lock()
+		for _, inv := range f.peers {
+			inv.queue.Push(c)
+		}
+		s.mutex.Unlock()
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.fetchers[f.id] == f {
+		delete(s.fetchers, f.id)
+	}
+
+	// the queues are closed rather than canceled, so that the final
+	// invalidation is still sent after the watch has stopped.
+	for _, inv := range f.peers {
+		inv.queue.Close()
+	}
+
+	f.cancel()
+}
+
+// invalidateTimeout is the time each peer is given to accept an invalidation.
+// A peer that does not respond in time is sent no further invalidations until
+// it fetches the session again.
+const invalidateTimeout = 1 * time.Second
+
+// pushInvalidations sends the changes queued by inv to a peer that has
+// fetched the session, one at a time and in order. A peer that fails to
+// accept a change is not sent any further changes, until it fetches the
+// session again.
+//
+// Each peer has its own queue, so that a slow or unresponsive peer does not
+// delay the invalidations sent to other peers.
+func (s *server) pushInvalidations(
+	ctx context.Context,
+	f *fetchers,
+	peerID ident.PeerID,
+	inv *invalidator,
+) {
+	inv.queue.Run(ctx, func(v interface{}) bool {
+		ctx, cancel := context.WithTimeout(ctx, invalidateTimeout)
+		defer cancel()
+
+		if err := s.client.Invalidate(ctx, peerID, v.(rinq.Change)); err != nil {
+			s.untrack(f, peerID, inv)
+			return false
+		}
+
+		return true
+	})
+}
+
+// untrack stops sending invalidations to a peer. The session is no longer
+// watched once there are no peers left to inform.
+func (s *server) untrack(f *fetchers, peerID ident.PeerID, inv *invalidator) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	inv.cancel()
+
+	if f.peers[peerID] != inv {
+		return
+	}
+
+	delete(f.peers, peerID)
+
+	if len(f.peers) == 0 {
+		if s.fetchers[f.id] == f {
+			delete(s.fetchers, f.id)
+		}
+
+		f.cancel()
+	}
+}
+
+func (s *server) find(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	var args findRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		return
+	}
+
+	// find is multicast, no response is expected.
+	res.Close()
+
+	var refs []ident.Ref
+
+	s.sessions.Each(func(sess *localsession.Session) {
+		if args.Limit != 0 && len(refs) >= args.Limit {
+			return
+		}
+
+		// expired attributes are cleared first, as they are when fetched, so
+		// that sessions are not matched by their expired values.
+		sess.ClearExpired()
+
+		ref, attrs := sess.Attrs()
+		if attrs.MatchConstraint(args.Namespace, args.Constraint) {
+			refs = append(refs, ref)
+		}
+	})
+
+	peerID := req.ID.Ref.ID.Peer
+
+	logRemoteFind(ctx, s.logger, s.peerID, peerID, args.Namespace, args.Constraint, len(refs))
+
+	if len(refs) == 0 {
+		return
+	}
+
+	// the error is ignored, as it most likely means the deadline has passed
+	// and the requesting peer is no longer collecting results.
+	_ = s.client.Found(ctx, peerID, args.ID, refs)
+}
+
+func (s *server) found(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	var args foundRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		return
+	}
+
+	peerID := req.ID.Ref.ID.Peer
+	refs := make([]ident.Ref, len(args.Sessions))
+
+	for i, sess := range args.Sessions {
+		refs[i] = peerID.Session(sess.Seq).At(sess.Rev)
+	}
+
+	if !s.client.collect(args.ID, refs) {
+		_ = res.Fail(notFoundFailure, "")
+		return
+	}
+
+	res.Close()
+}
+
+func (s *server) moved(
+	ctx context.Context,
+	req rinq.Request,
+	res rinq.Response,
+) {
+	var args movedRequest
+
+	if err := req.Payload.Decode(&args); err != nil {
+		res.Error(err)
+		return
+	}
+
+	// moved is multicast, no response is expected.
+	res.Close()
+
+	peerID := req.ID.Ref.ID.Peer
+
+	for _, m := range args.Sessions {
+		s.remote.move(peerID.Session(m.Seq), m.To)
+	}
+}
+
+// source returns the source of session events caused by req, which was sent
+// by another peer.
+func source(ctx context.Context, req rinq.Request) rinq.SessionEventSource {
+	return rinq.SessionEventSource{
+		Peer:    req.ID.Ref.ID.Peer,
+		TraceID: trace.Get(ctx),
+	}
+}
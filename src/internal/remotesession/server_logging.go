@@ -2,22 +2,30 @@ package remotesession
 
 import (
 	"context"
+	"fmt"
 
-	"github.com/jmalloc/twelf/src/twelf"
 	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/internal/localsession"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
 	"github.com/rinq/rinq-go/src/rinq/trace"
 )
 
 func logRemoteUpdate(
 	ctx context.Context,
-	logger twelf.Logger,
+	logger logging.Logger,
 	ref ident.Ref,
 	peerID ident.PeerID,
-	diff *attributes.Diff,
+	diff fmt.Stringer, // *attributes.Diff or *attributes.DiffSet
 ) {
 	logger.Log(
+		[]logging.Field{
+			logging.Session(ref.ID),
+			logging.Revision(ref.Rev),
+			logging.Peer(peerID),
+			logging.TraceID(trace.Get(ctx)),
+		},
 		"%s session updated by %s %s [%s]",
 		ref.ShortString(),
 		peerID.ShortString(),
@@ -28,12 +36,18 @@ func logRemoteUpdate(
 
 func logRemoteClear(
 	ctx context.Context,
-	logger twelf.Logger,
+	logger logging.Logger,
 	ref ident.Ref,
 	peerID ident.PeerID,
 	diff *attributes.Diff,
 ) {
 	logger.Log(
+		[]logging.Field{
+			logging.Session(ref.ID),
+			logging.Revision(ref.Rev),
+			logging.Peer(peerID),
+			logging.TraceID(trace.Get(ctx)),
+		},
 		"%s session cleared by %s %s [%s]",
 		ref.ShortString(),
 		peerID.ShortString(),
@@ -42,15 +56,89 @@ func logRemoteClear(
 	)
 }
 
+func logRemoteWatch(
+	ctx context.Context,
+	logger logging.Logger,
+	ref ident.Ref,
+	peerID ident.PeerID,
+	ns string,
+) {
+	logger.Debug(
+		[]logging.Field{
+			logging.Session(ref.ID),
+			logging.Revision(ref.Rev),
+			logging.Peer(peerID),
+			logging.Namespace(ns),
+			logging.TraceID(trace.Get(ctx)),
+		},
+		"%s session watched by %s in '%s' namespace [%s]",
+		ref.ShortString(),
+		peerID.ShortString(),
+		ns,
+		trace.Get(ctx),
+	)
+}
+
+func logRemoteFind(
+	ctx context.Context,
+	logger logging.Logger,
+	peerID ident.PeerID,
+	requester ident.PeerID,
+	ns string,
+	con constraint.Constraint,
+	count int,
+) {
+	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Namespace(ns),
+			logging.TraceID(trace.Get(ctx)),
+		},
+		"%s found %d session(s) matching %s in '%s' namespace for %s [%s]",
+		peerID.ShortString(),
+		count,
+		con,
+		ns,
+		requester.ShortString(),
+		trace.Get(ctx),
+	)
+}
+
+func logWatchPushError(
+	logger logging.Logger,
+	ref ident.Ref,
+	peerID ident.PeerID,
+	err error,
+) {
+	logger.Log(
+		[]logging.Field{
+			logging.Session(ref.ID),
+			logging.Revision(ref.Rev),
+			logging.Peer(peerID),
+			logging.Error(err),
+		},
+		"%s session stopped pushing changes to %s: %s",
+		ref.ShortString(),
+		peerID.ShortString(),
+		err,
+	)
+}
+
 func logRemoteDestroy(
 	ctx context.Context,
-	logger twelf.Logger,
+	logger logging.Logger,
 	sess *localsession.Session,
 	peerID ident.PeerID,
 ) {
 	ref, attrs := sess.Attrs()
 
 	logger.Log(
+		[]logging.Field{
+			logging.Session(ref.ID),
+			logging.Revision(ref.Rev),
+			logging.Peer(peerID),
+			logging.TraceID(trace.Get(ctx)),
+		},
 		"%s session destroyed by %s %s [%s]",
 		ref.ShortString(),
 		peerID.ShortString(),
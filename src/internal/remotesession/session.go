@@ -3,33 +3,50 @@ package remotesession
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/internal/revisions"
 	"github.com/rinq/rinq-go/src/internal/x/syncx"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/metrics"
 )
 
 type session struct {
-	id     ident.SessionID
-	client *client
+	client  *client
+	metrics metrics.Recorder
 
 	mutex      sync.RWMutex
+	id         ident.SessionID
 	highestRev ident.Revision
 	cache      attrTableCache
 	isClosed   bool
 }
 
-func newSession(id ident.SessionID, client *client) *session {
+func newSession(
+	id ident.SessionID,
+	client *client,
+	recorder metrics.Recorder,
+) *session {
 	return &session{
-		id:     id,
-		client: client,
+		id:      id,
+		client:  client,
+		metrics: recorder,
 
 		cache: attrTableCache{},
 	}
 }
 
+// ID returns the ID of the session. It changes if the session is migrated to
+// another peer.
+func (s *session) ID() ident.SessionID {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.id
+}
+
 func (s *session) Head(ctx context.Context) (rinq.Revision, error) {
 	unlock := syncx.RLock(&s.mutex)
 	defer unlock()
@@ -38,9 +55,10 @@ func (s *session) Head(ctx context.Context) (rinq.Revision, error) {
 		return nil, rinq.NotFoundError{ID: s.id}
 	}
 
+	id := s.id
 	unlock()
 
-	rev, _, err := s.client.Fetch(ctx, s.id, "", nil)
+	rev, _, err := s.client.Fetch(ctx, id, "", nil)
 
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -81,11 +99,18 @@ func (s *session) Fetch(
 	solvedAttrs, unsolvedKeys, err := s.fetchLocal(rev, ns, keys)
 	if err != nil {
 		return nil, err
-	} else if len(unsolvedKeys) == 0 {
+	}
+
+	s.metrics.SessionCacheLookup(
+		len(keys)-len(unsolvedKeys),
+		len(unsolvedKeys),
+	)
+
+	if len(unsolvedKeys) == 0 {
 		return solvedAttrs, nil
 	}
 
-	fetchedRev, fetchedAttrs, err := s.client.Fetch(ctx, s.id, ns, unsolvedKeys)
+	fetchedRev, fetchedAttrs, err := s.client.Fetch(ctx, s.ID(), ns, unsolvedKeys)
 
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -147,10 +172,69 @@ func (s *session) Fetch(
 	return solvedAttrs, nil
 }
 
+// FetchAll fetches every attribute in the ns namespace of the session, or every
+// attribute in every namespace if ns is empty.
+//
+// The cache can not be used to satisfy the request as it may not contain
+// every attribute, but the fetched attributes are added to the cache.
+func (s *session) FetchAll(
+	ctx context.Context,
+	ns string,
+) (attributes.Catalog, error) {
+	unlock := syncx.RLock(&s.mutex)
+	defer unlock()
+
+	if s.isClosed {
+		return nil, rinq.NotFoundError{ID: s.id}
+	}
+
+	id := s.id
+	unlock()
+
+	fetchedRev, fetchedAttrs, err := s.client.FetchAll(ctx, id, ns)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.updateState(fetchedRev, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for n, attrs := range fetchedAttrs {
+		cache, isExistingNamespace := s.cache[n]
+
+		for _, attr := range attrs {
+			entry := cache[attr.Key]
+
+			// Update the cache entry if the fetched revision is newer.
+			if fetchedRev > entry.FetchedAt {
+				if cache == nil {
+					cache = attrNamespaceCache{}
+				}
+
+				cache[attr.Key] = cachedAttr{attr, fetchedRev}
+			}
+		}
+
+		if !isExistingNamespace && cache != nil {
+			s.cache[n] = cache
+		}
+	}
+
+	return fetchedAttrs, nil
+}
+
+// TryUpdate updates attributes in the ns namespace of the session.
+//
+// If conds is nil, the update fails if rev is not the current revision.
+// Otherwise the conditions are evaluated by the owning peer instead.
 func (s *session) TryUpdate(
 	ctx context.Context,
 	rev ident.Revision,
 	ns string,
+	conds []rinq.Condition,
 	attrs attributes.List,
 ) (rinq.Revision, error) {
 	unlock := syncx.RLock(&s.mutex)
@@ -162,7 +246,7 @@ func (s *session) TryUpdate(
 
 	ref := s.id.At(rev)
 
-	if s.highestRev > rev {
+	if conds == nil && s.highestRev > rev {
 		return nil, rinq.StaleUpdateError{Ref: ref}
 	}
 
@@ -180,7 +264,9 @@ func (s *session) TryUpdate(
 				return nil, rinq.FrozenAttributesError{Ref: ref}
 			}
 
-			if entry.FetchedAt == rev && attr == entry.Attr.Attr {
+			// Attributes that expire are always sent, as setting them again
+			// changes their expiry.
+			if conds == nil && entry.FetchedAt == rev && attr == entry.Attr.Attr && entry.Attr.ExpiresAt == 0 {
 				continue
 			}
 		}
@@ -190,7 +276,7 @@ func (s *session) TryUpdate(
 
 	unlock()
 
-	updatedRev, returnedAttrs, err := s.client.Update(ctx, ref, ns, updateAttrs)
+	updatedRev, returnedAttrs, err := s.client.Update(ctx, ref, ns, conds, updateAttrs)
 
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -224,6 +310,91 @@ func (s *session) TryUpdate(
 	}, nil
 }
 
+// TryUpdateMany updates attributes in several namespaces of the session in a
+// single revision.
+func (s *session) TryUpdateMany(
+	ctx context.Context,
+	rev ident.Revision,
+	attrs map[string]attributes.List,
+) (rinq.Revision, error) {
+	unlock := syncx.RLock(&s.mutex)
+	defer unlock()
+
+	if s.isClosed {
+		return nil, rinq.NotFoundError{ID: s.id}
+	}
+
+	ref := s.id.At(rev)
+
+	if s.highestRev > rev {
+		return nil, rinq.StaleUpdateError{Ref: ref}
+	}
+
+	updateAttrs := make(map[string]attributes.List, len(attrs))
+
+	for ns, l := range attrs {
+		cache := s.cache[ns]
+		nsAttrs := make(attributes.List, 0, len(l))
+
+		for _, attr := range l {
+			if entry, ok := cache[attr.Key]; ok {
+				if entry.Attr.IsFrozen {
+					if attr == entry.Attr.Attr {
+						continue
+					}
+
+					return nil, rinq.FrozenAttributesError{Ref: ref}
+				}
+
+				if entry.FetchedAt == rev && attr == entry.Attr.Attr && entry.Attr.ExpiresAt == 0 {
+					continue
+				}
+			}
+
+			nsAttrs = append(nsAttrs, attr)
+		}
+
+		updateAttrs[ns] = nsAttrs
+	}
+
+	unlock()
+
+	updatedRev, diffs, err := s.client.UpdateMany(ctx, ref, updateAttrs)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.updateState(updatedRev, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, diff := range diffs.Diffs {
+		cache, isExistingNamespace := s.cache[diff.Namespace]
+
+		for _, attr := range diff.VList {
+			entry := cache[attr.Key]
+			if updatedRev > entry.FetchedAt {
+				if cache == nil {
+					cache = attrNamespaceCache{}
+				}
+
+				cache[attr.Key] = cachedAttr{attr, updatedRev}
+			}
+		}
+
+		if !isExistingNamespace && cache != nil {
+			s.cache[diff.Namespace] = cache
+		}
+	}
+
+	return &revision{
+		s.id.At(s.highestRev),
+		s,
+	}, nil
+}
+
 func (s *session) TryClear(
 	ctx context.Context,
 	rev ident.Revision,
@@ -313,6 +484,53 @@ func (s *session) TryDestroy(
 	return nil
 }
 
+// Invalidate marks the cached attributes with the given keys in the ns
+// namespace as having been updated at rev.
+//
+// Subsequent reads at revisions before rev fail with a StaleFetchError
+// without contacting the owning peer, as they would if the owning peer were
+// asked, and reads at or after rev fetch the new values.
+//
+// Invalidations at or below a revision the session already holds are
+// ignored, as the cache already reflects them.
+func (s *session) Invalidate(rev ident.Revision, ns string, keys []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if rev <= s.highestRev {
+		return
+	}
+
+	s.updateState(rev, nil)
+
+	cache := s.cache[ns]
+
+	for _, key := range keys {
+		if entry, ok := cache[key]; ok && entry.FetchedAt < rev {
+			entry.Attr.UpdatedAt = rev
+			cache[key] = entry
+		}
+	}
+}
+
+// Move changes the ID of the session after it has been migrated to another
+// peer. The revisions and cached attributes remain valid, as migration
+// preserves them.
+func (s *session) Move(id ident.SessionID) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.id = id
+}
+
+// Close marks the session as destroyed.
+func (s *session) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.isClosed = true
+}
+
 func (s *session) fetchLocal(
 	rev ident.Revision,
 	ns string,
@@ -330,6 +548,7 @@ func (s *session) fetchLocal(
 	unsolved = make([]string, 0, count)
 
 	cache := s.cache[ns]
+	now := time.Now()
 
 	for _, key := range keys {
 		if entry, ok := cache[key]; ok {
@@ -346,6 +565,14 @@ func (s *session) fetchLocal(
 				return
 			}
 
+			// The attribute has expired, so the owning peer has cleared it, or
+			// is about to. The cached value can not be used, even if it was
+			// valid at the requested revision.
+			if entry.Attr.IsExpiredAt(now) {
+				unsolved = append(unsolved, key)
+				continue
+			}
+
 			// The attribute has been frozen, so it can't have changed, or we
 			// already know the cache data is valid at or after the requested
 			// revision.
@@ -1,22 +1,54 @@
 package remotesession
 
 import (
+	"context"
 	"sync"
 	"time"
 
-	"github.com/jmalloc/twelf/src/twelf"
 	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/internal/command"
 	"github.com/rinq/rinq-go/src/internal/revisions"
 	"github.com/rinq/rinq-go/src/internal/service"
 	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/metrics"
 )
 
 // Store is a local cache of remote revisions.
 type Store interface {
 	revisions.Store
 	service.Service
+
+	// Dump fetches the current revision of a remote session, and all of its
+	// attributes, bypassing the cache.
+	Dump(ctx context.Context, id ident.SessionID) (ident.Revision, attributes.Catalog, error)
+
+	// Find searches all peers for sessions with attributes that match con,
+	// where ns is the default namespace of the constraint.
+	//
+	// Results are collected until limit sessions have been found, or the ctx
+	// deadline is met. A limit of zero means there is no limit.
+	Find(ctx context.Context, ns string, con constraint.Constraint, limit int) ([]ident.Ref, error)
+
+	// Moved informs all peers that sessions owned by this peer have been
+	// migrated to another peer. moves maps the original session IDs to the
+	// new IDs.
+	Moved(ctx context.Context, moves map[ident.SessionID]ident.SessionID) error
+
+	// sessionClient returns the client used to communicate with the peers
+	// that own remote sessions.
+	sessionClient() *client
+
+	// move updates the cache after a session has been migrated from one ID
+	// to another.
+	move(from, to ident.SessionID)
+
+	// invalidate applies a change pushed by the peer that owns a session to
+	// the cache. It returns false if the session is not cached.
+	invalidate(ref ident.Ref, ns string, keys []string, isDestroyed bool) bool
 }
 
 type store struct {
@@ -26,10 +58,12 @@ type store struct {
 	peerID   ident.PeerID
 	client   *client
 	interval time.Duration
-	logger   twelf.Logger
+	logger   logging.Logger
+	metrics  metrics.Recorder
 
-	mutex sync.Mutex
-	cache map[ident.SessionID]*cacheEntry
+	mutex   sync.Mutex
+	cache   map[ident.SessionID]*cacheEntry
+	aliases map[ident.SessionID]ident.SessionID // original ID -> migrated ID
 }
 
 // NewStore returns a new store for revisions of remote sessions.
@@ -37,15 +71,19 @@ func NewStore(
 	peerID ident.PeerID,
 	invoker command.Invoker,
 	pruneInterval time.Duration,
-	logger twelf.Logger,
+	defaultTimeout time.Duration,
+	logger logging.Logger,
 	tracer opentracing.Tracer,
+	recorder metrics.Recorder,
 ) Store {
 	s := &store{
 		peerID:   peerID,
-		client:   newClient(peerID, invoker, logger, tracer),
+		client:   newClient(peerID, invoker, defaultTimeout, logger, tracer),
 		interval: pruneInterval,
 		logger:   logger,
+		metrics:  recorder,
 		cache:    map[ident.SessionID]*cacheEntry{},
+		aliases:  map[ident.SessionID]ident.SessionID{},
 	}
 
 	s.sm = service.NewStateMachine(s.run, nil)
@@ -61,6 +99,98 @@ type cacheEntry struct {
 	Marked  bool
 }
 
+func (s *store) Dump(
+	ctx context.Context,
+	id ident.SessionID,
+) (ident.Revision, attributes.Catalog, error) {
+	return s.client.Dump(ctx, id)
+}
+
+func (s *store) Find(
+	ctx context.Context,
+	ns string,
+	con constraint.Constraint,
+	limit int,
+) ([]ident.Ref, error) {
+	return s.client.Find(ctx, ns, con, limit)
+}
+
+func (s *store) Moved(
+	ctx context.Context,
+	moves map[ident.SessionID]ident.SessionID,
+) error {
+	return s.client.Moved(ctx, moves)
+}
+
+func (s *store) move(from, to ident.SessionID) {
+	// the owning peer no longer pushes changes to watchers of the original
+	// session, so they are closed without a destroyed change, as described
+	// by rinq.Revision.Watch().
+	s.client.closeWatchers(from)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// aliases are retained for as long as the migrated session is cached, so
+	// that revisions of the original session obtained later are also
+	// redirected.
+	s.aliases[from] = to
+
+	// sessions that were migrated to from are now found at to.
+	for f, t := range s.aliases {
+		if t == from {
+			s.aliases[f] = to
+		}
+	}
+
+	entry, ok := s.cache[from]
+	if !ok {
+		return
+	}
+
+	delete(s.cache, from)
+	entry.Session.Move(to)
+
+	if _, ok := s.cache[to]; !ok {
+		s.cache[to] = entry
+	}
+
+	logCacheMove(s.logger, s.peerID, from, to)
+}
+
+func (s *store) sessionClient() *client {
+	return s.client
+}
+
+func (s *store) invalidate(
+	ref ident.Ref,
+	ns string,
+	keys []string,
+	isDestroyed bool,
+) bool {
+	s.mutex.Lock()
+	entry, ok := s.cache[ref.ID]
+	if ok && isDestroyed {
+		delete(s.cache, ref.ID)
+		s.removeAliases()
+		logCacheEvict(s.logger, s.peerID, ref.ID)
+	}
+	s.mutex.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	if isDestroyed {
+		entry.Session.Close()
+	} else {
+		entry.Session.Invalidate(ref.Rev, ns, keys)
+		logCacheInvalidate(s.logger, s.peerID, ref, ns, keys)
+	}
+
+	return true
+}
+
 func (s *store) GetRevision(ref ident.Ref) (rinq.Revision, error) {
 	sess := s.getSession(ref.ID)
 	return sess.At(ref.Rev), nil
@@ -70,12 +200,16 @@ func (s *store) getSession(id ident.SessionID) *session {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	if to, ok := s.aliases[id]; ok {
+		id = to
+	}
+
 	if entry, ok := s.cache[id]; ok {
 		entry.Marked = false
 		return entry.Session
 	}
 
-	sess := newSession(id, s.client)
+	sess := newSession(id, s.client, s.metrics)
 	s.cache[id] = &cacheEntry{sess, false}
 	logCacheAdd(s.logger, s.peerID, id)
 
@@ -105,9 +239,25 @@ func (s *store) prune() {
 		if entry.Marked {
 			delete(s.cache, id)
 			logCacheRemove(s.logger, s.peerID, id)
+
+			// the owning peer no longer needs to send invalidations for the
+			// session to this peer.
+			go s.client.forget(id)
 		} else {
 			entry.Marked = true
 			logCacheMark(s.logger, s.peerID, id)
 		}
 	}
+
+	s.removeAliases()
+}
+
+// removeAliases removes the aliases of migrated sessions that are no longer
+// cached. It must be called with the mutex held.
+func (s *store) removeAliases() {
+	for from, to := range s.aliases {
+		if _, ok := s.cache[to]; !ok {
+			delete(s.aliases, from)
+		}
+	}
 }
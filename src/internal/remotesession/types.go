@@ -1,8 +1,11 @@
 package remotesession
 
 import (
+	"errors"
+
 	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
 	"github.com/rinq/rinq-go/src/rinq/ident"
 )
 
@@ -15,24 +18,63 @@ const (
 	updateCommand  = "update"
 	clearCommand   = "clear"
 	destroyCommand = "destroy"
+	dumpCommand    = "dump"
+	watchCommand   = "watch"
+	unwatchCommand = "unwatch"
+	changedCommand = "changed" // sent by the owning peer to the watching peer
+
+	// updateManyCommand is distinct from updateCommand so that peers that do
+	// not support updates to several namespaces reject the request, rather
+	// than misinterpreting it as an empty update.
+	updateManyCommand = "update-many"
+
+	// invalidateCommand is sent by the owning peer to each peer that has
+	// fetched a session when that session is modified or destroyed.
+	invalidateCommand = "invalidate"
+
+	// forgetCommand is sent to the owning peer by a peer that no longer
+	// caches a session, so that it is sent no further invalidations.
+	forgetCommand = "forget"
+
+	// findCommand is multicast to all peers to search for sessions, each peer
+	// with matching sessions responds with a foundCommand.
+	findCommand  = "find"
+	foundCommand = "found"
+
+	// movedCommand is multicast to all peers by a peer that has migrated its
+	// sessions to another peer before stopping.
+	movedCommand = "moved"
 )
 
 type fetchRequest struct {
 	Seq       uint32   `json:"s"`
 	Namespace string   `json:"ns,omitempty"`
 	Keys      []string `json:"k,omitempty"`
+
+	// All requests every attribute in the namespace instead of specific keys,
+	// or every attribute in every namespace if Namespace is empty.
+	All bool `json:"all,omitempty"`
 }
 
 type fetchResponse struct {
 	Rev   ident.Revision   `json:"r"`
 	Attrs attributes.VList `json:"a,omitempty"`
+
+	// Catalog is present instead of Attrs when the request has All set.
+	Catalog attributes.Catalog `json:"c,omitempty"`
+
+	// SentAt is the owning peer's time when the response was sent, as a Unix
+	// time in nanoseconds. The receiving peer uses it to rebase the expiry
+	// times of the attributes onto its own clock, see rebase().
+	SentAt int64 `json:"t,omitempty"`
 }
 
 type updateRequest struct {
-	Seq       uint32          `json:"s"`
-	Rev       ident.Revision  `json:"r"`
-	Namespace string          `json:"ns"`
-	Attrs     attributes.List `json:"a,omitempty"` // omitted for "clear" command
+	Seq       uint32           `json:"s"`
+	Rev       ident.Revision   `json:"r"`
+	Namespace string           `json:"ns"`
+	Attrs     attributes.List  `json:"a,omitempty"` // omitted for "clear" command
+	Conds     []rinq.Condition `json:"c,omitempty"` // present for conditional updates only
 }
 
 type updateResponse struct {
@@ -40,33 +82,129 @@ type updateResponse struct {
 	CreatedRevs []ident.Revision `json:"cr,omitempty"`
 }
 
+type updateManyRequest struct {
+	Seq        uint32                     `json:"s"`
+	Rev        ident.Revision             `json:"r"`
+	Namespaces map[string]attributes.List `json:"nss"`
+}
+
+type updateManyResponse struct {
+	Rev ident.Revision `json:"r"`
+
+	// Diffs contains the attributes that were changed by the update, keyed by
+	// namespace. Attributes that already had the requested value are omitted.
+	Diffs map[string]attributes.VList `json:"d,omitempty"`
+
+	// SentAt is the owning peer's time when the response was sent, see
+	// fetchResponse.SentAt.
+	SentAt int64 `json:"t,omitempty"`
+}
+
 type destroyRequest struct {
 	Seq uint32         `json:"s"`
 	Rev ident.Revision `json:"r"`
 }
 
+type dumpRequest struct {
+	Seq uint32 `json:"s"`
+}
+
+type dumpResponse struct {
+	Rev   ident.Revision     `json:"r"`
+	Attrs attributes.Catalog `json:"a,omitempty"`
+
+	// SentAt is the owning peer's time when the response was sent, see
+	// fetchResponse.SentAt.
+	SentAt int64 `json:"t,omitempty"`
+}
+
+type watchRequest struct {
+	ID        uint64         `json:"id"`
+	Seq       uint32         `json:"s"`
+	Rev       ident.Revision `json:"r"`
+	Namespace string         `json:"ns"`
+	Keys      []string       `json:"k,omitempty"`
+}
+
+type unwatchRequest struct {
+	ID uint64 `json:"id"`
+}
+
+type changedRequest struct {
+	ID          uint64          `json:"id"`
+	Seq         uint32          `json:"s"`
+	Rev         ident.Revision  `json:"r"`
+	Namespace   string          `json:"ns,omitempty"`
+	Attrs       attributes.List `json:"a,omitempty"`
+	IsDestroyed bool            `json:"d,omitempty"`
+}
+
+type invalidateRequest struct {
+	Seq         uint32         `json:"s"`
+	Rev         ident.Revision `json:"r"`
+	Namespace   string         `json:"ns,omitempty"`
+	Keys        []string       `json:"k,omitempty"`
+	IsDestroyed bool           `json:"d,omitempty"`
+}
+
+type forgetRequest struct {
+	Seq uint32 `json:"s"`
+}
+
+type findRequest struct {
+	ID         uint64                `json:"id"`
+	Namespace  string                `json:"ns"`
+	Constraint constraint.Constraint `json:"c"`
+	Limit      int                   `json:"l,omitempty"`
+}
+
+type foundRequest struct {
+	ID       uint64         `json:"id"`
+	Sessions []foundSession `json:"ss"`
+}
+
+type foundSession struct {
+	Seq uint32         `json:"s"`
+	Rev ident.Revision `json:"r"`
+}
+
+type movedRequest struct {
+	Sessions []movedSession `json:"ss"`
+}
+
+type movedSession struct {
+	Seq uint32          `json:"s"`
+	To  ident.SessionID `json:"t"`
+}
+
 const (
 	notFoundFailure         = "not-found"
 	staleUpdateFailure      = "stale"
 	frozenAttributesFailure = "frozen"
+	conditionFailedFailure  = "condition-failed"
 )
 
 // errorToFailure returns the appropriate failure type based on the type of err.
 func errorToFailure(err error) error {
-	switch err.(type) {
+	switch e := err.(type) {
 	case rinq.NotFoundError:
 		return rinq.Failure{Type: notFoundFailure}
 	case rinq.StaleUpdateError:
 		return rinq.Failure{Type: staleUpdateFailure}
 	case rinq.FrozenAttributesError:
 		return rinq.Failure{Type: frozenAttributesFailure}
+	case rinq.ConditionFailedError:
+		// the message carries the key of the failed condition
+		return rinq.Failure{Type: conditionFailedFailure, Message: e.Condition.Key}
 	default:
 		return err
 	}
 }
 
 // failureToError returns the appropriate error based on the failure type of err.
-func failureToError(ref ident.Ref, err error) error {
+//
+// conds are the conditions of a conditional update, if any.
+func failureToError(ref ident.Ref, err error, conds ...rinq.Condition) error {
 	switch rinq.FailureType(err) {
 	case notFoundFailure:
 		return rinq.NotFoundError{ID: ref.ID}
@@ -74,6 +212,15 @@ func failureToError(ref ident.Ref, err error) error {
 		return rinq.StaleUpdateError{Ref: ref}
 	case frozenAttributesFailure:
 		return rinq.FrozenAttributesError{Ref: ref}
+	case conditionFailedFailure:
+		var f rinq.Failure
+		errors.As(err, &f)
+
+		for _, c := range conds {
+			if c.Key == f.Message {
+				return rinq.ConditionFailedError{Ref: ref, Condition: c}
+			}
+		}
 	}
 
 	return err
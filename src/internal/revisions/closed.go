@@ -30,10 +30,26 @@ func (r closed) GetMany(context.Context, string, ...string) (rinq.AttrTable, err
 	return nil, rinq.NotFoundError{ID: ident.SessionID(r)}
 }
 
+func (r closed) GetAll(context.Context, string) (rinq.AttrTable, error) {
+	return nil, rinq.NotFoundError{ID: ident.SessionID(r)}
+}
+
+func (r closed) Namespaces(context.Context) ([]string, error) {
+	return nil, rinq.NotFoundError{ID: ident.SessionID(r)}
+}
+
 func (r closed) Update(context.Context, string, ...rinq.Attr) (rinq.Revision, error) {
 	return r, rinq.NotFoundError{ID: ident.SessionID(r)}
 }
 
+func (r closed) UpdateIf(context.Context, string, []rinq.Condition, ...rinq.Attr) (rinq.Revision, error) {
+	return r, rinq.NotFoundError{ID: ident.SessionID(r)}
+}
+
+func (r closed) UpdateMany(context.Context, map[string][]rinq.Attr) (rinq.Revision, error) {
+	return r, rinq.NotFoundError{ID: ident.SessionID(r)}
+}
+
 func (r closed) Clear(context.Context, string) (rinq.Revision, error) {
 	return r, rinq.NotFoundError{ID: ident.SessionID(r)}
 }
@@ -41,3 +57,7 @@ func (r closed) Clear(context.Context, string) (rinq.Revision, error) {
 func (r closed) Destroy(context.Context) error {
 	return nil
 }
+
+func (r closed) Watch(context.Context, string, ...string) (<-chan rinq.Change, error) {
+	return nil, rinq.NotFoundError{ID: ident.SessionID(r)}
+}
@@ -10,6 +10,9 @@ type Service interface {
 	// Err returns the error that caused the Done() channel to close, if any.
 	Err() error
 
+	// Status returns the current stage of the service's lifecycle.
+	Status() Status
+
 	// Stop halts the service immediately.
 	Stop()
 
@@ -76,6 +76,24 @@ func (s *StateMachine) Err() error {
 	return s.err
 }
 
+// Status returns the current stage of the service's lifecycle.
+func (s *StateMachine) Status() Status {
+	select {
+	case <-s.Finalized:
+		return Stopped
+	default:
+	}
+
+	select {
+	case <-s.Forceful:
+		return Stopping
+	case <-s.Graceful:
+		return Stopping
+	default:
+		return Running
+	}
+}
+
 // Stop halts the service immediately.
 func (s *StateMachine) Stop() {
 	s.mutex.Lock()
@@ -0,0 +1,28 @@
+package service
+
+// Status describes the stage of a service's lifecycle.
+type Status int
+
+const (
+	// Running indicates that the service has not been asked to stop.
+	Running Status = iota
+
+	// Stopping indicates that the service has been asked to stop, but has not
+	// yet finished.
+	Stopping
+
+	// Stopped indicates that the service has finished, and its Done() channel
+	// is closed.
+	Stopped
+)
+
+func (s Status) String() string {
+	switch s {
+	case Running:
+		return "running"
+	case Stopping:
+		return "stopping"
+	default:
+		return "stopped"
+	}
+}
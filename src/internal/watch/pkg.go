@@ -0,0 +1,3 @@
+// Package watch delivers changes to session attributes to the channels
+// returned by Revision.Watch().
+package watch
@@ -0,0 +1,186 @@
+package watch
+
+import (
+	"context"
+	"sort"
+
+	"github.com/rinq/rinq-go/src/internal/attributes"
+	"github.com/rinq/rinq-go/src/internal/x/syncx"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// Watcher queues the changes to a single namespace of a session, and delivers
+// them to a channel.
+//
+// Changes are pushed to the watcher without blocking, and delivered in order
+// by Run().
+type Watcher struct {
+	ns   string
+	keys map[string]struct{} // nil if all keys are watched
+	out  chan rinq.Change
+
+	queue syncx.Queue
+}
+
+// New returns a watcher for changes to the given keys of the ns namespace. If
+// keys is empty, all keys are watched. If ns is empty, all namespaces are
+// watched.
+func New(ns string, keys []string) *Watcher {
+	w := &Watcher{
+		ns:  ns,
+		out: make(chan rinq.Change),
+	}
+
+	if len(keys) != 0 {
+		w.keys = make(map[string]struct{}, len(keys))
+		for _, k := range keys {
+			w.keys[k] = struct{}{}
+		}
+	}
+
+	return w
+}
+
+// C returns the channel to which changes are delivered.
+func (w *Watcher) C() <-chan rinq.Change {
+	return w.out
+}
+
+// Namespace returns the namespace being watched, or an empty string if all
+// namespaces are watched.
+func (w *Watcher) Namespace() string {
+	return w.ns
+}
+
+// Keys returns the keys being watched, or nil if all keys are watched.
+func (w *Watcher) Keys() []string {
+	if w.keys == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(w.keys))
+	for k := range w.keys {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Push queues c for delivery, if it affects the watched attributes.
+//
+// Changes that indicate the session has been destroyed are always delivered,
+// and close the watcher.
+func (w *Watcher) Push(c rinq.Change) {
+	if !c.IsDestroyed {
+		if w.ns != "" && c.Namespace != w.ns {
+			return
+		}
+
+		c.Attrs = w.filter(c.Attrs)
+		if len(c.Attrs) == 0 {
+			return
+		}
+	}
+
+	w.queue.Push(c)
+
+	if c.IsDestroyed {
+		w.queue.Close()
+	}
+}
+
+// Close stops the watcher once any queued changes have been delivered.
+func (w *Watcher) Close() {
+	w.queue.Close()
+}
+
+// Run delivers queued changes to C() until ctx is canceled, or the watcher is
+// closed and all queued changes have been delivered. C() is closed when Run
+// returns.
+func (w *Watcher) Run(ctx context.Context) {
+	defer close(w.out)
+
+	w.queue.Run(ctx, func(v interface{}) bool {
+		select {
+		case w.out <- v.(rinq.Change):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
+
+// filter returns the attributes in attrs that are being watched.
+func (w *Watcher) filter(attrs []rinq.Attr) []rinq.Attr {
+	if w.keys == nil {
+		return attrs
+	}
+
+	var r []rinq.Attr
+	for _, attr := range attrs {
+		if _, ok := w.keys[attr.Key]; ok {
+			r = append(r, attr)
+		}
+	}
+
+	return r
+}
+
+// FromDiff returns the change described by d, which was made to the session
+// with the given ID.
+func FromDiff(id ident.SessionID, d *attributes.Diff) rinq.Change {
+	return rinq.Change{
+		Ref:       id.At(d.Revision),
+		Namespace: d.Namespace,
+		Attrs:     sorted(d.VList),
+	}
+}
+
+// Since returns the change to the attributes in t that were updated after
+// rev, as of the session revision ref. ok is false if there are no such
+// attributes.
+func Since(ref ident.Ref, ns string, t attributes.VTable, rev ident.Revision) (c rinq.Change, ok bool) {
+	var attrs attributes.VList
+
+	for _, attr := range t {
+		if attr.UpdatedAt > rev {
+			attrs = append(attrs, attr)
+		}
+	}
+
+	if len(attrs) == 0 {
+		return rinq.Change{}, false
+	}
+
+	return rinq.Change{
+		Ref:       ref,
+		Namespace: ns,
+		Attrs:     sorted(attrs),
+	}, true
+}
+
+// Destroyed returns a change indicating that the session was destroyed at
+// ref.
+func Destroyed(ref ident.Ref) rinq.Change {
+	return rinq.Change{
+		Ref:         ref,
+		IsDestroyed: true,
+	}
+}
+
+// sorted returns the attributes in l, sorted by key.
+func sorted(l attributes.VList) []rinq.Attr {
+	attrs := make([]rinq.Attr, len(l))
+	for i, attr := range l {
+		attrs[i] = attr.Attr
+	}
+
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Key < attrs[j].Key
+	})
+
+	return attrs
+}
@@ -0,0 +1,181 @@
+package watch_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/internal/attributes"
+	. "github.com/rinq/rinq-go/src/internal/watch"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+var _ = Describe("Watcher", func() {
+	var (
+		ctx    context.Context
+		cancel func()
+		ref    ident.Ref
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		ref = ident.NewPeerID().Session(1).At(1)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	change := func(ns string, attrs ...rinq.Attr) rinq.Change {
+		return rinq.Change{Ref: ref, Namespace: ns, Attrs: attrs}
+	}
+
+	Describe("Push", func() {
+		It("delivers changes in order", func() {
+			w := New("ns", nil)
+			go w.Run(ctx)
+
+			w.Push(change("ns", rinq.Set("a", "1")))
+			w.Push(change("ns", rinq.Set("a", "2")))
+
+			Eventually(w.C()).Should(Receive(Equal(change("ns", rinq.Set("a", "1")))))
+			Eventually(w.C()).Should(Receive(Equal(change("ns", rinq.Set("a", "2")))))
+		})
+
+		It("does not block when changes are not being received", func() {
+			w := New("ns", nil)
+
+			for i := 0; i < 100; i++ {
+				w.Push(change("ns", rinq.Set("a", "1")))
+			}
+		})
+
+		It("ignores changes to other namespaces", func() {
+			w := New("ns", nil)
+			go w.Run(ctx)
+
+			w.Push(change("other", rinq.Set("a", "1")))
+
+			Consistently(w.C()).ShouldNot(Receive())
+		})
+
+		It("delivers changes to all namespaces if the namespace is empty", func() {
+			w := New("", nil)
+			go w.Run(ctx)
+
+			w.Push(change("ns", rinq.Set("a", "1")))
+			w.Push(change("other", rinq.Set("a", "2")))
+
+			Eventually(w.C()).Should(Receive(Equal(change("ns", rinq.Set("a", "1")))))
+			Eventually(w.C()).Should(Receive(Equal(change("other", rinq.Set("a", "2")))))
+		})
+
+		It("only delivers the watched keys", func() {
+			w := New("ns", []string{"b"})
+			go w.Run(ctx)
+
+			w.Push(change("ns", rinq.Set("a", "1")))
+			w.Push(change("ns", rinq.Set("a", "2"), rinq.Set("b", "3")))
+
+			Eventually(w.C()).Should(Receive(Equal(change("ns", rinq.Set("b", "3")))))
+			Consistently(w.C()).ShouldNot(Receive())
+		})
+
+		It("closes the channel after delivering a destroyed change", func() {
+			w := New("ns", []string{"b"})
+			go w.Run(ctx)
+
+			w.Push(change("ns", rinq.Set("b", "1")))
+			w.Push(Destroyed(ref))
+			w.Push(change("ns", rinq.Set("b", "2")))
+
+			Eventually(w.C()).Should(Receive(Equal(change("ns", rinq.Set("b", "1")))))
+			Eventually(w.C()).Should(Receive(Equal(Destroyed(ref))))
+			Eventually(w.C()).Should(BeClosed())
+		})
+	})
+
+	Describe("Close", func() {
+		It("closes the channel after any queued changes are delivered", func() {
+			w := New("ns", nil)
+			w.Push(change("ns", rinq.Set("a", "1")))
+			w.Close()
+
+			go w.Run(ctx)
+
+			Eventually(w.C()).Should(Receive())
+			Eventually(w.C()).Should(BeClosed())
+		})
+	})
+
+	Describe("Run", func() {
+		It("closes the channel when the context is canceled", func() {
+			w := New("ns", nil)
+			w.Push(change("ns", rinq.Set("a", "1")))
+
+			go w.Run(ctx)
+			cancel()
+
+			Eventually(func() bool {
+				_, ok := <-w.C()
+				return ok
+			}).Should(BeFalse())
+		})
+	})
+
+	Describe("Keys", func() {
+		It("returns the sorted keys", func() {
+			w := New("ns", []string{"b", "a"})
+			Expect(w.Keys()).To(Equal([]string{"a", "b"}))
+		})
+
+		It("returns nil if all keys are watched", func() {
+			w := New("ns", nil)
+			Expect(w.Keys()).To(BeNil())
+		})
+	})
+})
+
+var _ = Describe("FromDiff", func() {
+	It("returns a change with the attributes sorted by key", func() {
+		id := ident.NewPeerID().Session(1)
+		diff := attributes.NewDiff("ns", 3)
+		diff.Append(
+			attributes.VAttr{Attr: rinq.Set("b", "2")},
+			attributes.VAttr{Attr: rinq.Set("a", "1")},
+		)
+
+		Expect(FromDiff(id, diff)).To(Equal(rinq.Change{
+			Ref:       id.At(3),
+			Namespace: "ns",
+			Attrs:     []rinq.Attr{rinq.Set("a", "1"), rinq.Set("b", "2")},
+		}))
+	})
+})
+
+var _ = Describe("Since", func() {
+	ref := ident.NewPeerID().Session(1).At(3)
+	table := attributes.VTable{
+		"a": {Attr: rinq.Set("a", "1"), CreatedAt: 1, UpdatedAt: 1},
+		"c": {Attr: rinq.Set("c", "3"), CreatedAt: 1, UpdatedAt: 3},
+		"b": {Attr: rinq.Set("b", "2"), CreatedAt: 2, UpdatedAt: 2},
+	}
+
+	It("returns the attributes updated after the given revision", func() {
+		c, ok := Since(ref, "ns", table, 1)
+
+		Expect(ok).To(BeTrue())
+		Expect(c).To(Equal(rinq.Change{
+			Ref:       ref,
+			Namespace: "ns",
+			Attrs:     []rinq.Attr{rinq.Set("b", "2"), rinq.Set("c", "3")},
+		}))
+	})
+
+	It("returns false if no attributes have been updated", func() {
+		_, ok := Since(ref, "ns", table, 3)
+
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,92 @@
+package syncx
+
+import (
+	"context"
+	"sync"
+)
+
+// Queue is an unbounded queue of values that are delivered in order by a
+// single call to Run().
+//
+// Values are pushed to the queue without blocking, so that a slow consumer
+// never blocks the producer. The zero value is ready to use.
+type Queue struct {
+	mutex  sync.Mutex
+	values []interface{}
+	closed bool
+	ready  chan struct{}
+}
+
+// Push queues values for delivery. It has no effect if the queue is closed.
+func (q *Queue) Push(values ...interface{}) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if !q.closed {
+		q.values = append(q.values, values...)
+		q.signal()
+	}
+}
+
+// Close stops the queue once any queued values have been delivered.
+func (q *Queue) Close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.closed = true
+	q.signal()
+}
+
+// Run delivers queued values to send until ctx is canceled, or the queue is
+// closed and all queued values have been delivered.
+//
+// send must block until the value is delivered or ctx is canceled, and return
+// false in the latter case.
+func (q *Queue) Run(ctx context.Context, send func(interface{}) bool) {
+	for {
+		q.mutex.Lock()
+		values := q.values
+		closed := q.closed
+		ready := q.readyChan()
+		q.values = nil
+		q.mutex.Unlock()
+
+		for _, v := range values {
+			if !send(v) {
+				return
+			}
+		}
+
+		if closed && len(values) == 0 {
+			return
+		}
+
+		if len(values) != 0 {
+			continue
+		}
+
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// signal wakes Run(). It must be called with the mutex held.
+func (q *Queue) signal() {
+	select {
+	case q.readyChan() <- struct{}{}:
+	default:
+	}
+}
+
+// readyChan returns the channel used to wake Run(), creating it if necessary.
+// It must be called with the mutex held.
+func (q *Queue) readyChan() chan struct{} {
+	if q.ready == nil {
+		q.ready = make(chan struct{}, 1)
+	}
+
+	return q.ready
+}
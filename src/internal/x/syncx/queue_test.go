@@ -0,0 +1,89 @@
+package syncx_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rinq/rinq-go/src/internal/x/syncx"
+)
+
+var _ = Describe("Queue", func() {
+	var (
+		ctx    context.Context
+		cancel func()
+		queue  *Queue
+		out    chan interface{}
+		done   chan struct{}
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		queue = &Queue{}
+		out = make(chan interface{})
+		done = make(chan struct{})
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	run := func() {
+		go func() {
+			defer close(done)
+
+			queue.Run(ctx, func(v interface{}) bool {
+				select {
+				case out <- v:
+					return true
+				case <-ctx.Done():
+					return false
+				}
+			})
+		}()
+	}
+
+	It("delivers values in order", func() {
+		run()
+
+		queue.Push(1, 2)
+		queue.Push(3)
+
+		Eventually(out).Should(Receive(Equal(1)))
+		Eventually(out).Should(Receive(Equal(2)))
+		Eventually(out).Should(Receive(Equal(3)))
+	})
+
+	It("does not block when values are not being delivered", func() {
+		for i := 0; i < 100; i++ {
+			queue.Push(i)
+		}
+	})
+
+	It("stops once queued values are delivered after it is closed", func() {
+		queue.Push(1)
+		queue.Close()
+		run()
+
+		Eventually(out).Should(Receive(Equal(1)))
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("ignores values pushed after it is closed", func() {
+		queue.Close()
+		queue.Push(1)
+		run()
+
+		Eventually(done).Should(BeClosed())
+		Expect(out).ShouldNot(Receive())
+	})
+
+	It("stops when the context is canceled", func() {
+		run()
+
+		queue.Push(1)
+		cancel()
+
+		Eventually(done).Should(BeClosed())
+	})
+})
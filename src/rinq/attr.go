@@ -1,6 +1,8 @@
 package rinq
 
 import (
+	"time"
+
 	"github.com/rinq/rinq-go/src/internal/x/bufferpool"
 	"github.com/rinq/rinq-go/src/internal/x/repr"
 )
@@ -22,6 +24,20 @@ type Attr struct {
 	// IsFrozen is true if the attribute is "frozen" such that it can never be
 	// altered again (for a given session).
 	IsFrozen bool `json:"f,omitempty"`
+
+	// TTL is the time after which the attribute expires, measured from the
+	// time at which it is set by Revision.Update(), UpdateIf() or
+	// UpdateMany(). A zero value means the attribute does not expire.
+	//
+	// When an attribute expires, the owning peer clears its value in a new
+	// revision of the session, exactly as if it had been updated to the empty
+	// string. Updating the attribute again before it expires replaces its
+	// expiry, and updating it without a TTL means it no longer expires.
+	//
+	// TTL is ignored for frozen attributes and empty values. It is only used
+	// when updating attributes, it is always zero in attributes returned by
+	// Revision.Get() and similar methods.
+	TTL time.Duration `json:"t,omitempty"`
 }
 
 // Set is a convenience method that creates an Attr with the specified key and
@@ -36,6 +52,24 @@ func Freeze(key, value string) Attr {
 	return Attr{Key: key, Value: value, IsFrozen: true}
 }
 
+// WithTTL returns a copy of attr that expires once d has elapsed after it is
+// set. See Attr.TTL.
+//
+// It panics if d is negative, or if attr is frozen.
+func (attr Attr) WithTTL(d time.Duration) Attr {
+	if d < 0 {
+		panic("TTL must not be negative")
+	}
+
+	if attr.IsFrozen {
+		panic("frozen attributes can not expire")
+	}
+
+	attr.TTL = d
+
+	return attr
+}
+
 func (attr Attr) String() string {
 	buf := bufferpool.Get()
 	defer bufferpool.Put(buf)
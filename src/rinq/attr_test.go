@@ -1,6 +1,8 @@
 package rinq_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/rinq/rinq-go/src/rinq"
@@ -50,3 +52,23 @@ var _ = Describe("Freeze", func() {
 		Expect(attr).To(Equal(expected))
 	})
 })
+
+var _ = Describe("Attr.WithTTL", func() {
+	It("returns a copy of the attribute with the TTL set", func() {
+		attr := rinq.Set("foo", "bar").WithTTL(time.Second)
+		expected := rinq.Attr{Key: "foo", Value: "bar", TTL: time.Second}
+		Expect(attr).To(Equal(expected))
+	})
+
+	It("panics if the TTL is negative", func() {
+		Expect(func() {
+			rinq.Set("foo", "bar").WithTTL(-time.Second)
+		}).To(Panic())
+	})
+
+	It("panics if the attribute is frozen", func() {
+		Expect(func() {
+			rinq.Freeze("foo", "bar").WithTTL(time.Second)
+		}).To(Panic())
+	})
+})
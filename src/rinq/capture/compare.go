@@ -0,0 +1,139 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/codec"
+)
+
+// Compare returns a description of each difference between the result
+// captured in r and the result of re-issuing the same command, as given by in
+// and err. It returns nil if the results are equivalent.
+//
+// Payloads are compared by value, so payloads that use different codecs are
+// considered equal if they represent the same value. Error messages other than
+// failure messages are not compared, as they typically describe transient
+// conditions such as timeouts.
+func Compare(r Record, in *rinq.Payload, err error) []string {
+	var actual Record
+	actual.SetResult(in, err)
+
+	var diffs []string
+
+	switch {
+	case r.Failure != nil && actual.Failure != nil:
+		if r.Failure.Type != actual.Failure.Type {
+			diffs = append(diffs, fmt.Sprintf(
+				"failure type: expected %q, got %q",
+				r.Failure.Type,
+				actual.Failure.Type,
+			))
+		}
+
+		if r.Failure.Message != actual.Failure.Message {
+			diffs = append(diffs, fmt.Sprintf(
+				"failure message: expected %q, got %q",
+				r.Failure.Message,
+				actual.Failure.Message,
+			))
+		}
+
+		if !equalPayloads(r.Failure.Payload, actual.Failure.Payload) {
+			diffs = append(diffs, fmt.Sprintf(
+				"failure payload: expected %s, got %s",
+				r.Failure.Payload,
+				actual.Failure.Payload,
+			))
+		}
+
+	case r.Error != "" && actual.Error != "":
+		// both results are errors, the messages are not compared
+
+	case r.Failure == nil && r.Error == "" && actual.Failure == nil && actual.Error == "":
+		if !equalPayloads(r.Response, actual.Response) {
+			diffs = append(diffs, fmt.Sprintf(
+				"response: expected %s, got %s",
+				r.Response,
+				actual.Response,
+			))
+		}
+
+	default:
+		diffs = append(diffs, fmt.Sprintf(
+			"outcome: expected %s, got %s",
+			r.outcome(),
+			actual.outcome(),
+		))
+	}
+
+	return diffs
+}
+
+// outcome returns a short description of the result captured in r.
+func (r *Record) outcome() string {
+	if r.Failure != nil {
+		return fmt.Sprintf("'%s' failure", r.Failure.Type)
+	}
+
+	if r.Error != "" {
+		return fmt.Sprintf("error (%s)", r.Error)
+	}
+
+	return "success"
+}
+
+// String returns a JSON representation of the payload's value, if possible,
+// otherwise it describes the payload's content-type and size.
+func (p *Payload) String() string {
+	if p == nil {
+		return "null"
+	}
+
+	if buf, ok := p.json(); ok {
+		return string(buf)
+	}
+
+	return fmt.Sprintf("<%d bytes of %s>", len(p.Data), p.ContentType)
+}
+
+// json returns the JSON representation of the payload's value.
+func (p *Payload) json() ([]byte, bool) {
+	rp := p.Payload()
+	defer rp.Close()
+
+	t, err := rp.Transcode(codec.JSON)
+	if err != nil {
+		return nil, false
+	}
+	defer t.Close()
+
+	return bytes.TrimSpace(append([]byte(nil), t.Bytes()...)), true
+}
+
+// equalPayloads returns true if a and b represent the same value.
+func equalPayloads(a, b *Payload) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if a.ContentType == b.ContentType && bytes.Equal(a.Data, b.Data) {
+		return true
+	}
+
+	ja, okA := a.json()
+	jb, okB := b.json()
+	if !okA || !okB {
+		return false
+	}
+
+	var va, vb interface{}
+	if json.Unmarshal(ja, &va) != nil || json.Unmarshal(jb, &vb) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(va, vb)
+}
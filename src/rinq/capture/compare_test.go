@@ -0,0 +1,67 @@
+package capture_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/capture"
+	"github.com/rinq/rinq-go/src/rinq/codec"
+)
+
+var _ = Describe("Compare", func() {
+	var record capture.Record
+
+	BeforeEach(func() {
+		p := rinq.NewPayload(map[string]interface{}{"a": 1})
+		defer p.Close()
+
+		record = capture.Record{}
+		record.SetResult(p, nil)
+	})
+
+	It("returns nil when the responses are equal", func() {
+		p := rinq.NewPayload(map[string]interface{}{"a": 1})
+		defer p.Close()
+
+		Expect(capture.Compare(record, p, nil)).To(BeNil())
+	})
+
+	It("compares payloads that use different codecs by value", func() {
+		p := rinq.NewPayloadWithCodec(map[string]interface{}{"a": 1}, codec.JSON)
+		defer p.Close()
+
+		Expect(capture.Compare(record, p, nil)).To(BeNil())
+	})
+
+	It("reports differences between response payloads", func() {
+		p := rinq.NewPayload(map[string]interface{}{"a": 2})
+		defer p.Close()
+
+		Expect(capture.Compare(record, p, nil)).To(ConsistOf(
+			`response: expected {"a":1}, got {"a":2}`,
+		))
+	})
+
+	It("reports a change in outcome", func() {
+		Expect(capture.Compare(record, nil, rinq.NewFailure("type", "", nil))).To(ConsistOf(
+			"outcome: expected success, got 'type' failure",
+		))
+	})
+
+	It("reports differences between failures", func() {
+		record.SetResult(nil, rinq.NewFailure("type-a", "message-a", nil))
+
+		Expect(capture.Compare(record, nil, rinq.NewFailure("type-b", "message-b", nil))).To(ConsistOf(
+			`failure type: expected "type-a", got "type-b"`,
+			`failure message: expected "message-a", got "message-b"`,
+		))
+	})
+
+	It("does not compare error messages", func() {
+		record.SetResult(nil, errors.New("<error a>"))
+
+		Expect(capture.Compare(record, nil, errors.New("<error b>"))).To(BeNil())
+	})
+})
@@ -0,0 +1,9 @@
+// Package capture records the command and notification traffic seen by a peer
+// so that it can be inspected or replayed later.
+//
+// Capture is enabled by passing a Recorder to the options.Capture() option.
+// Traffic is usually written to a file in the JSON Lines format using a Writer,
+// and read back using a Reader. The "rinq replay" command re-issues captured
+// calls against a network and reports any differences between the captured
+// and actual responses.
+package capture
@@ -0,0 +1,149 @@
+package capture
+
+import (
+	"time"
+
+	"github.com/rinq/rinq-go/src/rinq"
+)
+
+// Kind describes the type of traffic that a Record represents.
+type Kind string
+
+const (
+	// Call is a command request sent by the peer using Session.Call() or
+	// Session.CallBalanced(). The record includes the response.
+	Call Kind = "call"
+
+	// CallAsync is a command request sent by the peer using
+	// Session.CallAsync(). The response is delivered to the session's async
+	// handler and is not part of the record.
+	CallAsync Kind = "call-async"
+
+	// Execute is a command request sent by the peer using Session.Execute().
+	Execute Kind = "execute"
+
+	// ExecuteMany is a command request sent by the peer using
+	// Session.ExecuteMany().
+	ExecuteMany Kind = "execute-many"
+
+	// Request is a command request received and handled by the peer. The
+	// record includes the response produced by the handler.
+	Request Kind = "request"
+
+	// Notification is a notification received by the peer.
+	Notification Kind = "notification"
+)
+
+// Record describes a single command request or notification seen by a peer.
+type Record struct {
+	// Kind is the type of traffic that the record represents.
+	Kind Kind `json:"kind"`
+
+	// MessageID is the ID of the command request or notification message.
+	MessageID string `json:"message_id"`
+
+	// TraceID is the trace ID of the operation, if known.
+	TraceID string `json:"trace_id,omitempty"`
+
+	// Metadata is the application-defined metadata sent with the message.
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Namespace is the namespace of the command or notification.
+	Namespace string `json:"namespace"`
+
+	// Command is the command name. It is empty for notifications.
+	Command string `json:"command,omitempty"`
+
+	// Type is the notification type. It is empty for commands.
+	Type string `json:"type,omitempty"`
+
+	// Target is the ID of the peer that a unicast call was sent to, or the ID
+	// of the local session that received a unicast notification.
+	Target string `json:"target,omitempty"`
+
+	// Constraint is the constraint used to select the recipients of a
+	// multicast notification.
+	Constraint string `json:"constraint,omitempty"`
+
+	// Request is the command request or notification payload.
+	Request *Payload `json:"request,omitempty"`
+
+	// Response is the command response payload. It is nil if the command
+	// produced no response, or its response was not successful.
+	Response *Payload `json:"response,omitempty"`
+
+	// Failure is the failure produced by the command, if any.
+	Failure *Failure `json:"failure,omitempty"`
+
+	// Error is the error message of any other error produced by the command,
+	// or the error that occurred while sending the request.
+	Error string `json:"error,omitempty"`
+
+	// StartedAt is the time at which the message was sent or received.
+	StartedAt time.Time `json:"started_at"`
+
+	// Duration is the time taken to obtain the response to a command. It is
+	// zero for records that do not include a response.
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// SetResult populates the Response, Failure and Error fields of r from the
+// result of a command.
+func (r *Record) SetResult(in *rinq.Payload, err error) {
+	r.Response = nil
+	r.Failure = nil
+	r.Error = ""
+
+	if err == nil {
+		r.Response = NewPayload(in)
+	} else if f, ok := rinq.FailureFromError(err); ok {
+		defer f.Payload.Close()
+
+		r.Failure = &Failure{
+			Type:    f.Type,
+			Message: f.Message,
+			Payload: NewPayload(f.Payload),
+		}
+	} else {
+		r.Error = err.Error()
+	}
+}
+
+// Payload is the captured binary representation of a payload.
+type Payload struct {
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data"`
+}
+
+// NewPayload returns a copy of p that remains valid after p is closed. It
+// returns nil if p is nil or empty.
+func NewPayload(p *rinq.Payload) *Payload {
+	if p.Len() == 0 {
+		return nil
+	}
+
+	return &Payload{
+		ContentType: p.ContentType(),
+		Data:        append([]byte(nil), p.Bytes()...),
+	}
+}
+
+// Payload returns a new rinq.Payload containing the captured data. It returns
+// nil if p is nil.
+func (p *Payload) Payload() *rinq.Payload {
+	if p == nil {
+		return nil
+	}
+
+	return rinq.NewPayloadFromBytesWithContentType(
+		append([]byte(nil), p.Data...),
+		p.ContentType,
+	)
+}
+
+// Failure is a captured command failure.
+type Failure struct {
+	Type    string   `json:"type"`
+	Message string   `json:"message,omitempty"`
+	Payload *Payload `json:"payload,omitempty"`
+}
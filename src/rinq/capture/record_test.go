@@ -0,0 +1,78 @@
+package capture_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/capture"
+)
+
+var _ = Describe("Record", func() {
+	Describe("SetResult", func() {
+		It("captures successful responses", func() {
+			p := rinq.NewPayload(123)
+			defer p.Close()
+
+			var r capture.Record
+			r.SetResult(p, nil)
+
+			Expect(r.Response).To(Equal(capture.NewPayload(p)))
+			Expect(r.Failure).To(BeNil())
+			Expect(r.Error).To(BeEmpty())
+		})
+
+		It("captures failures", func() {
+			p := rinq.NewPayload(123)
+			defer p.Close()
+
+			var r capture.Record
+			r.SetResult(nil, rinq.NewFailure("type", "message", p))
+
+			Expect(r.Response).To(BeNil())
+			Expect(r.Failure).To(Equal(&capture.Failure{
+				Type:    "type",
+				Message: "message",
+				Payload: capture.NewPayload(p),
+			}))
+			Expect(r.Error).To(BeEmpty())
+		})
+
+		It("captures other errors", func() {
+			var r capture.Record
+			r.SetResult(nil, errors.New("<error>"))
+
+			Expect(r.Response).To(BeNil())
+			Expect(r.Failure).To(BeNil())
+			Expect(r.Error).To(Equal("<error>"))
+		})
+	})
+})
+
+var _ = Describe("Payload", func() {
+	It("remains valid after the original payload is closed", func() {
+		p := rinq.NewPayload(map[string]interface{}{"a": 1})
+		cp := capture.NewPayload(p)
+		p.Close()
+
+		rp := cp.Payload()
+		defer rp.Close()
+
+		Expect(rp.Value()).To(BeEquivalentTo(map[interface{}]interface{}{"a": uint64(1)}))
+	})
+
+	It("returns nil for nil payloads", func() {
+		Expect(capture.NewPayload(nil)).To(BeNil())
+
+		var cp *capture.Payload
+		Expect(cp.Payload()).To(BeNil())
+	})
+
+	It("renders the payload value as JSON", func() {
+		p := rinq.NewPayload([]int{1, 2})
+		defer p.Close()
+
+		Expect(capture.NewPayload(p).String()).To(Equal("[1,2]"))
+	})
+})
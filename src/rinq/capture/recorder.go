@@ -0,0 +1,87 @@
+package capture
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Recorder receives the records captured by a peer.
+//
+// Record may be called concurrently from multiple goroutines, and must not
+// block for long periods, as it is called from within the peer's message
+// processing paths.
+type Recorder interface {
+	Record(r Record)
+}
+
+// Discard is a Recorder that ignores all records. Peers do not build records
+// at all when configured to use Discard, which is the default.
+var Discard Recorder = discard{}
+
+type discard struct{}
+
+func (discard) Record(Record) {}
+
+// Func is a function that implements Recorder.
+type Func func(r Record)
+
+// Record calls fn(r).
+func (fn Func) Record(r Record) {
+	fn(r)
+}
+
+// Writer is a Recorder that writes records to an io.Writer in the JSON Lines
+// format, one record per line.
+type Writer struct {
+	mutex sync.Mutex
+	enc   *json.Encoder
+	err   error
+}
+
+// NewWriter returns a new Writer that writes records to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		enc: json.NewEncoder(w),
+	}
+}
+
+// Record writes r to the underlying writer.
+//
+// If a write fails, the error is available via Err() and all subsequent
+// records are discarded.
+func (w *Writer) Record(r Record) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.err == nil {
+		w.err = w.enc.Encode(r)
+	}
+}
+
+// Err returns the first error that occurred while writing a record, if any.
+func (w *Writer) Err() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.err
+}
+
+// Reader reads records written by a Writer.
+type Reader struct {
+	dec *json.Decoder
+}
+
+// NewReader returns a new Reader that reads records from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		dec: json.NewDecoder(r),
+	}
+}
+
+// Read returns the next record. It returns io.EOF when there are no more
+// records.
+func (r *Reader) Read() (rec Record, err error) {
+	err = r.dec.Decode(&rec)
+	return
+}
@@ -0,0 +1,78 @@
+package capture_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/capture"
+)
+
+var _ = Describe("Writer and Reader", func() {
+	It("round-trip records", func() {
+		p := rinq.NewPayload("hello")
+		defer p.Close()
+
+		records := []capture.Record{
+			{
+				Kind:      capture.Call,
+				MessageID: "58AEE146-191C.45@3#7",
+				TraceID:   "<trace>",
+				Metadata:  map[string]string{"key": "value"},
+				Namespace: "ns",
+				Command:   "cmd",
+				Request:   capture.NewPayload(p),
+				Response:  capture.NewPayload(p),
+				StartedAt: time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC),
+				Duration:  25 * time.Millisecond,
+			},
+			{
+				Kind:      capture.Notification,
+				MessageID: "58AEE146-191C.45@3#8",
+				Namespace: "ns",
+				Type:      "type",
+				StartedAt: time.Date(2017, 1, 2, 3, 4, 6, 0, time.UTC),
+			},
+		}
+
+		var buf bytes.Buffer
+		w := capture.NewWriter(&buf)
+
+		for _, r := range records {
+			w.Record(r)
+		}
+
+		Expect(w.Err()).ShouldNot(HaveOccurred())
+		Expect(bytes.Count(buf.Bytes(), []byte("\n"))).To(Equal(2))
+
+		r := capture.NewReader(&buf)
+
+		for _, expected := range records {
+			rec, err := r.Read()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(rec).To(Equal(expected))
+		}
+
+		_, err := r.Read()
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	It("discards records after a write error", func() {
+		w := capture.NewWriter(failingWriter{})
+
+		w.Record(capture.Record{})
+		w.Record(capture.Record{})
+
+		Expect(w.Err()).To(MatchError("<error>"))
+	})
+})
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("<error>")
+}
@@ -0,0 +1,54 @@
+package rinq
+
+import (
+	"github.com/rinq/rinq-go/src/internal/x/bufferpool"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// Change describes a modification to the attributes of a session, as
+// delivered by Revision.Watch().
+type Change struct {
+	// Ref refers to the session revision produced by the change.
+	Ref ident.Ref
+
+	// Namespace is the namespace that contains the changed attributes.
+	Namespace string
+
+	// Attrs contains the new state of each attribute that was changed, sorted
+	// by key.
+	Attrs []Attr
+
+	// IsDestroyed is true if the session has been destroyed. It is always the
+	// last change delivered for a session, and Attrs is empty.
+	IsDestroyed bool
+}
+
+// String returns a representation of the change, such as
+// "58AEE146-191C.45@3 ns::{a=1, b@2}".
+func (c Change) String() string {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+
+	buf.WriteString(c.Ref.String())
+
+	if c.IsDestroyed {
+		buf.WriteString(" destroyed")
+		return buf.String()
+	}
+
+	buf.WriteRune(' ')
+	buf.WriteString(c.Namespace)
+	buf.WriteString("::{")
+
+	for n, attr := range c.Attrs {
+		if n != 0 {
+			buf.WriteString(", ")
+		}
+
+		buf.WriteString(attr.String())
+	}
+
+	buf.WriteRune('}')
+
+	return buf.String()
+}
@@ -0,0 +1,45 @@
+package rinq_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+var _ = Describe("Change", func() {
+	var sessionRef = ident.Ref{
+		ID: ident.SessionID{
+			Peer: ident.PeerID{
+				Clock: 1,
+				Rand:  2,
+			},
+			Seq: 3,
+		},
+		Rev: 4,
+	}
+
+	Describe("String", func() {
+		It("includes the namespace and attributes", func() {
+			c := rinq.Change{
+				Ref:       sessionRef,
+				Namespace: "ns",
+				Attrs: []rinq.Attr{
+					rinq.Set("a", "1"),
+					rinq.Freeze("b", "2"),
+				},
+			}
+
+			Expect(c.String()).To(Equal("1-0002.3@4 ns::{a=1, b@2}"))
+		})
+
+		It("indicates when the session is destroyed", func() {
+			c := rinq.Change{
+				Ref:         sessionRef,
+				IsDestroyed: true,
+			}
+
+			Expect(c.String()).To(Equal("1-0002.3@4 destroyed"))
+		})
+	})
+})
@@ -0,0 +1,59 @@
+package codec
+
+import (
+	"io"
+	"sync"
+)
+
+// Codec encodes and decodes payload values.
+type Codec interface {
+	// ContentType returns the MIME type that identifies the encoding used by
+	// the codec. It is sent over the network along with the encoded payload.
+	ContentType() string
+
+	// Encode writes the binary representation of v to w.
+	Encode(w io.Writer, v interface{}) error
+
+	// Decode unpacks the binary representation in buf into v.
+	Decode(buf []byte, v interface{}) error
+}
+
+// Register makes c available for decoding payloads that are received with
+// c.ContentType() as their content-type.
+//
+// The built-in codecs are registered automatically. If a codec with the same
+// content-type is already registered it is replaced.
+func Register(c Codec) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	registry[c.ContentType()] = c
+}
+
+// Lookup returns the codec registered for the content-type ct.
+//
+// An empty content-type always refers to the CBOR codec, which allows payloads
+// to be received from peers that do not send a content-type.
+func Lookup(ct string) (Codec, bool) {
+	if ct == "" {
+		return CBOR, true
+	}
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	c, ok := registry[ct]
+	return c, ok
+}
+
+var (
+	mutex    sync.RWMutex
+	registry = map[string]Codec{}
+)
+
+func init() {
+	Register(CBOR)
+	Register(JSON)
+	Register(MessagePack)
+	Register(Protobuf)
+}
@@ -0,0 +1,110 @@
+package codec_test
+
+import (
+	"bytes"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq/codec"
+)
+
+var _ = Describe("Codec", func() {
+	DescribeTable(
+		"round-trips values",
+		func(c codec.Codec) {
+			var buf bytes.Buffer
+			err := c.Encode(&buf, map[string]interface{}{"foo": "bar"})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			var v map[string]string
+			err = c.Decode(buf.Bytes(), &v)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(v).To(Equal(map[string]string{"foo": "bar"}))
+		},
+		Entry("CBOR", codec.CBOR),
+		Entry("JSON", codec.JSON),
+		Entry("MessagePack", codec.MessagePack),
+	)
+
+	Describe("CBOR", func() {
+		It("decodes an empty buffer as nil", func() {
+			v := "<value>"
+			var p interface{} = &v
+
+			err := codec.CBOR.Decode(nil, &p)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(p).To(BeNil())
+		})
+	})
+
+	Describe("Protobuf", func() {
+		It("round-trips protocol buffers messages", func() {
+			var buf bytes.Buffer
+			err := codec.Protobuf.Encode(&buf, &wrappers.StringValue{Value: "<value>"})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			var v wrappers.StringValue
+			err = codec.Protobuf.Decode(buf.Bytes(), &v)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(v.Value).To(Equal("<value>"))
+		})
+
+		It("returns an error when encoding a value that is not a message", func() {
+			var buf bytes.Buffer
+			err := codec.Protobuf.Encode(&buf, "<value>")
+
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("returns an error when decoding into a value that is not a message", func() {
+			var v interface{}
+			err := codec.Protobuf.Decode([]byte{}, &v)
+
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Lookup", func() {
+	DescribeTable(
+		"returns the built-in codecs",
+		func(ct string, expected codec.Codec) {
+			c, ok := codec.Lookup(ct)
+
+			Expect(ok).To(BeTrue())
+			Expect(c).To(BeIdenticalTo(expected))
+		},
+		Entry("empty content-type", "", codec.CBOR),
+		Entry("CBOR", "application/cbor", codec.CBOR),
+		Entry("JSON", "application/json", codec.JSON),
+		Entry("MessagePack", "application/msgpack", codec.MessagePack),
+		Entry("Protobuf", "application/x-protobuf", codec.Protobuf),
+	)
+
+	It("returns false if there is no codec registered for the content-type", func() {
+		_, ok := codec.Lookup("<unknown>")
+
+		Expect(ok).To(BeFalse())
+	})
+
+	It("returns codecs added with Register()", func() {
+		c := &testCodec{}
+		codec.Register(c)
+
+		r, ok := codec.Lookup("<test>")
+
+		Expect(ok).To(BeTrue())
+		Expect(r).To(BeIdenticalTo(c))
+	})
+})
+
+type testCodec struct {
+	codec.Codec
+}
+
+func (*testCodec) ContentType() string {
+	return "<test>"
+}
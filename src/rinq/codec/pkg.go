@@ -0,0 +1,3 @@
+// Package codec defines the encodings that can be used to represent payload
+// values on the network.
+package codec
@@ -0,0 +1,44 @@
+package codec
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Protobuf is a codec that uses Protocol Buffers. It can only encode and
+// decode values that implement proto.Message.
+//
+// See https://developers.google.com/protocol-buffers/ for more information.
+var Protobuf Codec = protobufCodec{}
+
+type protobufCodec struct{}
+
+func (protobufCodec) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (protobufCodec) Encode(w io.Writer, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("can not encode %T, it does not implement proto.Message", v)
+	}
+
+	buf, err := proto.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(buf)
+	return err
+}
+
+func (protobufCodec) Decode(buf []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("can not decode into %T, it does not implement proto.Message", v)
+	}
+
+	return proto.Unmarshal(buf, m)
+}
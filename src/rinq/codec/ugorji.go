@@ -0,0 +1,98 @@
+package codec
+
+import (
+	"io"
+	"sync"
+
+	"github.com/rinq/rinq-go/src/internal/x/cbor"
+	"github.com/ugorji/go/codec"
+)
+
+var (
+	// CBOR is a codec that uses the Concise Binary Object Representation.
+	// It is the default codec used when constructing payloads.
+	//
+	// See http://cbor.io/ for more information.
+	CBOR Codec = cborCodec{}
+
+	// JSON is a codec that uses JavaScript Object Notation.
+	JSON Codec = newUgorjiCodec("application/json", &codec.JsonHandle{})
+
+	// MessagePack is a codec that uses the MessagePack binary format.
+	//
+	// See https://msgpack.org/ for more information.
+	MessagePack Codec = newUgorjiCodec("application/msgpack", newMsgpackHandle())
+)
+
+// cborCodec is the CBOR codec. It uses the internal CBOR package so that the
+// encoding is identical to that used for other Rinq data structures.
+type cborCodec struct{}
+
+func (cborCodec) ContentType() string {
+	return "application/cbor"
+}
+
+func (cborCodec) Encode(w io.Writer, v interface{}) error {
+	return cbor.Encode(w, v)
+}
+
+func (cborCodec) Decode(buf []byte, v interface{}) error {
+	if len(buf) == 0 {
+		buf = cbor.Nil
+	}
+
+	return cbor.DecodeBytes(buf, v)
+}
+
+// newMsgpackHandle returns a MessagePack handle that decodes strings as Go
+// strings, rather than byte-slices.
+func newMsgpackHandle() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{WriteExt: true}
+	h.RawToString = true
+
+	return h
+}
+
+// ugorjiCodec is a codec that uses one of the handles provided by the
+// github.com/ugorji/go/codec package.
+type ugorjiCodec struct {
+	contentType string
+	encoders    sync.Pool
+	decoders    sync.Pool
+}
+
+func newUgorjiCodec(ct string, h codec.Handle) *ugorjiCodec {
+	return &ugorjiCodec{
+		contentType: ct,
+		encoders: sync.Pool{
+			New: func() interface{} {
+				return codec.NewEncoder(nil, h)
+			},
+		},
+		decoders: sync.Pool{
+			New: func() interface{} {
+				return codec.NewDecoderBytes(nil, h)
+			},
+		},
+	}
+}
+
+func (c *ugorjiCodec) ContentType() string {
+	return c.contentType
+}
+
+func (c *ugorjiCodec) Encode(w io.Writer, v interface{}) error {
+	e := c.encoders.Get().(*codec.Encoder)
+	defer c.encoders.Put(e)
+
+	e.Reset(w)
+	return e.Encode(v)
+}
+
+func (c *ugorjiCodec) Decode(buf []byte, v interface{}) error {
+	d := c.decoders.Get().(*codec.Decoder)
+	defer c.decoders.Put(d)
+
+	d.ResetBytes(buf)
+	return d.Decode(v)
+}
@@ -2,9 +2,11 @@ package rinq
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/metadata"
 )
 
 // CommandHandler is a callback-function invoked when a command request is
@@ -47,6 +49,12 @@ type Request struct {
 	// request is responsible for closing the payload, however there is no
 	// requirement that the payload be closed during the execution of the handler.
 	Payload *Payload
+
+	// Metadata contains the application-defined metadata that was attached
+	// to the sender's context. It is also available in the context passed to
+	// the handler, and is forwarded to any onward requests made using that
+	// context. See the metadata package for more information.
+	Metadata metadata.MD
 }
 
 // Response sends a reply to incoming command requests.
@@ -67,6 +75,10 @@ type Response interface {
 
 	// Error sends an error to the source session and closes the response.
 	//
+	// If err is (or wraps) a Failure, or an error of a Go type registered with
+	// RegisterFailureType(), the caller receives a Failure; otherwise it
+	// receives a CommandError.
+	//
 	// A panic occurs if the response has already been closed.
 	Error(error)
 
@@ -95,7 +107,8 @@ type Response interface {
 // should usually be handled by the caller.
 //
 // Failures can be produced in a command handler by calling Response.Fail() or
-// passing a Failure value to Response.Error().
+// passing a Failure value to Response.Error(). Errors of a Go type that has
+// been registered with RegisterFailureType() are also sent as failures.
 type Failure struct {
 	// Type is an application-defined string identifying the failure.
 	// They serve the same purpose as an error code. They should be concise
@@ -107,33 +120,55 @@ type Failure struct {
 
 	// Payload is an optional application-defined payload.
 	Payload *Payload
+
+	// cause is the application-defined error value that the failure
+	// represents, if its type has been registered with RegisterFailureType().
+	cause error
 }
 
 func (err Failure) Error() string {
 	return fmt.Sprintf("%s: %s", err.Type, err.Message)
 }
 
-// IsFailure returns true if err is a Failure.
+// Unwrap returns the application-defined error value that the failure
+// represents, or nil if no Go type is registered for the failure type.
+//
+// This allows errors.As() to be used to obtain a value of the registered type
+// from a failure returned by Session.Call().
+func (err Failure) Unwrap() error {
+	return err.cause
+}
+
+// Is returns true if target is a Failure with the same type as err.
+//
+// This allows errors.Is() to be used to check the failure type, for example
+// errors.Is(err, rinq.Failure{Type: "not-found"}).
+func (err Failure) Is(target error) bool {
+	f, ok := target.(Failure)
+	return ok && f.Type != "" && f.Type == err.Type
+}
+
+// IsFailure returns true if err is (or wraps) a Failure.
 func IsFailure(err error) bool {
-	_, ok := err.(Failure)
-	return ok
+	var f Failure
+	return errors.As(err, &f)
 }
 
-// IsFailureType returns true if err is a Failure with a type of t.
+// IsFailureType returns true if err is (or wraps) a Failure with a type of t.
 func IsFailureType(t string, err error) bool {
 	if t == "" {
 		panic("failure type is empty")
 	}
 
-	f, _ := err.(Failure)
-	return f.Type == t
+	var f Failure
+	return errors.As(err, &f) && f.Type == t
 }
 
 // FailureType returns the failure type of err; or an empty string if err is not
-// a Failure.
+// (and does not wrap) a Failure.
 func FailureType(err error) string {
-	f, ok := err.(Failure)
-	if ok && f.Type == "" {
+	var f Failure
+	if errors.As(err, &f) && f.Type == "" {
 		panic("failure type is empty")
 	}
 
@@ -143,12 +178,10 @@ func FailureType(err error) string {
 // IsCommandError returns true if err was sent in response to a command request,
 // as opposed to a local error that occurred when attempting to send the request.
 func IsCommandError(err error) bool {
-	switch err.(type) {
-	case Failure, CommandError:
-		return true
-	default:
-		return false
-	}
+	var f Failure
+	var e CommandError
+
+	return errors.As(err, &f) || errors.As(err, &e)
 }
 
 // CommandError is an error (as opposed to a Failure) sent in response to a
@@ -2,6 +2,7 @@ package rinq_test
 
 import (
 	"errors"
+	"fmt"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -23,6 +24,11 @@ var _ = Describe("IsFailure", func() {
 		Expect(r).To(BeTrue())
 	})
 
+	It("returns true for wrapped failures", func() {
+		r := rinq.IsFailure(fmt.Errorf("<context>: %w", rinq.Failure{}))
+		Expect(r).To(BeTrue())
+	})
+
 	It("returns false for other error types", func() {
 		r := rinq.IsFailure(errors.New(""))
 		Expect(r).To(BeFalse())
@@ -35,6 +41,11 @@ var _ = Describe("IsFailureType", func() {
 		Expect(r).To(BeTrue())
 	})
 
+	It("returns true for wrapped failures with the same type", func() {
+		r := rinq.IsFailureType("foo", fmt.Errorf("<context>: %w", rinq.Failure{Type: "foo"}))
+		Expect(r).To(BeTrue())
+	})
+
 	It("returns false for failures with a different type", func() {
 		r := rinq.IsFailureType("foo", rinq.Failure{Type: "bar"})
 		Expect(r).To(BeFalse())
@@ -59,6 +70,11 @@ var _ = Describe("FailureType", func() {
 		Expect(r).To(Equal("foo"))
 	})
 
+	It("returns the type of wrapped failures", func() {
+		r := rinq.FailureType(fmt.Errorf("<context>: %w", rinq.Failure{Type: "foo"}))
+		Expect(r).To(Equal("foo"))
+	})
+
 	It("returns empty string for other error types", func() {
 		r := rinq.FailureType(errors.New(""))
 		Expect(r).To(Equal(""))
@@ -83,6 +99,11 @@ var _ = Describe("IsCommandError", func() {
 		Expect(r).To(BeTrue())
 	})
 
+	It("returns true for wrapped command errors", func() {
+		r := rinq.IsCommandError(fmt.Errorf("<context>: %w", rinq.CommandError("")))
+		Expect(r).To(BeTrue())
+	})
+
 	It("returns false for other error types", func() {
 		r := rinq.IsCommandError(errors.New(""))
 		Expect(r).To(BeFalse())
@@ -0,0 +1,50 @@
+package rinq
+
+import (
+	"github.com/rinq/rinq-go/src/internal/x/bufferpool"
+	"github.com/rinq/rinq-go/src/internal/x/repr"
+)
+
+// Condition asserts the current value of an attribute, as used by
+// Revision.UpdateIf().
+type Condition struct {
+	// Key is the key of the attribute.
+	Key string `json:"k"`
+
+	// Value is the value the attribute must have. An empty value requires that
+	// the attribute is empty or does not exist.
+	Value string `json:"v,omitempty"`
+}
+
+// Expect is a convenience method that returns a Condition that requires the
+// attribute with the specified key to have the specified value.
+func Expect(key, value string) Condition {
+	return Condition{Key: key, Value: value}
+}
+
+// ExpectEmpty is a convenience method that returns a Condition that requires
+// the attribute with the specified key to be empty, or to not exist.
+func ExpectEmpty(key string) Condition {
+	return Condition{Key: key}
+}
+
+// IsSatisfiedBy returns true if attr has the expected value.
+func (c Condition) IsSatisfiedBy(attr Attr) bool {
+	return attr.Value == c.Value
+}
+
+func (c Condition) String() string {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+
+	buf.WriteString(repr.Escape(c.Key))
+
+	if c.Value == "" {
+		buf.WriteString(" is empty")
+	} else {
+		buf.WriteString("==")
+		buf.WriteString(repr.Escape(c.Value))
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,48 @@
+package rinq_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+)
+
+var _ = Describe("Condition", func() {
+	Describe("IsSatisfiedBy", func() {
+		It("returns true if the attribute has the expected value", func() {
+			c := rinq.Expect("foo", "bar")
+			Expect(c.IsSatisfiedBy(rinq.Set("foo", "bar"))).To(BeTrue())
+		})
+
+		It("returns false if the attribute has a different value", func() {
+			c := rinq.Expect("foo", "bar")
+			Expect(c.IsSatisfiedBy(rinq.Set("foo", "baz"))).To(BeFalse())
+		})
+
+		It("returns true for empty attributes when an empty value is expected", func() {
+			c := rinq.ExpectEmpty("foo")
+			Expect(c.IsSatisfiedBy(rinq.Set("foo", ""))).To(BeTrue())
+		})
+
+		It("returns false for non-empty attributes when an empty value is expected", func() {
+			c := rinq.ExpectEmpty("foo")
+			Expect(c.IsSatisfiedBy(rinq.Set("foo", "bar"))).To(BeFalse())
+		})
+	})
+
+	Describe("String", func() {
+		It("uses 'double equals' syntax", func() {
+			c := rinq.Expect("foo", "bar")
+			Expect(c.String()).To(Equal("foo==bar"))
+		})
+
+		It("describes conditions that expect an empty value", func() {
+			c := rinq.ExpectEmpty("foo")
+			Expect(c.String()).To(Equal("foo is empty"))
+		})
+
+		It("escapes conditions that contain certain characters", func() {
+			c := rinq.Expect("foo key", "bar value")
+			Expect(c.String()).To(Equal(`"foo key"=="bar value"`))
+		})
+	})
+})
@@ -0,0 +1,269 @@
+package constraint
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Parse parses the human-readable representation of a constraint, as produced
+// by Constraint.String().
+//
+// Braces around individual terms are optional, so "a=1, b=2" is equivalent
+// to "{a=1, b=2}". Keys and values that contain characters other than
+// letters, digits, underscores, periods and hyphens must be quoted as JSON
+// strings.
+func Parse(s string) (Constraint, error) {
+	p := &parser{in: s}
+
+	con, err := p.parseOr()
+	if err != nil {
+		return Constraint{}, err
+	}
+
+	p.skipSpace()
+	if !p.eof() {
+		return Constraint{}, p.errorf("unexpected '%c'", p.peek())
+	}
+
+	if err := con.Validate(); err != nil {
+		return Constraint{}, err
+	}
+
+	return con, nil
+}
+
+type parser struct {
+	in  string
+	pos int
+}
+
+// parseOr parses one or more terms separated by "|".
+func (p *parser) parseOr() (Constraint, error) {
+	var cons []Constraint
+
+	for {
+		con, err := p.parseAnd()
+		if err != nil {
+			return Constraint{}, err
+		}
+
+		cons = append(cons, con)
+
+		if !p.accept("|") {
+			break
+		}
+	}
+
+	if len(cons) == 1 {
+		return cons[0], nil
+	}
+
+	return Or(cons...), nil
+}
+
+// parseAnd parses one or more terms separated by ",".
+func (p *parser) parseAnd() (Constraint, error) {
+	var cons []Constraint
+
+	for {
+		con, err := p.parseUnary()
+		if err != nil {
+			return Constraint{}, err
+		}
+
+		cons = append(cons, con)
+
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if len(cons) == 1 {
+		return cons[0], nil
+	}
+
+	return And(cons...), nil
+}
+
+// parseUnary parses a single term.
+func (p *parser) parseUnary() (Constraint, error) {
+	p.skipSpace()
+
+	if p.eof() {
+		return Constraint{}, p.errorf("unexpected end of constraint")
+	}
+
+	switch p.peek() {
+	case '{':
+		p.pos++
+		con, err := p.parseOr()
+		if err != nil {
+			return Constraint{}, err
+		}
+
+		if !p.accept("}") {
+			return Constraint{}, p.errorf("expected '}'")
+		}
+
+		return con, nil
+
+	case '*':
+		p.pos++
+		return None, nil
+
+	case '!':
+		p.pos++
+
+		// "!key" is an emptiness check, whereas "! term" negates a term.
+		if p.eof() || strings.ContainsRune(" \t\r\n{!*", rune(p.peek())) {
+			con, err := p.parseUnary()
+			if err != nil {
+				return Constraint{}, err
+			}
+
+			return Not(con), nil
+		}
+
+		k, err := p.parseString()
+		if err != nil {
+			return Constraint{}, err
+		}
+
+		return Empty(k), nil
+	}
+
+	k, err := p.parseString()
+	if err != nil {
+		return Constraint{}, err
+	}
+
+	switch {
+	case p.acceptNoSpace("::"):
+		con, err := p.parseUnary()
+		if err != nil {
+			return Constraint{}, err
+		}
+
+		if con.Op == andOp {
+			return Within(k, con.Terms...), nil
+		}
+
+		return Within(k, con), nil
+
+	case p.accept("!="):
+		v, err := p.parseValue()
+		if err != nil {
+			return Constraint{}, err
+		}
+
+		return NotEqual(k, v), nil
+
+	case p.accept("="):
+		v, err := p.parseValue()
+		if err != nil {
+			return Constraint{}, err
+		}
+
+		return Equal(k, v), nil
+	}
+
+	return NotEmpty(k), nil
+}
+
+// parseValue parses the right-hand side of a comparison.
+func (p *parser) parseValue() (string, error) {
+	p.skipSpace()
+	return p.parseString()
+}
+
+// parseString parses a bare or JSON-quoted string.
+func (p *parser) parseString() (string, error) {
+	if p.eof() {
+		return "", p.errorf("unexpected end of constraint")
+	}
+
+	start := p.pos
+
+	if p.peek() == '"' {
+		p.pos++
+
+		for !p.eof() {
+			switch p.in[p.pos] {
+			case '\\':
+				p.pos += 2
+				continue
+			case '"':
+				p.pos++
+
+				var s string
+				if err := json.Unmarshal([]byte(p.in[start:p.pos]), &s); err != nil {
+					return "", p.errorf("invalid quoted string")
+				}
+
+				return s, nil
+			}
+
+			p.pos++
+		}
+
+		return "", p.errorf("unterminated quoted string")
+	}
+
+	for !p.eof() && isBare(p.peek()) {
+		p.pos++
+	}
+
+	if p.pos == start {
+		return "", p.errorf("unexpected '%c'", p.peek())
+	}
+
+	return p.in[start:p.pos], nil
+}
+
+// accept consumes tok if it appears next in the input, ignoring leading
+// whitespace.
+func (p *parser) accept(tok string) bool {
+	p.skipSpace()
+	return p.acceptNoSpace(tok)
+}
+
+// acceptNoSpace consumes tok if it appears next in the input.
+func (p *parser) acceptNoSpace(tok string) bool {
+	if strings.HasPrefix(p.in[p.pos:], tok) {
+		p.pos += len(tok)
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && strings.ContainsRune(" \t\r\n", rune(p.peek())) {
+		p.pos++
+	}
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.in)
+}
+
+func (p *parser) peek() byte {
+	return p.in[p.pos]
+}
+
+func (p *parser) errorf(f string, v ...interface{}) error {
+	return fmt.Errorf(
+		"can not parse constraint at offset %d: %s",
+		p.pos,
+		fmt.Sprintf(f, v...),
+	)
+}
+
+// isBare returns true if c can appear in an unquoted key or value.
+func isBare(c byte) bool {
+	return c >= 'a' && c <= 'z' ||
+		c >= 'A' && c <= 'Z' ||
+		c >= '0' && c <= '9' ||
+		c == '_' || c == '.' || c == '-'
+}
@@ -0,0 +1,141 @@
+package constraint_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
+)
+
+var _ = Describe("Parse", func() {
+	DescribeTable(
+		"parses the string representation of a constraint",
+		func(s string, expected constraint.Constraint) {
+			con, err := constraint.Parse(s)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(con).To(Equal(expected))
+		},
+
+		Entry("None", "{*}", constraint.None),
+		Entry("Equal", "{a=1}", constraint.Equal("a", "1")),
+		Entry("NotEqual", "{a!=1}", constraint.NotEqual("a", "1")),
+		Entry("Empty", "{!a}", constraint.Empty("a")),
+		Entry("NotEmpty", "{a}", constraint.NotEmpty("a")),
+		Entry(
+			"Not",
+			"{! a=1}",
+			constraint.Not(constraint.Equal("a", "1")),
+		),
+		Entry(
+			"Not with compound expression",
+			"{! {a=1, b=2}}",
+			constraint.Not(
+				constraint.And(
+					constraint.Equal("a", "1"),
+					constraint.Equal("b", "2"),
+				),
+			),
+		),
+		Entry(
+			"And",
+			"{a=1, b=2}",
+			constraint.And(
+				constraint.Equal("a", "1"),
+				constraint.Equal("b", "2"),
+			),
+		),
+		Entry(
+			"Or",
+			"{a=1|b=2}",
+			constraint.Or(
+				constraint.Equal("a", "1"),
+				constraint.Equal("b", "2"),
+			),
+		),
+		Entry(
+			"Within",
+			"ns::{a=1}",
+			constraint.Within("ns", constraint.Equal("a", "1")),
+		),
+		Entry(
+			"Within with multiple values",
+			"ns::{a=1, b=2}",
+			constraint.Within(
+				"ns",
+				constraint.Equal("a", "1"),
+				constraint.Equal("b", "2"),
+			),
+		),
+		Entry(
+			"nested compound expression",
+			"{a=1, {b=2|c=3}}",
+			constraint.And(
+				constraint.Equal("a", "1"),
+				constraint.Or(
+					constraint.Equal("b", "2"),
+					constraint.Equal("c", "3"),
+				),
+			),
+		),
+		Entry(
+			"quoted strings",
+			`{"a b"="c\"d"}`,
+			constraint.Equal("a b", `c"d`),
+		),
+		Entry(
+			"expression without braces",
+			"a=1, b!=2",
+			constraint.And(
+				constraint.Equal("a", "1"),
+				constraint.NotEqual("b", "2"),
+			),
+		),
+		Entry(
+			"expression with additional whitespace",
+			" { a = 1 | ! b } ",
+			constraint.Or(
+				constraint.Equal("a", "1"),
+				constraint.Not(constraint.NotEmpty("b")),
+			),
+		),
+	)
+
+	It("parses the output of Constraint.String()", func() {
+		con := constraint.Within(
+			"ns",
+			constraint.None,
+			constraint.Not(
+				constraint.And(
+					constraint.Equal("a", "1"),
+					constraint.NotEqual("b b", "2"),
+					constraint.Or(
+						constraint.Empty("c"),
+						constraint.NotEmpty("d"),
+					),
+				),
+			),
+		)
+
+		parsed, err := constraint.Parse(con.String())
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(parsed).To(Equal(con))
+	})
+
+	DescribeTable(
+		"returns an error if the string is invalid",
+		func(s string) {
+			_, err := constraint.Parse(s)
+
+			Expect(err).Should(HaveOccurred())
+		},
+
+		Entry("empty", ""),
+		Entry("unbalanced braces", "{a=1"),
+		Entry("trailing characters", "a=1}"),
+		Entry("missing value", "a="),
+		Entry("unterminated quoted string", `"a`),
+		Entry("invalid namespace", "_ns::{a=1}"),
+	)
+})
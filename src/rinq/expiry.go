@@ -0,0 +1,64 @@
+package rinq
+
+import "time"
+
+// Expiry describes when a session is destroyed automatically.
+//
+// Expired sessions are destroyed exactly as if Session.Destroy() had been
+// called; the Session.Done() channel is closed and any watchers of the
+// session's revisions are informed that it has been destroyed.
+type Expiry struct {
+	// TTL is the maximum lifetime of the session, measured from the time the
+	// expiry is applied. A zero value means the session has no maximum
+	// lifetime.
+	TTL time.Duration
+
+	// IdleTimeout is the maximum time that may pass without any activity on
+	// the session. Activity includes sending command requests and
+	// notifications, and updating or clearing attributes, whether locally or
+	// by a remote peer. A zero value means the session never becomes idle.
+	IdleTimeout time.Duration
+}
+
+// IsZero returns true if e does not cause the session to expire.
+func (e Expiry) IsZero() bool {
+	return e.TTL == 0 && e.IdleTimeout == 0
+}
+
+// SessionOption is an option that can be passed to Peer.Session().
+type SessionOption func(*Expiry)
+
+// TTL returns a session option that destroys the session once d has elapsed
+// since it was created.
+func TTL(d time.Duration) SessionOption {
+	if d < 0 {
+		panic("TTL must not be negative")
+	}
+
+	return func(e *Expiry) {
+		e.TTL = d
+	}
+}
+
+// IdleTimeout returns a session option that destroys the session once d has
+// elapsed without any activity on the session.
+func IdleTimeout(d time.Duration) SessionOption {
+	if d < 0 {
+		panic("idle timeout must not be negative")
+	}
+
+	return func(e *Expiry) {
+		e.IdleTimeout = d
+	}
+}
+
+// NewExpiry returns the expiry described by opts.
+func NewExpiry(opts ...SessionOption) Expiry {
+	var e Expiry
+
+	for _, opt := range opts {
+		opt(&e)
+	}
+
+	return e
+}
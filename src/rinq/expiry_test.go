@@ -0,0 +1,55 @@
+package rinq_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+)
+
+var _ = Describe("Expiry", func() {
+	Describe("NewExpiry", func() {
+		It("applies the options", func() {
+			e := rinq.NewExpiry(
+				rinq.TTL(1*time.Hour),
+				rinq.IdleTimeout(5*time.Minute),
+			)
+
+			Expect(e).To(Equal(rinq.Expiry{
+				TTL:         1 * time.Hour,
+				IdleTimeout: 5 * time.Minute,
+			}))
+		})
+	})
+
+	Describe("IsZero", func() {
+		It("returns true if there are no timeouts", func() {
+			Expect(rinq.Expiry{}.IsZero()).To(BeTrue())
+		})
+
+		It("returns false if there is a TTL", func() {
+			Expect(rinq.Expiry{TTL: 1}.IsZero()).To(BeFalse())
+		})
+
+		It("returns false if there is an idle timeout", func() {
+			Expect(rinq.Expiry{IdleTimeout: 1}.IsZero()).To(BeFalse())
+		})
+	})
+
+	Describe("TTL", func() {
+		It("panics if the duration is negative", func() {
+			Expect(func() {
+				rinq.TTL(-1)
+			}).To(Panic())
+		})
+	})
+
+	Describe("IdleTimeout", func() {
+		It("panics if the duration is negative", func() {
+			Expect(func() {
+				rinq.IdleTimeout(-1)
+			}).To(Panic())
+		})
+	})
+})
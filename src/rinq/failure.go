@@ -0,0 +1,141 @@
+package rinq
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// RegisterFailureType associates the failure type t with the Go type of the
+// error value proto.
+//
+// When a command handler passes an error of the registered Go type to
+// Response.Error(), it is sent to the caller as a Failure of type t. The
+// failure message is the result of the error's Error() method, and the
+// failure payload contains the error value itself.
+//
+// When a Failure of type t is received in response to a command, its payload
+// is decoded into a new value of the registered Go type, which is available
+// using errors.As(), or by calling Failure.Unwrap().
+//
+// The error type should be a struct (or pointer to a struct) that can be
+// represented using the payload's codec.
+//
+// It panics if t is empty, or if either t or the Go type is already registered.
+func RegisterFailureType(t string, proto error) {
+	if t == "" {
+		panic("failure type is empty")
+	}
+
+	if proto == nil {
+		panic("failure prototype must not be nil")
+	}
+
+	rt := reflect.TypeOf(proto)
+
+	failureTypes.mutex.Lock()
+	defer failureTypes.mutex.Unlock()
+
+	if _, ok := failureTypes.byName[t]; ok {
+		panic(fmt.Sprintf("failure type '%s' is already registered", t))
+	}
+
+	if _, ok := failureTypes.byType[rt]; ok {
+		panic(fmt.Sprintf("%s is already registered as a failure type", rt))
+	}
+
+	failureTypes.byName[t] = rt
+	failureTypes.byType[rt] = t
+}
+
+// NewFailure returns a new Failure with the given type, message and payload.
+//
+// If a Go type has been registered for t using RegisterFailureType(), the
+// payload is decoded into a value of that type, which is available via
+// Failure.Unwrap().
+func NewFailure(t, m string, p *Payload) Failure {
+	if t == "" {
+		panic("failure type is empty")
+	}
+
+	f := Failure{
+		Type:    t,
+		Message: m,
+		Payload: p,
+	}
+
+	failureTypes.mutex.RLock()
+	rt, ok := failureTypes.byName[t]
+	failureTypes.mutex.RUnlock()
+
+	if ok {
+		f.cause = decodeFailureCause(rt, p)
+	}
+
+	return f
+}
+
+// FailureFromError returns the Failure that represents err.
+//
+// If err is (or wraps) a Failure, that failure is returned. Otherwise, if err
+// is (or wraps) an error of a Go type that has been registered using
+// RegisterFailureType(), a new failure is constructed from that error value.
+// If err is not represented by a failure, ok is false.
+//
+// The caller owns the payload of the returned failure, and must close it when
+// it is no longer needed. If err is (or wraps) a Failure, the returned payload
+// is a clone of that failure's payload, so closing it does not affect err.
+func FailureFromError(err error) (f Failure, ok bool) {
+	if errors.As(err, &f) {
+		f.Payload = f.Payload.Clone()
+		return f, true
+	}
+
+	failureTypes.mutex.RLock()
+	defer failureTypes.mutex.RUnlock()
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if t, ok := failureTypes.byType[reflect.TypeOf(e)]; ok {
+			return Failure{
+				Type:    t,
+				Message: e.Error(),
+				Payload: NewPayload(e),
+				cause:   e,
+			}, true
+		}
+	}
+
+	return Failure{}, false
+}
+
+// decodeFailureCause returns a new value of type rt, populated from p.
+// It returns nil if p can not be decoded into rt.
+func decodeFailureCause(rt reflect.Type, p *Payload) error {
+	if rt.Kind() == reflect.Ptr {
+		v := reflect.New(rt.Elem())
+		if err := p.Decode(v.Interface()); err != nil {
+			return nil
+		}
+
+		err, _ := v.Interface().(error)
+		return err
+	}
+
+	v := reflect.New(rt)
+	if err := p.Decode(v.Interface()); err != nil {
+		return nil
+	}
+
+	err, _ := v.Elem().Interface().(error)
+	return err
+}
+
+var failureTypes = struct {
+	mutex  sync.RWMutex
+	byName map[string]reflect.Type
+	byType map[reflect.Type]string
+}{
+	byName: map[string]reflect.Type{},
+	byType: map[reflect.Type]string{},
+}
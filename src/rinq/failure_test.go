@@ -0,0 +1,158 @@
+package rinq_test
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+)
+
+type insufficientFunds struct {
+	Balance int
+}
+
+func (e insufficientFunds) Error() string {
+	return fmt.Sprintf("insufficient funds, balance is %d", e.Balance)
+}
+
+type accountLocked struct {
+	Reason string
+}
+
+func (e *accountLocked) Error() string {
+	return "account locked: " + e.Reason
+}
+
+func init() {
+	rinq.RegisterFailureType("insufficient-funds", insufficientFunds{})
+	rinq.RegisterFailureType("account-locked", &accountLocked{})
+}
+
+var _ = Describe("RegisterFailureType", func() {
+	It("panics if the failure type is empty", func() {
+		Expect(func() {
+			rinq.RegisterFailureType("", errors.New(""))
+		}).Should(Panic())
+	})
+
+	It("panics if the failure type is already registered", func() {
+		Expect(func() {
+			rinq.RegisterFailureType("insufficient-funds", errors.New(""))
+		}).Should(Panic())
+	})
+
+	It("panics if the Go type is already registered", func() {
+		Expect(func() {
+			rinq.RegisterFailureType("<type>", insufficientFunds{})
+		}).Should(Panic())
+	})
+})
+
+var _ = Describe("NewFailure", func() {
+	It("decodes the payload into the registered value type", func() {
+		p := rinq.NewPayload(insufficientFunds{Balance: 10})
+		defer p.Close()
+
+		f := rinq.NewFailure("insufficient-funds", "<message>", p)
+
+		var err insufficientFunds
+		Expect(errors.As(f, &err)).To(BeTrue())
+		Expect(err).To(Equal(insufficientFunds{Balance: 10}))
+		Expect(errors.Is(f, insufficientFunds{Balance: 10})).To(BeTrue())
+	})
+
+	It("decodes the payload into the registered pointer type", func() {
+		p := rinq.NewPayload(&accountLocked{Reason: "<reason>"})
+		defer p.Close()
+
+		f := rinq.NewFailure("account-locked", "<message>", p)
+
+		var err *accountLocked
+		Expect(errors.As(f, &err)).To(BeTrue())
+		Expect(err).To(Equal(&accountLocked{Reason: "<reason>"}))
+	})
+
+	It("does not unwrap to anything if the type is not registered", func() {
+		f := rinq.NewFailure("<type>", "<message>", nil)
+
+		Expect(f.Unwrap()).To(BeNil())
+		Expect(f).To(Equal(rinq.Failure{Type: "<type>", Message: "<message>"}))
+	})
+})
+
+var _ = Describe("FailureFromError", func() {
+	It("returns failures as-is", func() {
+		f, ok := rinq.FailureFromError(rinq.Failure{Type: "<type>"})
+
+		Expect(ok).To(BeTrue())
+		Expect(f).To(Equal(rinq.Failure{Type: "<type>"}))
+	})
+
+	It("returns a clone of the failure's payload", func() {
+		p := rinq.NewPayload(123)
+		defer p.Close()
+
+		f, ok := rinq.FailureFromError(rinq.Failure{Type: "<type>", Payload: p})
+		Expect(ok).To(BeTrue())
+
+		f.Payload.Close()
+
+		Expect(p.Bytes()).To(Equal([]byte{24, 123}))
+	})
+
+	It("returns wrapped failures", func() {
+		err := fmt.Errorf("<context>: %w", rinq.Failure{Type: "<type>"})
+		f, ok := rinq.FailureFromError(err)
+
+		Expect(ok).To(BeTrue())
+		Expect(f.Type).To(Equal("<type>"))
+	})
+
+	It("converts errors of a registered type", func() {
+		f, ok := rinq.FailureFromError(insufficientFunds{Balance: 10})
+		defer f.Payload.Close()
+
+		Expect(ok).To(BeTrue())
+		Expect(f.Type).To(Equal("insufficient-funds"))
+		Expect(f.Message).To(Equal("insufficient funds, balance is 10"))
+		Expect(f.Unwrap()).To(Equal(insufficientFunds{Balance: 10}))
+
+		var v insufficientFunds
+		err := f.Payload.Decode(&v)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(v).To(Equal(insufficientFunds{Balance: 10}))
+	})
+
+	It("converts wrapped errors of a registered type", func() {
+		err := fmt.Errorf("<context>: %w", &accountLocked{Reason: "<reason>"})
+		f, ok := rinq.FailureFromError(err)
+		defer f.Payload.Close()
+
+		Expect(ok).To(BeTrue())
+		Expect(f.Type).To(Equal("account-locked"))
+	})
+
+	It("returns false for other errors", func() {
+		_, ok := rinq.FailureFromError(errors.New("<error>"))
+
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("Failure", func() {
+	Describe("Is", func() {
+		It("returns true for failures of the same type", func() {
+			err := fmt.Errorf("<context>: %w", rinq.Failure{Type: "<type>", Message: "<message>"})
+
+			Expect(errors.Is(err, rinq.Failure{Type: "<type>"})).To(BeTrue())
+		})
+
+		It("returns false for failures of a different type", func() {
+			err := rinq.Failure{Type: "<type>"}
+
+			Expect(errors.Is(err, rinq.Failure{Type: "<other>"})).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,116 @@
+package rinq
+
+import "github.com/rinq/rinq-go/src/rinq/ident"
+
+// ServiceState describes the stage of the lifecycle of one of the internal
+// services that make up a peer.
+type ServiceState string
+
+const (
+	// ServiceRunning indicates that the service is operating normally.
+	ServiceRunning ServiceState = "running"
+
+	// ServiceStopping indicates that the service has been asked to stop, and
+	// is finishing any pending work.
+	ServiceStopping ServiceState = "stopping"
+
+	// ServiceStopped indicates that the service has stopped.
+	ServiceStopped ServiceState = "stopped"
+)
+
+// Names of the services reported in Health.Services.
+const (
+	InvokerService     = "invoker"
+	ServerService      = "server"
+	ListenerService    = "listener"
+	RemoteStoreService = "remote-store"
+)
+
+// ServiceHealth is a report of the state of one of the peer's internal
+// services.
+type ServiceHealth struct {
+	// State is the stage of the service's lifecycle.
+	State ServiceState `json:"state"`
+
+	// Error is the error that caused the service to stop, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// ChannelPoolHealth is a report of the availability of the pool of broker
+// channels shared by the peer's services.
+type ChannelPoolHealth struct {
+	// Idle is the number of channels in the pool that are ready to be used.
+	Idle int `json:"idle"`
+
+	// Capacity is the maximum number of idle channels retained by the pool.
+	Capacity int `json:"capacity"`
+}
+
+// Health is a point-in-time report of the health of a peer, as returned by
+// Peer.Health().
+type Health struct {
+	// PeerID is the ID of the peer that produced the report.
+	PeerID ident.PeerID `json:"-"`
+
+	// Connected is true if the peer's connection to the broker is open.
+	Connected bool `json:"connected"`
+
+	// Draining is true if the peer is stopping, either because Stop() or
+	// GracefulStop() has been called, or because an error has occurred.
+	Draining bool `json:"draining"`
+
+	// Services contains the state of each of the peer's internal services,
+	// keyed by the service names defined in this package, such as
+	// InvokerService.
+	Services map[string]ServiceHealth `json:"services"`
+
+	// ChannelPool describes the availability of broker channels.
+	ChannelPool ChannelPoolHealth `json:"channel_pool"`
+
+	// PendingCalls is the number of outgoing command requests that are
+	// awaiting a response.
+	PendingCalls int `json:"pending_calls"`
+
+	// InFlightCommands is the number of incoming command requests that are
+	// currently being handled.
+	InFlightCommands int `json:"in_flight_commands"`
+
+	// InFlightNotifications is the number of incoming notifications that are
+	// currently being handled.
+	InFlightNotifications int `json:"in_flight_notifications"`
+}
+
+// IsLive returns true if the peer is connected to the broker and none of its
+// services have stopped.
+//
+// A peer that is draining is still considered live, as it continues to
+// process pending work.
+func (h Health) IsLive() bool {
+	if !h.Connected {
+		return false
+	}
+
+	for _, s := range h.Services {
+		if s.State == ServiceStopped {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsReady returns true if the peer is live, is not draining and all of its
+// services are running, such that it is able to accept new work.
+func (h Health) IsReady() bool {
+	if !h.IsLive() || h.Draining {
+		return false
+	}
+
+	for _, s := range h.Services {
+		if s.State != ServiceRunning {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,72 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rinq/rinq-go/src/rinq"
+)
+
+// Reporter is an interface for obtaining a health report. It is implemented
+// by rinq.Peer.
+type Reporter interface {
+	Health() rinq.Health
+}
+
+// report is the JSON representation of a health report.
+type report struct {
+	PeerID string `json:"peer_id"`
+	Live   bool   `json:"live"`
+	Ready  bool   `json:"ready"`
+	rinq.Health
+}
+
+// NewHandler returns an HTTP handler that serves the health report produced
+// by r as JSON.
+//
+// It responds with "200 OK" if the peer is ready to accept new work, and
+// "503 Service Unavailable" otherwise, making it suitable for use as a
+// readiness probe.
+func NewHandler(r Reporter) http.Handler {
+	return handler{r, rinq.Health.IsReady}
+}
+
+// NewLivenessHandler returns an HTTP handler that serves the health report
+// produced by r as JSON.
+//
+// It responds with "200 OK" if the peer is live, and "503 Service Unavailable"
+// otherwise, making it suitable for use as a liveness probe. Unlike the
+// handler returned by NewHandler(), it continues to report success while the
+// peer is draining.
+func NewLivenessHandler(r Reporter) http.Handler {
+	return handler{r, rinq.Health.IsLive}
+}
+
+type handler struct {
+	reporter Reporter
+	healthy  func(rinq.Health) bool
+}
+
+func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	health := h.reporter.Health()
+
+	code := http.StatusOK
+	if !h.healthy(health) {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(report{
+		PeerID: health.PeerID.String(),
+		Live:   health.IsLive(),
+		Ready:  health.IsReady(),
+		Health: health,
+	})
+}
@@ -0,0 +1,114 @@
+package healthcheck_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/healthcheck"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+type reporter rinq.Health
+
+func (r reporter) Health() rinq.Health {
+	return rinq.Health(r)
+}
+
+var _ = Describe("handlers", func() {
+	var health rinq.Health
+
+	serve := func(h http.Handler, method string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, "/health", nil))
+		return w
+	}
+
+	BeforeEach(func() {
+		health = rinq.Health{
+			PeerID:    ident.PeerID{Clock: 1, Rand: 2},
+			Connected: true,
+			Services: map[string]rinq.ServiceHealth{
+				rinq.InvokerService: {State: rinq.ServiceRunning},
+				rinq.ServerService:  {State: rinq.ServiceRunning},
+			},
+			ChannelPool:  rinq.ChannelPoolHealth{Idle: 3, Capacity: 20},
+			PendingCalls: 4,
+		}
+	})
+
+	Describe("NewHandler", func() {
+		It("responds with 200 OK when the peer is ready", func() {
+			w := serve(healthcheck.NewHandler(reporter(health)), http.MethodGet)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Content-Type")).To(Equal("application/json"))
+		})
+
+		It("responds with 503 Service Unavailable when the peer is draining", func() {
+			health.Draining = true
+			w := serve(healthcheck.NewHandler(reporter(health)), http.MethodGet)
+
+			Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+		})
+
+		It("responds with 503 Service Unavailable when a service is stopping", func() {
+			health.Services[rinq.ServerService] = rinq.ServiceHealth{State: rinq.ServiceStopping}
+			w := serve(healthcheck.NewHandler(reporter(health)), http.MethodGet)
+
+			Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+		})
+
+		It("serves the report as JSON", func() {
+			w := serve(healthcheck.NewHandler(reporter(health)), http.MethodGet)
+
+			var body map[string]interface{}
+			err := json.Unmarshal(w.Body.Bytes(), &body)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(body).To(HaveKeyWithValue("peer_id", health.PeerID.String()))
+			Expect(body).To(HaveKeyWithValue("live", true))
+			Expect(body).To(HaveKeyWithValue("ready", true))
+			Expect(body).To(HaveKeyWithValue("connected", true))
+			Expect(body).To(HaveKeyWithValue("pending_calls", 4.0))
+			Expect(body).To(HaveKey("services"))
+			Expect(body).To(HaveKey("channel_pool"))
+		})
+
+		It("does not write a body for HEAD requests", func() {
+			w := serve(healthcheck.NewHandler(reporter(health)), http.MethodHead)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.Len()).To(Equal(0))
+		})
+	})
+
+	Describe("NewLivenessHandler", func() {
+		It("responds with 200 OK when the peer is draining", func() {
+			health.Draining = true
+			w := serve(healthcheck.NewLivenessHandler(reporter(health)), http.MethodGet)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+		})
+
+		It("responds with 503 Service Unavailable when the broker connection is closed", func() {
+			health.Connected = false
+			w := serve(healthcheck.NewLivenessHandler(reporter(health)), http.MethodGet)
+
+			Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+		})
+
+		It("responds with 503 Service Unavailable when a service has stopped", func() {
+			health.Services[rinq.InvokerService] = rinq.ServiceHealth{
+				State: rinq.ServiceStopped,
+				Error: "<error>",
+			}
+			w := serve(healthcheck.NewLivenessHandler(reporter(health)), http.MethodGet)
+
+			Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+		})
+	})
+})
@@ -0,0 +1,3 @@
+// Package healthcheck provides HTTP handlers that serve the health of a peer,
+// for use as liveness and readiness probes.
+package healthcheck
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"regexp"
+	"strconv"
 	"time"
 )
 
@@ -41,6 +43,31 @@ func NewPeerID() PeerID {
 	}
 }
 
+// ParsePeerID parses a string representation of a peer ID.
+func ParsePeerID(str string) (id PeerID, err error) {
+	matches := peerIDPattern.FindStringSubmatch(str)
+
+	if len(matches) != 0 {
+		// Read the clock component ...
+		var value uint64
+		value, err = strconv.ParseUint(matches[1], 16, 64)
+		if err != nil {
+			return
+		}
+		id.Clock = value
+
+		// Read the random component ...
+		value, err = strconv.ParseUint(matches[2], 16, 16)
+		if err != nil {
+			return
+		}
+		id.Rand = uint16(value)
+	}
+
+	err = id.Validate()
+	return
+}
+
 // Validate returns an error if the peer ID is not valid.
 //
 // Neither the Clock nor Rand component may be zero.
@@ -79,3 +106,11 @@ func (id PeerID) String() string {
 		id.Rand,
 	)
 }
+
+var peerIDPattern *regexp.Regexp
+
+func init() {
+	peerIDPattern = regexp.MustCompile(
+		`^(.+)\-(.+)$`,
+	)
+}
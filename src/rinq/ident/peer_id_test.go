@@ -16,6 +16,30 @@ var _ = Describe("PeerID", func() {
 		})
 	})
 
+	Describe("ParsePeerID", func() {
+		It("parses a human readable ID", func() {
+			id, err := ParsePeerID("123456789ABCDEF-0BAD")
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(id).To(Equal(PeerID{Clock: 0x0123456789abcdef, Rand: 0x0bad}))
+		})
+
+		DescribeTable(
+			"returns an error if the string is malformed",
+			func(id string) {
+				_, err := ParsePeerID(id)
+
+				Expect(err).Should(HaveOccurred())
+			},
+			Entry("malformed", "<malformed>"),
+			Entry("zero clock component", "0-1"),
+			Entry("zero random component", "1-0"),
+			Entry("invalid clock component", "x-1"),
+			Entry("invalid random component", "1-x"),
+			Entry("session ID", "1-1.1"),
+		)
+	})
+
 	DescribeTable(
 		"Validate",
 		func(subject PeerID, isValid bool) {
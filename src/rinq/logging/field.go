@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"time"
+
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// Field is a key/value pair attached to a log entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Keys used by the fields produced by this package.
+const (
+	PeerKey             = "peer"
+	SessionKey          = "session"
+	RevisionKey         = "revision"
+	MessageIDKey        = "message_id"
+	NamespaceKey        = "namespace"
+	CommandKey          = "command"
+	NotificationTypeKey = "notification_type"
+	TraceIDKey          = "trace_id"
+	DurationKey         = "duration"
+	InputSizeKey        = "input_size"
+	OutputSizeKey       = "output_size"
+	FailureTypeKey      = "failure_type"
+	ErrorKey            = "error"
+)
+
+// Any returns a field with an arbitrary key and value.
+func Any(k string, v interface{}) Field {
+	return Field{k, v}
+}
+
+// Peer returns a field containing a peer ID.
+func Peer(id ident.PeerID) Field {
+	return Field{PeerKey, id.String()}
+}
+
+// Session returns a field containing a session ID.
+func Session(id ident.SessionID) Field {
+	return Field{SessionKey, id.String()}
+}
+
+// Revision returns a field containing a session revision number.
+func Revision(rev ident.Revision) Field {
+	return Field{RevisionKey, uint32(rev)}
+}
+
+// MessageID returns a field containing a message ID.
+func MessageID(id ident.MessageID) Field {
+	return Field{MessageIDKey, id.String()}
+}
+
+// Namespace returns a field containing a namespace.
+func Namespace(ns string) Field {
+	return Field{NamespaceKey, ns}
+}
+
+// Command returns a field containing a command name.
+func Command(cmd string) Field {
+	return Field{CommandKey, cmd}
+}
+
+// NotificationType returns a field containing a notification type.
+func NotificationType(t string) Field {
+	return Field{NotificationTypeKey, t}
+}
+
+// TraceID returns a field containing a trace ID.
+func TraceID(id string) Field {
+	return Field{TraceIDKey, id}
+}
+
+// Duration returns a field containing the duration of an operation.
+func Duration(d time.Duration) Field {
+	return Field{DurationKey, d}
+}
+
+// InputSize returns a field containing the size of an incoming payload, in
+// bytes.
+func InputSize(n int) Field {
+	return Field{InputSizeKey, n}
+}
+
+// OutputSize returns a field containing the size of an outgoing payload, in
+// bytes.
+func OutputSize(n int) Field {
+	return Field{OutputSizeKey, n}
+}
+
+// FailureType returns a field containing the type of a rinq.Failure.
+func FailureType(t string) Field {
+	return Field{FailureTypeKey, t}
+}
+
+// Error returns a field containing an error. The error is stored as a string
+// so that it can be serialized by any logger.
+func Error(err error) Field {
+	if err == nil {
+		return Field{ErrorKey, ""}
+	}
+
+	return Field{ErrorKey, err.Error()}
+}
@@ -0,0 +1,18 @@
+package logging
+
+// Logger is an interface for writing structured log entries.
+//
+// Each entry consists of a set of key/value fields, and a human-readable
+// message described by a printf-style format string and arguments. The message
+// duplicates the information in the fields, so implementations that index the
+// fields may choose to render the message lazily, or not at all.
+type Logger interface {
+	// Log writes an informational entry.
+	Log(fields []Field, format string, args ...interface{})
+
+	// Debug writes a debug entry. It is a no-op unless IsDebug() is true.
+	Debug(fields []Field, format string, args ...interface{})
+
+	// IsDebug returns true if debug entries are written.
+	IsDebug() bool
+}
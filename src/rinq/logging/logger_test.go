@@ -0,0 +1,163 @@
+package logging_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	. "github.com/rinq/rinq-go/src/rinq/logging"
+)
+
+var _ = Describe("FromTwelf", func() {
+	var (
+		target  *twelfLogger
+		subject Logger
+	)
+
+	BeforeEach(func() {
+		target = &twelfLogger{}
+		subject = FromTwelf(target)
+	})
+
+	It("writes the formatted message", func() {
+		subject.Log(
+			[]Field{Namespace("ns")},
+			"message in '%s' namespace",
+			"ns",
+		)
+
+		Expect(target.messages).To(Equal([]string{"message in 'ns' namespace"}))
+	})
+
+	It("does not write debug messages unless debug is enabled", func() {
+		subject.Debug(nil, "debug message")
+
+		Expect(subject.IsDebug()).To(BeFalse())
+		Expect(target.messages).To(BeEmpty())
+	})
+})
+
+var _ = Describe("ToTwelf", func() {
+	It("writes messages to the structured logger", func() {
+		buffer := &bytes.Buffer{}
+		subject := ToTwelf(FromSlog(slog.New(
+			slog.NewTextHandler(buffer, &slog.HandlerOptions{
+				ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+					if a.Key == slog.TimeKey {
+						return slog.Attr{}
+					}
+					return a
+				},
+			}),
+		)))
+
+		subject.Log("message in '%s' namespace", "ns")
+
+		Expect(buffer.String()).To(Equal(
+			`level=INFO msg="message in 'ns' namespace"` + "\n",
+		))
+	})
+
+	It("returns the original logger when round-tripped", func() {
+		target := &twelfLogger{}
+
+		Expect(ToTwelf(FromTwelf(target))).To(BeIdenticalTo(target))
+	})
+})
+
+// twelfLogger is a twelf.Logger that captures messages in memory.
+type twelfLogger struct {
+	debug    bool
+	messages []string
+}
+
+func (l *twelfLogger) Log(f string, v ...interface{}) {
+	l.LogString(fmt.Sprintf(f, v...))
+}
+
+func (l *twelfLogger) LogString(s string) {
+	l.messages = append(l.messages, s)
+}
+
+func (l *twelfLogger) Debug(f string, v ...interface{}) {
+	if l.debug {
+		l.Log(f, v...)
+	}
+}
+
+func (l *twelfLogger) DebugString(s string) {
+	if l.debug {
+		l.LogString(s)
+	}
+}
+
+func (l *twelfLogger) IsDebug() bool {
+	return l.debug
+}
+
+var _ = Describe("FromSlog", func() {
+	var (
+		buffer  *bytes.Buffer
+		subject Logger
+	)
+
+	BeforeEach(func() {
+		buffer = &bytes.Buffer{}
+		subject = FromSlog(slog.New(
+			slog.NewTextHandler(buffer, &slog.HandlerOptions{
+				ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+					if a.Key == slog.TimeKey {
+						return slog.Attr{}
+					}
+					return a
+				},
+			}),
+		))
+	})
+
+	It("writes the formatted message and the fields as attributes", func() {
+		subject.Log(
+			[]Field{
+				Namespace("ns"),
+				Command("cmd"),
+				Duration(10 * time.Millisecond),
+				Error(errors.New("<error>")),
+			},
+			"called '%s::%s' command",
+			"ns",
+			"cmd",
+		)
+
+		Expect(buffer.String()).To(Equal(
+			`level=INFO msg="called 'ns::cmd' command" namespace=ns command=cmd duration=10ms error=<error>` + "\n",
+		))
+	})
+
+	It("does not write debug messages unless the debug level is enabled", func() {
+		subject.Debug(nil, "debug message")
+
+		Expect(subject.IsDebug()).To(BeFalse())
+		Expect(buffer.String()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Field constructors", func() {
+	It("uses the string representation of identifiers", func() {
+		peerID := ident.PeerID{Clock: 1, Rand: 2}
+		msgID := peerID.Session(3).At(4).Message(5)
+
+		Expect(Peer(peerID)).To(Equal(Field{PeerKey, peerID.String()}))
+		Expect(Session(msgID.Ref.ID)).To(Equal(Field{SessionKey, msgID.Ref.ID.String()}))
+		Expect(Revision(msgID.Ref.Rev)).To(Equal(Field{RevisionKey, uint32(4)}))
+		Expect(MessageID(msgID)).To(Equal(Field{MessageIDKey, msgID.String()}))
+	})
+
+	It("stores errors as strings", func() {
+		Expect(Error(errors.New("<error>"))).To(Equal(Field{ErrorKey, "<error>"}))
+	})
+})
@@ -0,0 +1,3 @@
+// Package logging defines a structured logger interface used by peers, along
+// with adapters for twelf and log/slog.
+package logging
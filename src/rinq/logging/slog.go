@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// FromSlog returns a Logger that writes entries to a log/slog logger.
+//
+// Informational entries are written at slog.LevelInfo, and debug entries at
+// slog.LevelDebug. Each field is written as a separate attribute.
+func FromSlog(l *slog.Logger) Logger {
+	return slogLogger{l}
+}
+
+type slogLogger struct {
+	target *slog.Logger
+}
+
+func (l slogLogger) Log(fields []Field, f string, v ...interface{}) {
+	l.write(slog.LevelInfo, fields, f, v)
+}
+
+func (l slogLogger) Debug(fields []Field, f string, v ...interface{}) {
+	l.write(slog.LevelDebug, fields, f, v)
+}
+
+func (l slogLogger) IsDebug() bool {
+	return l.target.Enabled(context.Background(), slog.LevelDebug)
+}
+
+func (l slogLogger) write(
+	level slog.Level,
+	fields []Field,
+	f string,
+	v []interface{},
+) {
+	ctx := context.Background()
+
+	if !l.target.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := make([]slog.Attr, len(fields))
+	for i, field := range fields {
+		attrs[i] = slog.Any(field.Key, field.Value)
+	}
+
+	l.target.LogAttrs(ctx, level, fmt.Sprintf(f, v...), attrs...)
+}
@@ -0,0 +1,63 @@
+package logging
+
+import "github.com/jmalloc/twelf/src/twelf"
+
+// FromTwelf returns a Logger that writes the human-readable message of each
+// entry to a twelf logger. Fields are discarded.
+func FromTwelf(l twelf.Logger) Logger {
+	if a, ok := l.(twelfAdapter); ok {
+		return a.target
+	}
+
+	return twelfLogger{l}
+}
+
+// ToTwelf returns a twelf logger that writes each message to l, without any
+// fields.
+func ToTwelf(l Logger) twelf.Logger {
+	if t, ok := l.(twelfLogger); ok {
+		return t.target
+	}
+
+	return twelfAdapter{l}
+}
+
+type twelfLogger struct {
+	target twelf.Logger
+}
+
+func (l twelfLogger) Log(_ []Field, f string, v ...interface{}) {
+	l.target.Log(f, v...)
+}
+
+func (l twelfLogger) Debug(_ []Field, f string, v ...interface{}) {
+	l.target.Debug(f, v...)
+}
+
+func (l twelfLogger) IsDebug() bool {
+	return l.target.IsDebug()
+}
+
+type twelfAdapter struct {
+	target Logger
+}
+
+func (l twelfAdapter) Log(f string, v ...interface{}) {
+	l.target.Log(nil, f, v...)
+}
+
+func (l twelfAdapter) LogString(s string) {
+	l.target.Log(nil, "%s", s)
+}
+
+func (l twelfAdapter) Debug(f string, v ...interface{}) {
+	l.target.Debug(nil, f, v...)
+}
+
+func (l twelfAdapter) DebugString(s string) {
+	l.target.Debug(nil, "%s", s)
+}
+
+func (l twelfAdapter) IsDebug() bool {
+	return l.target.IsDebug()
+}
@@ -0,0 +1,76 @@
+package metadata
+
+import "context"
+
+// MD is a set of application-defined metadata key/value pairs.
+//
+// An MD obtained from a context, Request or Notification is shared and must
+// not be modified. Use MD.Copy() to obtain a modifiable copy.
+type MD map[string]string
+
+// Pairs returns an MD built from a list of alternating keys and values.
+//
+// It panics if kv has an odd number of elements.
+func Pairs(kv ...string) MD {
+	if len(kv)%2 != 0 {
+		panic("metadata must be specified as key/value pairs")
+	}
+
+	md := MD{}
+	for i := 0; i < len(kv); i += 2 {
+		md[kv[i]] = kv[i+1]
+	}
+
+	return md
+}
+
+// Get returns the value associated with the key k, or an empty string if k
+// is not present.
+func (md MD) Get(k string) string {
+	return md[k]
+}
+
+// Copy returns a copy of md.
+func (md MD) Copy() MD {
+	c := make(MD, len(md))
+	for k, v := range md {
+		c[k] = v
+	}
+
+	return c
+}
+
+// With returns a new context derived from parent that includes md as its
+// metadata, replacing any existing metadata.
+//
+// Any operations (such as command calls, session notifications, etc) that use
+// the returned context send the metadata to the receiving peer. md must not be
+// modified after it is passed to With().
+func With(parent context.Context, md MD) context.Context {
+	return context.WithValue(parent, key, md)
+}
+
+// Append returns a new context derived from parent that includes the metadata
+// from parent, along with the key/value pairs in kv.
+//
+// It panics if kv has an odd number of elements.
+func Append(parent context.Context, kv ...string) context.Context {
+	add := Pairs(kv...)
+	md := Get(parent).Copy()
+
+	for k, v := range add {
+		md[k] = v
+	}
+
+	return With(parent, md)
+}
+
+// Get returns the metadata from ctx, or nil if none is present.
+func Get(ctx context.Context) MD {
+	md, _ := ctx.Value(key).(MD)
+	return md
+}
+
+type keyType struct{}
+
+var key keyType
@@ -0,0 +1,60 @@
+package metadata_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/rinq/rinq-go/src/rinq/metadata"
+)
+
+var _ = Describe("Pairs", func() {
+	It("returns metadata containing the key/value pairs", func() {
+		md := Pairs("<k1>", "<v1>", "<k2>", "<v2>")
+
+		Expect(md).To(Equal(MD{"<k1>": "<v1>", "<k2>": "<v2>"}))
+	})
+
+	It("panics if there are an odd number of elements", func() {
+		Expect(func() {
+			Pairs("<k1>")
+		}).Should(Panic())
+	})
+})
+
+var _ = Describe("With", func() {
+	It("adds the metadata", func() {
+		ctx := With(context.Background(), Pairs("<k>", "<v>"))
+
+		Expect(Get(ctx)).To(Equal(MD{"<k>": "<v>"}))
+	})
+
+	It("replaces existing metadata", func() {
+		parent := With(context.Background(), Pairs("<k1>", "<v1>"))
+		ctx := With(parent, Pairs("<k2>", "<v2>"))
+
+		Expect(Get(ctx)).To(Equal(MD{"<k2>": "<v2>"}))
+	})
+})
+
+var _ = Describe("Append", func() {
+	It("merges the key/value pairs with the existing metadata", func() {
+		parent := With(context.Background(), Pairs("<k1>", "<v1>", "<k2>", "<v2>"))
+		ctx := Append(parent, "<k2>", "<v2b>", "<k3>", "<v3>")
+
+		Expect(Get(ctx)).To(Equal(MD{"<k1>": "<v1>", "<k2>": "<v2b>", "<k3>": "<v3>"}))
+	})
+
+	It("does not modify the parent's metadata", func() {
+		parent := With(context.Background(), Pairs("<k1>", "<v1>"))
+		Append(parent, "<k2>", "<v2>")
+
+		Expect(Get(parent)).To(Equal(MD{"<k1>": "<v1>"}))
+	})
+})
+
+var _ = Describe("Get", func() {
+	It("returns nil when no metadata is present", func() {
+		Expect(Get(context.Background())).To(BeNil())
+	})
+})
@@ -0,0 +1,8 @@
+// Package metadata provides functions for attaching application-defined
+// key/value pairs to a context, such as a locale, tenant or authentication
+// token.
+//
+// Metadata is sent along with command requests and notifications, and is
+// made available to the handler. Any operations performed using the context
+// supplied to the handler automatically forward the metadata.
+package metadata
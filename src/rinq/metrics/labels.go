@@ -0,0 +1,64 @@
+package metrics
+
+import "github.com/rinq/rinq-go/src/rinq"
+
+// InvocationMode describes how a command request is sent.
+type InvocationMode string
+
+const (
+	// Call is a command request that blocks until a response is received.
+	Call InvocationMode = "call"
+
+	// CallAsync is a command request where the response is delivered to an
+	// asynchronous handler.
+	CallAsync InvocationMode = "call-async"
+
+	// Execute is a command request that does not expect a response.
+	Execute InvocationMode = "execute"
+)
+
+// NotificationMode describes how a notification is sent.
+type NotificationMode string
+
+const (
+	// Unicast is a notification sent to a single session.
+	Unicast NotificationMode = "unicast"
+
+	// Multicast is a notification sent to all sessions that match a
+	// constraint.
+	Multicast NotificationMode = "multicast"
+)
+
+// Outcome describes the result of a command.
+type Outcome string
+
+const (
+	// Success indicates that the command completed successfully.
+	Success Outcome = "success"
+
+	// Failure indicates that the command produced an application-defined
+	// failure.
+	Failure Outcome = "failure"
+
+	// Error indicates that the command produced an unexpected error.
+	Error Outcome = "error"
+
+	// NoResponse indicates that a command handler returned without
+	// responding to the request.
+	NoResponse Outcome = "none"
+)
+
+// OutcomeOf returns the outcome that corresponds to err, as returned by
+// rinq.Session.Call() or passed to rinq.Response.Error().
+func OutcomeOf(err error) Outcome {
+	if err == nil {
+		return Success
+	}
+
+	if f, ok := rinq.FailureFromError(err); ok {
+		f.Payload.Close()
+		return Failure
+	}
+
+	return Error
+}
@@ -0,0 +1,32 @@
+package metrics_test
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+	. "github.com/rinq/rinq-go/src/rinq/metrics"
+)
+
+var _ = Describe("OutcomeOf", func() {
+	It("returns Success for a nil error", func() {
+		Expect(OutcomeOf(nil)).To(Equal(Success))
+	})
+
+	It("returns Failure for a failure", func() {
+		err := rinq.Failure{Type: "<type>"}
+		Expect(OutcomeOf(err)).To(Equal(Failure))
+	})
+
+	It("returns Failure for a wrapped failure", func() {
+		err := fmt.Errorf("<context>: %w", rinq.Failure{Type: "<type>"})
+		Expect(OutcomeOf(err)).To(Equal(Failure))
+	})
+
+	It("returns Error for any other error", func() {
+		Expect(OutcomeOf(errors.New("<error>"))).To(Equal(Error))
+		Expect(OutcomeOf(rinq.CommandError("<error>"))).To(Equal(Error))
+	})
+})
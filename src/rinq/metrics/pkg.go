@@ -0,0 +1,3 @@
+// Package metrics defines an interface for recording metrics about the
+// operation of a peer.
+package metrics
@@ -0,0 +1,3 @@
+// Package prommetrics provides a metrics.Recorder that exposes peer metrics
+// to Prometheus.
+package prommetrics
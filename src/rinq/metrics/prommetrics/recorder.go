@@ -0,0 +1,369 @@
+package prommetrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rinq/rinq-go/src/rinq/metrics"
+)
+
+// Namespace is the Prometheus namespace used for all metric names.
+const Namespace = "rinq"
+
+var (
+	// durationBuckets are the histogram buckets used for latency metrics, in
+	// seconds.
+	durationBuckets = prometheus.DefBuckets
+
+	// sizeBuckets are the histogram buckets used for payload sizes, in bytes.
+	// They range from 64 bytes to 1 megabyte.
+	sizeBuckets = prometheus.ExponentialBuckets(64, 4, 8)
+)
+
+// Recorder is a metrics.Recorder that exposes metrics to Prometheus. It
+// implements prometheus.Collector, and must be registered with a Prometheus
+// registry in order for its metrics to be exported.
+type Recorder struct {
+	invokerRequests     *prometheus.CounterVec
+	invokerRequestSize  *prometheus.HistogramVec
+	invokerCallDuration *prometheus.HistogramVec
+	invokerResponseSize *prometheus.HistogramVec
+	invokerPending      prometheus.Gauge
+
+	serverRequests        *prometheus.CounterVec
+	serverRequestSize     *prometheus.HistogramVec
+	serverHandlerDuration *prometheus.HistogramVec
+	serverResponseSize    *prometheus.HistogramVec
+	serverInFlight        prometheus.Gauge
+
+	notifierNotifications    *prometheus.CounterVec
+	notifierNotificationSize *prometheus.HistogramVec
+
+	listenerNotifications    *prometheus.CounterVec
+	listenerNotificationSize *prometheus.HistogramVec
+	listenerHandlerDuration  *prometheus.HistogramVec
+
+	sessionCacheHits   prometheus.Counter
+	sessionCacheMisses prometheus.Counter
+
+	channelsAcquired *prometheus.CounterVec
+	channelsReleased *prometheus.CounterVec
+	channelsInUse    prometheus.Gauge
+}
+
+// NewRecorder returns a new Prometheus recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		invokerRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: "invoker",
+				Name:      "requests_total",
+				Help:      "The number of command requests sent.",
+			},
+			[]string{"namespace", "command", "mode"},
+		),
+		invokerRequestSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: "invoker",
+				Name:      "request_size_bytes",
+				Help:      "The size of the payloads of command requests sent.",
+				Buckets:   sizeBuckets,
+			},
+			[]string{"namespace", "command", "mode"},
+		),
+		invokerCallDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: "invoker",
+				Name:      "call_duration_seconds",
+				Help:      "The time taken to receive responses to synchronous calls.",
+				Buckets:   durationBuckets,
+			},
+			[]string{"namespace", "command", "outcome"},
+		),
+		invokerResponseSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: "invoker",
+				Name:      "response_size_bytes",
+				Help:      "The size of the payloads of responses to synchronous calls.",
+				Buckets:   sizeBuckets,
+			},
+			[]string{"namespace", "command", "outcome"},
+		),
+		invokerPending: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: "invoker",
+				Name:      "pending_calls",
+				Help:      "The number of synchronous calls awaiting a response.",
+			},
+		),
+
+		serverRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: "server",
+				Name:      "requests_total",
+				Help:      "The number of command requests received.",
+			},
+			[]string{"namespace", "command"},
+		),
+		serverRequestSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: "server",
+				Name:      "request_size_bytes",
+				Help:      "The size of the payloads of command requests received.",
+				Buckets:   sizeBuckets,
+			},
+			[]string{"namespace", "command"},
+		),
+		serverHandlerDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: "server",
+				Name:      "handler_duration_seconds",
+				Help:      "The time taken by command handlers.",
+				Buckets:   durationBuckets,
+			},
+			[]string{"namespace", "command", "outcome"},
+		),
+		serverResponseSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: "server",
+				Name:      "response_size_bytes",
+				Help:      "The size of the payloads of command responses sent.",
+				Buckets:   sizeBuckets,
+			},
+			[]string{"namespace", "command", "outcome"},
+		),
+		serverInFlight: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: "server",
+				Name:      "in_flight_requests",
+				Help:      "The number of command requests currently being handled.",
+			},
+		),
+
+		notifierNotifications: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: "notifier",
+				Name:      "notifications_total",
+				Help:      "The number of notifications sent.",
+			},
+			[]string{"namespace", "type", "mode"},
+		),
+		notifierNotificationSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: "notifier",
+				Name:      "notification_size_bytes",
+				Help:      "The size of the payloads of notifications sent.",
+				Buckets:   sizeBuckets,
+			},
+			[]string{"namespace", "type", "mode"},
+		),
+
+		listenerNotifications: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: "listener",
+				Name:      "notifications_total",
+				Help:      "The number of notifications received.",
+			},
+			[]string{"namespace", "type"},
+		),
+		listenerNotificationSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: "listener",
+				Name:      "notification_size_bytes",
+				Help:      "The size of the payloads of notifications received.",
+				Buckets:   sizeBuckets,
+			},
+			[]string{"namespace", "type"},
+		),
+		listenerHandlerDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: "listener",
+				Name:      "handler_duration_seconds",
+				Help:      "The time taken by notification handlers.",
+				Buckets:   durationBuckets,
+			},
+			[]string{"namespace", "type"},
+		),
+
+		sessionCacheHits: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: "session_cache",
+				Name:      "hits_total",
+				Help:      "The number of remote session attributes served from the local cache.",
+			},
+		),
+		sessionCacheMisses: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: "session_cache",
+				Name:      "misses_total",
+				Help:      "The number of remote session attributes fetched from the owning peer.",
+			},
+		),
+
+		channelsAcquired: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: "channel_pool",
+				Name:      "acquired_total",
+				Help:      "The number of broker channels acquired, by source.",
+			},
+			[]string{"source"},
+		),
+		channelsReleased: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: "channel_pool",
+				Name:      "released_total",
+				Help:      "The number of broker channels released, by result.",
+			},
+			[]string{"result"},
+		),
+		channelsInUse: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: "channel_pool",
+				Name:      "in_use",
+				Help:      "The number of broker channels currently in use.",
+			},
+		),
+	}
+}
+
+// collectors returns all of the collectors managed by r.
+func (r *Recorder) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		r.invokerRequests,
+		r.invokerRequestSize,
+		r.invokerCallDuration,
+		r.invokerResponseSize,
+		r.invokerPending,
+		r.serverRequests,
+		r.serverRequestSize,
+		r.serverHandlerDuration,
+		r.serverResponseSize,
+		r.serverInFlight,
+		r.notifierNotifications,
+		r.notifierNotificationSize,
+		r.listenerNotifications,
+		r.listenerNotificationSize,
+		r.listenerHandlerDuration,
+		r.sessionCacheHits,
+		r.sessionCacheMisses,
+		r.channelsAcquired,
+		r.channelsReleased,
+		r.channelsInUse,
+	}
+}
+
+// Describe sends the descriptors of all metrics collected by r to ch.
+func (r *Recorder) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range r.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect sends the current value of all metrics collected by r to ch.
+func (r *Recorder) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range r.collectors() {
+		c.Collect(ch)
+	}
+}
+
+// CommandSent records a command request sent by the invoker.
+func (r *Recorder) CommandSent(ns, cmd string, m metrics.InvocationMode, size int) {
+	r.invokerRequests.WithLabelValues(ns, cmd, string(m)).Inc()
+	r.invokerRequestSize.WithLabelValues(ns, cmd, string(m)).Observe(float64(size))
+}
+
+// CallCompleted records the outcome of a synchronous call.
+func (r *Recorder) CallCompleted(ns, cmd string, d time.Duration, o metrics.Outcome, size int) {
+	r.invokerCallDuration.WithLabelValues(ns, cmd, string(o)).Observe(d.Seconds())
+	r.invokerResponseSize.WithLabelValues(ns, cmd, string(o)).Observe(float64(size))
+}
+
+// SetPendingCalls records the number of synchronous calls that are awaiting
+// a response.
+func (r *Recorder) SetPendingCalls(n int) {
+	r.invokerPending.Set(float64(n))
+}
+
+// CommandReceived records a command request received by the server.
+func (r *Recorder) CommandReceived(ns, cmd string, size int) {
+	r.serverRequests.WithLabelValues(ns, cmd).Inc()
+	r.serverRequestSize.WithLabelValues(ns, cmd).Observe(float64(size))
+}
+
+// CommandHandled records the outcome of a command handler.
+func (r *Recorder) CommandHandled(ns, cmd string, d time.Duration, o metrics.Outcome, size int) {
+	r.serverHandlerDuration.WithLabelValues(ns, cmd, string(o)).Observe(d.Seconds())
+	r.serverResponseSize.WithLabelValues(ns, cmd, string(o)).Observe(float64(size))
+}
+
+// SetInFlightCommands records the number of command requests that are
+// currently being handled by the server.
+func (r *Recorder) SetInFlightCommands(n int) {
+	r.serverInFlight.Set(float64(n))
+}
+
+// NotificationSent records a notification sent by the notifier.
+func (r *Recorder) NotificationSent(ns, t string, m metrics.NotificationMode, size int) {
+	r.notifierNotifications.WithLabelValues(ns, t, string(m)).Inc()
+	r.notifierNotificationSize.WithLabelValues(ns, t, string(m)).Observe(float64(size))
+}
+
+// NotificationReceived records a notification received by the listener.
+func (r *Recorder) NotificationReceived(ns, t string, size int) {
+	r.listenerNotifications.WithLabelValues(ns, t).Inc()
+	r.listenerNotificationSize.WithLabelValues(ns, t).Observe(float64(size))
+}
+
+// NotificationHandled records the time taken by a notification handler.
+func (r *Recorder) NotificationHandled(ns, t string, d time.Duration) {
+	r.listenerHandlerDuration.WithLabelValues(ns, t).Observe(d.Seconds())
+}
+
+// SessionCacheLookup records the result of looking up the attributes of a
+// remote session in the local cache.
+func (r *Recorder) SessionCacheLookup(hits, misses int) {
+	r.sessionCacheHits.Add(float64(hits))
+	r.sessionCacheMisses.Add(float64(misses))
+}
+
+// ChannelAcquired records the acquisition of a broker channel.
+func (r *Recorder) ChannelAcquired(created bool) {
+	if created {
+		r.channelsAcquired.WithLabelValues("new").Inc()
+	} else {
+		r.channelsAcquired.WithLabelValues("pool").Inc()
+	}
+
+	r.channelsInUse.Inc()
+}
+
+// ChannelReleased records the release of a broker channel.
+func (r *Recorder) ChannelReleased(retained bool) {
+	if retained {
+		r.channelsReleased.WithLabelValues("pooled").Inc()
+	} else {
+		r.channelsReleased.WithLabelValues("closed").Inc()
+	}
+
+	r.channelsInUse.Dec()
+}
@@ -0,0 +1,117 @@
+package prommetrics_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rinq/rinq-go/src/rinq/metrics"
+	. "github.com/rinq/rinq-go/src/rinq/metrics/prommetrics"
+)
+
+var _ = Describe("Recorder", func() {
+	var (
+		registry *prometheus.Registry
+		subject  *Recorder
+	)
+
+	BeforeEach(func() {
+		registry = prometheus.NewRegistry()
+		subject = NewRecorder()
+		registry.MustRegister(subject)
+	})
+
+	It("records invoker metrics", func() {
+		subject.CommandSent("ns", "cmd", metrics.Call, 100)
+		subject.CommandSent("ns", "cmd", metrics.Execute, 100)
+		subject.CallCompleted("ns", "cmd", time.Second, metrics.Success, 200)
+		subject.SetPendingCalls(3)
+
+		n, err := testutil.GatherAndCount(
+			registry,
+			"rinq_invoker_requests_total",
+			"rinq_invoker_request_size_bytes",
+			"rinq_invoker_call_duration_seconds",
+			"rinq_invoker_response_size_bytes",
+			"rinq_invoker_pending_calls",
+		)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(n).To(Equal(7))
+	})
+
+	It("records server metrics", func() {
+		subject.CommandReceived("ns", "cmd", 100)
+		subject.CommandHandled("ns", "cmd", time.Second, metrics.Failure, 0)
+		subject.SetInFlightCommands(2)
+
+		n, err := testutil.GatherAndCount(
+			registry,
+			"rinq_server_requests_total",
+			"rinq_server_request_size_bytes",
+			"rinq_server_handler_duration_seconds",
+			"rinq_server_response_size_bytes",
+			"rinq_server_in_flight_requests",
+		)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(n).To(Equal(5))
+	})
+
+	It("records notification metrics", func() {
+		subject.NotificationSent("ns", "type", metrics.Multicast, 100)
+		subject.NotificationReceived("ns", "type", 100)
+		subject.NotificationHandled("ns", "type", time.Second)
+
+		n, err := testutil.GatherAndCount(
+			registry,
+			"rinq_notifier_notifications_total",
+			"rinq_listener_notifications_total",
+			"rinq_listener_handler_duration_seconds",
+		)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(n).To(Equal(3))
+	})
+
+	It("records session cache hits and misses", func() {
+		subject.SessionCacheLookup(3, 1)
+		subject.SessionCacheLookup(1, 0)
+
+		n, err := testutil.GatherAndCount(registry, "rinq_session_cache_hits_total")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(n).To(Equal(1))
+
+		families, err := registry.Gather()
+		Expect(err).ShouldNot(HaveOccurred())
+
+		values := map[string]float64{}
+		for _, f := range families {
+			for _, m := range f.GetMetric() {
+				if c := m.GetCounter(); c != nil {
+					values[f.GetName()] += c.GetValue()
+				}
+			}
+		}
+
+		Expect(values["rinq_session_cache_hits_total"]).To(Equal(4.0))
+		Expect(values["rinq_session_cache_misses_total"]).To(Equal(1.0))
+	})
+
+	It("tracks the number of channels in use", func() {
+		subject.ChannelAcquired(true)
+		subject.ChannelAcquired(false)
+		subject.ChannelReleased(true)
+
+		families, err := registry.Gather()
+		Expect(err).ShouldNot(HaveOccurred())
+
+		for _, f := range families {
+			if f.GetName() == "rinq_channel_pool_in_use" {
+				Expect(f.GetMetric()[0].GetGauge().GetValue()).To(Equal(1.0))
+				return
+			}
+		}
+
+		Fail("in-use gauge was not collected")
+	})
+})
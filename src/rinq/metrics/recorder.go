@@ -0,0 +1,98 @@
+package metrics
+
+import "time"
+
+// Recorder records metrics about the operation of a peer.
+//
+// Implementations must be safe for concurrent use.
+type Recorder interface {
+	// CommandSent records a command request sent by the invoker, where size is
+	// the size of the request payload, in bytes.
+	CommandSent(ns, cmd string, m InvocationMode, size int)
+
+	// CallCompleted records the outcome of a synchronous call, where d is the
+	// time taken to receive the response and size is the size of the response
+	// payload, in bytes.
+	CallCompleted(ns, cmd string, d time.Duration, o Outcome, size int)
+
+	// SetPendingCalls records the number of synchronous calls that are awaiting
+	// a response.
+	SetPendingCalls(n int)
+
+	// CommandReceived records a command request received by the server, where
+	// size is the size of the request payload, in bytes.
+	CommandReceived(ns, cmd string, size int)
+
+	// CommandHandled records the outcome of a command handler, where d is the
+	// time taken by the handler and size is the size of the response payload,
+	// in bytes.
+	CommandHandled(ns, cmd string, d time.Duration, o Outcome, size int)
+
+	// SetInFlightCommands records the number of command requests that are
+	// currently being handled by the server.
+	SetInFlightCommands(n int)
+
+	// NotificationSent records a notification sent by the notifier, where size
+	// is the size of the notification payload, in bytes.
+	NotificationSent(ns, t string, m NotificationMode, size int)
+
+	// NotificationReceived records a notification received by the listener,
+	// where size is the size of the notification payload, in bytes.
+	NotificationReceived(ns, t string, size int)
+
+	// NotificationHandled records the time taken by a notification handler.
+	NotificationHandled(ns, t string, d time.Duration)
+
+	// SessionCacheLookup records the result of looking up the attributes of a
+	// remote session in the local cache. hits is the number of attributes
+	// that were served from the cache, misses is the number that had to be
+	// fetched from the owning peer.
+	SessionCacheLookup(hits, misses int)
+
+	// ChannelAcquired records the acquisition of a broker channel. created is
+	// true if a new channel was opened, rather than reusing a pooled channel.
+	ChannelAcquired(created bool)
+
+	// ChannelReleased records the release of a broker channel. retained is
+	// true if the channel was returned to the pool, rather than being closed.
+	ChannelReleased(retained bool)
+}
+
+// NoOp is a Recorder that discards all metrics.
+type NoOp struct{}
+
+// CommandSent does nothing.
+func (NoOp) CommandSent(string, string, InvocationMode, int) {}
+
+// CallCompleted does nothing.
+func (NoOp) CallCompleted(string, string, time.Duration, Outcome, int) {}
+
+// SetPendingCalls does nothing.
+func (NoOp) SetPendingCalls(int) {}
+
+// CommandReceived does nothing.
+func (NoOp) CommandReceived(string, string, int) {}
+
+// CommandHandled does nothing.
+func (NoOp) CommandHandled(string, string, time.Duration, Outcome, int) {}
+
+// SetInFlightCommands does nothing.
+func (NoOp) SetInFlightCommands(int) {}
+
+// NotificationSent does nothing.
+func (NoOp) NotificationSent(string, string, NotificationMode, int) {}
+
+// NotificationReceived does nothing.
+func (NoOp) NotificationReceived(string, string, int) {}
+
+// NotificationHandled does nothing.
+func (NoOp) NotificationHandled(string, string, time.Duration) {}
+
+// SessionCacheLookup does nothing.
+func (NoOp) SessionCacheLookup(int, int) {}
+
+// ChannelAcquired does nothing.
+func (NoOp) ChannelAcquired(bool) {}
+
+// ChannelReleased does nothing.
+func (NoOp) ChannelReleased(bool) {}
@@ -0,0 +1,21 @@
+package rinq
+
+import (
+	"context"
+
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// MigrationHandler is called when a session is migrated to this peer from a
+// peer that is stopping gracefully. See options.SessionMigration().
+//
+// sess is the migrated session. It has a new ID owned by this peer, and the
+// same revision and attributes as the original session, including frozen
+// attributes. from is the ID of the original session; revisions of the
+// original session held by other peers refer to sess transparently.
+//
+// listening contains the namespaces in which the original session was
+// listening for notifications. Notification handlers can not be migrated, so
+// h must call sess.Listen() for each namespace that the session should
+// continue to listen to.
+type MigrationHandler func(ctx context.Context, sess Session, from ident.SessionID, listening []string)
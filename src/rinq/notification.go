@@ -5,6 +5,7 @@ import (
 
 	"github.com/rinq/rinq-go/src/rinq/constraint"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/metadata"
 )
 
 // Notification holds information about an inter-session notification.
@@ -36,6 +37,12 @@ type Notification struct {
 	// criteria for selecting which sessions receive the notification. The
 	// constraint is nil if IsMulticast is false.
 	Constraint constraint.Constraint
+
+	// Metadata contains the application-defined metadata that was attached
+	// to the sender's context. It is also available in the context passed to
+	// the handler, and is forwarded to any onward requests made using that
+	// context. See the metadata package for more information.
+	Metadata metadata.MD
 }
 
 // NotificationHandler is a callback-function invoked when an inter-session
@@ -0,0 +1,23 @@
+package options
+
+// CompressionAlgorithm is an algorithm used to compress payloads before they
+// are sent over the network.
+type CompressionAlgorithm string
+
+const (
+	// NoCompression disables payload compression.
+	NoCompression CompressionAlgorithm = ""
+
+	// Gzip compresses payloads using the gzip algorithm.
+	Gzip CompressionAlgorithm = "gzip"
+
+	// Zstd compresses payloads using the Zstandard algorithm.
+	Zstd CompressionAlgorithm = "zstd"
+
+	// Snappy compresses payloads using the Snappy algorithm.
+	Snappy CompressionAlgorithm = "snappy"
+)
+
+// DefaultCompressionThreshold is the size, in bytes, at which payloads are
+// compressed when no threshold is specified.
+const DefaultCompressionThreshold = 1024
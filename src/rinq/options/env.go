@@ -11,12 +11,15 @@ import (
 //
 // The environment variables are listed below.
 //
-// - RINQ_DEFAULT_TIMEOUT (duration in milliseconds, non-zero)
-// - RINQ_LOG_DEBUG       (boolean 'true' or 'false')
-// - RINQ_COMMAND_WORKERS (positive integer, non-zero)
-// - RINQ_SESSION_WORKERS (positive integer, non-zero)
-// - RINQ_PRUNE_INTERVAL  (duration in milliseconds, non-zero)
-// - RINQ_PRODUCT         (string)
+// - RINQ_DEFAULT_TIMEOUT       (duration in milliseconds, non-zero)
+// - RINQ_LOG_DEBUG             (boolean 'true' or 'false')
+// - RINQ_COMMAND_WORKERS       (positive integer, non-zero)
+// - RINQ_SESSION_WORKERS       (positive integer, non-zero)
+// - RINQ_PRUNE_INTERVAL        (duration in milliseconds, non-zero)
+// - RINQ_PRODUCT               (string)
+// - RINQ_INTROSPECTION         (boolean 'true' or 'false')
+// - RINQ_COMPRESSION           (string 'gzip', 'zstd' or 'snappy')
+// - RINQ_COMPRESSION_THRESHOLD (size in bytes, non-zero)
 func FromEnv() ([]Option, error) {
 	var o []Option
 
@@ -60,5 +63,23 @@ func FromEnv() ([]Option, error) {
 		o = append(o, Product(p))
 	}
 
+	enabled, ok, err := env.Bool("RINQ_INTROSPECTION")
+	if err != nil {
+		return nil, err
+	} else if ok {
+		o = append(o, Introspection(enabled))
+	}
+
+	if a := os.Getenv("RINQ_COMPRESSION"); a != "" {
+		n, ok, err := env.UInt("RINQ_COMPRESSION_THRESHOLD")
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			n = DefaultCompressionThreshold
+		}
+
+		o = append(o, Compression(CompressionAlgorithm(a), n))
+	}
+
 	return o, nil
 }
@@ -18,6 +18,9 @@ var _ = Describe("FromEnv", func() {
 		os.Setenv("RINQ_SESSION_WORKERS", "")
 		os.Setenv("RINQ_PRUNE_INTERVAL", "")
 		os.Setenv("RINQ_PRODUCT", "")
+		os.Setenv("RINQ_INTROSPECTION", "")
+		os.Setenv("RINQ_COMPRESSION", "")
+		os.Setenv("RINQ_COMPRESSION_THRESHOLD", "")
 	})
 
 	It("returns an empty slice when no environment variables are set", func() {
@@ -161,4 +164,72 @@ var _ = Describe("FromEnv", func() {
 			Expect(opts.Product).To(Equal("my-app"))
 		})
 	})
+	Context("RINQ_INTROSPECTION", func() {
+		It("returns an Introspection option", func() {
+			os.Setenv("RINQ_INTROSPECTION", "true")
+			o, err := options.FromEnv()
+
+			Expect(err).NotTo(HaveOccurred())
+
+			opts, err := options.NewOptions(o...)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts.Introspection).To(BeTrue())
+		})
+
+		It("returns an error if the value is not a boolean", func() {
+			os.Setenv("RINQ_INTROSPECTION", "<not a bool>")
+			_, err := options.FromEnv()
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("RINQ_COMPRESSION", func() {
+		It("returns a Compression option with the default threshold", func() {
+			os.Setenv("RINQ_COMPRESSION", "zstd")
+			o, err := options.FromEnv()
+
+			Expect(err).NotTo(HaveOccurred())
+
+			opts, err := options.NewOptions(o...)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts.Compression).To(Equal(options.Zstd))
+			Expect(opts.CompressionThreshold).To(Equal(uint(options.DefaultCompressionThreshold)))
+		})
+
+		It("uses the threshold from RINQ_COMPRESSION_THRESHOLD", func() {
+			os.Setenv("RINQ_COMPRESSION", "gzip")
+			os.Setenv("RINQ_COMPRESSION_THRESHOLD", "256")
+			o, err := options.FromEnv()
+
+			Expect(err).NotTo(HaveOccurred())
+
+			opts, err := options.NewOptions(o...)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts.Compression).To(Equal(options.Gzip))
+			Expect(opts.CompressionThreshold).To(Equal(uint(256)))
+		})
+
+		It("returns an error if the threshold is not a positive integer", func() {
+			os.Setenv("RINQ_COMPRESSION", "gzip")
+			os.Setenv("RINQ_COMPRESSION_THRESHOLD", "-500")
+			_, err := options.FromEnv()
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("results in an error if the algorithm is not supported", func() {
+			os.Setenv("RINQ_COMPRESSION", "lzma")
+			o, err := options.FromEnv()
+
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = options.NewOptions(o...)
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
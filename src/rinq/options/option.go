@@ -5,6 +5,14 @@ import (
 
 	"github.com/jmalloc/twelf/src/twelf"
 	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/rinq/rinq-go/src/internal/oteltr"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/capture"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/metrics"
+	"github.com/rinq/rinq-go/src/rinq/redact"
+	"github.com/rinq/rinq-go/src/rinq/sessionstore"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Option is a function that applies a configuration change.
@@ -20,12 +28,27 @@ func DefaultTimeout(t time.Duration) Option {
 }
 
 // Logger returns an Option that specifies the target for all of the peer's logs.
+//
+// Only the human-readable message of each log entry is written to l. Use
+// StructuredLogger() to retain the key/value fields of each entry.
 func Logger(l twelf.Logger) Option {
 	return func(v visitor) error {
 		return v.applyLogger(l)
 	}
 }
 
+// StructuredLogger returns an Option that specifies the target for all of the
+// peer's logs.
+//
+// Each log entry includes fields such as the peer ID, message ID, namespace,
+// command, trace ID, durations and payload sizes. See the logging package for
+// adapters to common logging libraries.
+func StructuredLogger(l logging.Logger) Option {
+	return func(v visitor) error {
+		return v.applyStructuredLogger(l)
+	}
+}
+
 // CommandWorkers returns an Option that specifies the number of incoming command
 // REQUESTS that are accepted at any given time. A new goroutine is started to
 // service each command request.
@@ -71,3 +94,107 @@ func Tracer(t opentracing.Tracer) Option {
 		return v.applyTracer(t)
 	}
 }
+
+// OpenTelemetry returns an Option that specifies an OpenTelemetry tracer
+// provider to use for tracking Rinq operations.
+//
+// The spans produced are equivalent to those produced by an OpenTracing tracer.
+// Span contexts are propagated between peers in the W3C Trace Context format.
+// This option replaces any tracer specified by Tracer().
+//
+// See https://opentelemetry.io for more information.
+func OpenTelemetry(tp trace.TracerProvider) Option {
+	return func(v visitor) error {
+		return v.applyTracer(oteltr.NewTracer(tp))
+	}
+}
+
+// Metrics returns an Option that specifies the recorder used to collect metrics
+// about the peer's operation.
+//
+// See the prommetrics package for a recorder that exports metrics to
+// Prometheus.
+func Metrics(r metrics.Recorder) Option {
+	return func(v visitor) error {
+		return v.applyMetrics(r)
+	}
+}
+
+// Redactor returns an Option that specifies how payloads are rendered in debug
+// logs and tracing spans.
+//
+// By default payloads are logged in full. Use redact.NewRules() to mask
+// fields, truncate or suppress payloads on a per-namespace or per-command
+// basis.
+func Redactor(r redact.Redactor) Option {
+	return func(v visitor) error {
+		return v.applyRedactor(r)
+	}
+}
+
+// Capture returns an Option that specifies a recorder that receives a copy of
+// the command requests, responses and notifications seen by the peer.
+//
+// Use capture.NewWriter() to write the captured traffic to a file, which can
+// later be replayed against a network using the "rinq replay" command.
+// Traffic within internal namespaces is not captured. Capture is disabled by
+// default.
+func Capture(r capture.Recorder) Option {
+	return func(v visitor) error {
+		return v.applyCapture(r)
+	}
+}
+
+// SessionStore returns an Option that specifies where the state of the peer's
+// local sessions is persisted.
+//
+// By default sessions are kept only in memory, and are destroyed when the peer
+// stops. Sessions in any other store are closed without being destroyed, so
+// that they can be restored by a peer with the same peer ID, see
+// rinqamqp.Dialer for more information.
+func SessionStore(s sessionstore.Store) Option {
+	return func(v visitor) error {
+		return v.applySessionStore(s)
+	}
+}
+
+// Introspection returns an Option that specifies whether the peer answers
+// requests for runtime diagnostic information about itself.
+//
+// When enabled, the peer listens to the internal "_peer" namespace, allowing
+// any other peer on the network to obtain information such as the namespaces
+// it listens to, its local sessions and its pending work using
+// Peer.Inspect(). Introspection is disabled by default.
+func Introspection(enabled bool) Option {
+	return func(v visitor) error {
+		return v.applyIntrospection(enabled)
+	}
+}
+
+// SessionMigration returns an Option that enables session migration.
+//
+// When a peer with session migration enabled is stopped with GracefulStop(),
+// its sessions are handed to another peer that also has session migration
+// enabled, rather than being destroyed. h is called for each session that is
+// migrated to this peer, see rinq.MigrationHandler.
+//
+// If no other peer accepts the sessions before the default timeout, they are
+// destroyed as usual. Session migration is disabled by default, or if h is nil.
+func SessionMigration(h rinq.MigrationHandler) Option {
+	return func(v visitor) error {
+		return v.applySessionMigration(h)
+	}
+}
+
+// Compression returns an Option that specifies the algorithm used to compress
+// payloads that are at least threshold bytes in length.
+//
+// Compressed payloads can be decompressed by any peer that supports the chosen
+// algorithm, regardless of its own compression settings. Compression should
+// only be enabled once every peer on the network has been upgraded to a version
+// that supports it.
+func Compression(a CompressionAlgorithm, threshold uint) Option {
+	return func(v visitor) error {
+		return v.applyCompression(a, threshold)
+	}
+}
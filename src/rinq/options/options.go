@@ -1,10 +1,17 @@
 package options
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/jmalloc/twelf/src/twelf"
 	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/capture"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/metrics"
+	"github.com/rinq/rinq-go/src/rinq/redact"
+	"github.com/rinq/rinq-go/src/rinq/sessionstore"
 )
 
 // Options is a structure representing a resolved set of options.
@@ -16,6 +23,22 @@ type Options struct {
 	PruneInterval  time.Duration
 	Product        string
 	Tracer         opentracing.Tracer
+	Metrics        metrics.Recorder
+	Redactor       redact.Redactor
+	Capture        capture.Recorder
+	SessionStore   sessionstore.Store
+	Introspection  bool
+
+	// StructuredLogger is the logger used by the peer. It writes to Logger
+	// unless a structured logger is specified with the StructuredLogger()
+	// option, in which case Logger is an adapter that writes to it.
+	StructuredLogger logging.Logger
+
+	// MigrationHandler is nil if session migration is disabled.
+	MigrationHandler rinq.MigrationHandler
+
+	Compression          CompressionAlgorithm
+	CompressionThreshold uint
 }
 
 // NewOptions returns a new Options object from the given options, with default
@@ -31,13 +54,25 @@ func (o *Options) applyDefaultTimeout(v time.Duration) error {
 	return nil
 }
 
-// applyLogger sets the Logger value.
+// applyLogger sets the Logger and StructuredLogger values.
 func (o *Options) applyLogger(v twelf.Logger) error {
 	if v == nil {
 		panic("logger must not be nil")
 	}
 
 	o.Logger = v
+	o.StructuredLogger = logging.FromTwelf(v)
+	return nil
+}
+
+// applyStructuredLogger sets the StructuredLogger and Logger values.
+func (o *Options) applyStructuredLogger(v logging.Logger) error {
+	if v == nil {
+		panic("logger must not be nil")
+	}
+
+	o.StructuredLogger = v
+	o.Logger = logging.ToTwelf(v)
 	return nil
 }
 
@@ -74,3 +109,68 @@ func (o *Options) applyTracer(v opentracing.Tracer) error {
 	o.Tracer = v
 	return nil
 }
+
+// applyMetrics sets the Metrics value.
+func (o *Options) applyMetrics(v metrics.Recorder) error {
+	if v == nil {
+		panic("metrics recorder must not be nil")
+	}
+
+	o.Metrics = v
+	return nil
+}
+
+// applyRedactor sets the Redactor value.
+func (o *Options) applyRedactor(v redact.Redactor) error {
+	if v == nil {
+		panic("redactor must not be nil")
+	}
+
+	o.Redactor = v
+	return nil
+}
+
+// applyCapture sets the Capture value.
+func (o *Options) applyCapture(v capture.Recorder) error {
+	if v == nil {
+		panic("capture recorder must not be nil")
+	}
+
+	o.Capture = v
+	return nil
+}
+
+// applySessionStore sets the SessionStore value.
+func (o *Options) applySessionStore(v sessionstore.Store) error {
+	if v == nil {
+		panic("session store must not be nil")
+	}
+
+	o.SessionStore = v
+	return nil
+}
+
+// applyIntrospection sets the Introspection value.
+func (o *Options) applyIntrospection(v bool) error {
+	o.Introspection = v
+	return nil
+}
+
+// applySessionMigration sets the MigrationHandler value.
+func (o *Options) applySessionMigration(h rinq.MigrationHandler) error {
+	o.MigrationHandler = h
+	return nil
+}
+
+// applyCompression sets the Compression and CompressionThreshold values.
+func (o *Options) applyCompression(a CompressionAlgorithm, t uint) error {
+	switch a {
+	case NoCompression, Gzip, Zstd, Snappy:
+	default:
+		return fmt.Errorf("unsupported compression algorithm: %s", a)
+	}
+
+	o.Compression = a
+	o.CompressionThreshold = t
+	return nil
+}
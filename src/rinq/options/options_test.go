@@ -8,7 +8,12 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/rinq/rinq-go/src/rinq/capture"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/metrics"
 	"github.com/rinq/rinq-go/src/rinq/options"
+	"github.com/rinq/rinq-go/src/rinq/redact"
+	"github.com/rinq/rinq-go/src/rinq/sessionstore"
 )
 
 var _ = Describe("NewOptions", func() {
@@ -24,6 +29,24 @@ var _ = Describe("NewOptions", func() {
 			PruneInterval:  3 * time.Minute,
 			Product:        "",
 			Tracer:         opentracing.NoopTracer{},
+			Metrics:        metrics.NoOp{},
+			Redactor:       redact.None,
+			Capture:        capture.Discard,
+			SessionStore:   sessionstore.None,
+			Introspection:  false,
+
+			StructuredLogger: logging.FromTwelf(&twelf.StandardLogger{}),
+
+			Compression:          options.NoCompression,
+			CompressionThreshold: options.DefaultCompressionThreshold,
 		}))
 	})
+
+	It("returns an error if the compression algorithm is not supported", func() {
+		_, err := options.NewOptions(
+			options.Compression("<unknown>", 0),
+		)
+
+		Expect(err).To(HaveOccurred())
+	})
 })
@@ -6,17 +6,31 @@ import (
 
 	"github.com/jmalloc/twelf/src/twelf"
 	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/capture"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/metrics"
+	"github.com/rinq/rinq-go/src/rinq/redact"
+	"github.com/rinq/rinq-go/src/rinq/sessionstore"
 )
 
 // visitor handles the application of options.
 type visitor interface {
 	applyDefaultTimeout(time.Duration) error
 	applyLogger(twelf.Logger) error
+	applyStructuredLogger(logging.Logger) error
 	applyCommandWorkers(uint) error
 	applySessionWorkers(uint) error
 	applyPruneInterval(time.Duration) error
 	applyProduct(string) error
 	applyTracer(opentracing.Tracer) error
+	applyMetrics(metrics.Recorder) error
+	applyRedactor(redact.Redactor) error
+	applyCapture(capture.Recorder) error
+	applySessionStore(sessionstore.Store) error
+	applyIntrospection(bool) error
+	applySessionMigration(rinq.MigrationHandler) error
+	applyCompression(CompressionAlgorithm, uint) error
 }
 
 // Apply applies the default options, then a sequence of additional options to v.
@@ -46,6 +60,30 @@ func Apply(v visitor, opts ...Option) error {
 		return err
 	}
 
+	if err := v.applyMetrics(metrics.NoOp{}); err != nil {
+		return err
+	}
+
+	if err := v.applyRedactor(redact.None); err != nil {
+		return err
+	}
+
+	if err := v.applyCapture(capture.Discard); err != nil {
+		return err
+	}
+
+	if err := v.applySessionStore(sessionstore.None); err != nil {
+		return err
+	}
+
+	if err := v.applyIntrospection(false); err != nil {
+		return err
+	}
+
+	if err := v.applyCompression(NoCompression, DefaultCompressionThreshold); err != nil {
+		return err
+	}
+
 	for _, o := range opts {
 		if err := o(v); err != nil {
 			return err
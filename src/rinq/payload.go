@@ -2,12 +2,13 @@ package rinq
 
 import (
 	"bytes"
+	"fmt"
 	"reflect"
 	"sync"
 
 	"github.com/rinq/rinq-go/src/internal/x/bufferpool"
-	"github.com/rinq/rinq-go/src/internal/x/cbor"
-	"github.com/ugorji/go/codec"
+	"github.com/rinq/rinq-go/src/rinq/codec"
+	ugorji "github.com/ugorji/go/codec"
 )
 
 // Payload is an immutable, application-defined value that is included in a
@@ -24,8 +25,11 @@ import (
 // goroutines, call Payload.Clone() to obtain a second payload that references
 // the same underlying data.
 //
-// Payload values can be any value that can be represented using CBOR encoding.
-// See http://cbor.io/ for more information.
+// Payload values can be any value that can be represented using the payload's
+// codec. By default, payloads use CBOR encoding, see http://cbor.io/ for more
+// information. Payloads that use other encodings can be constructed using
+// NewPayloadWithCodec() or NewPayloadFromBytesWithContentType(), and converted
+// between encodings using Payload.Transcode().
 //
 // Payloads are modeled in this way to allow an application to forward incoming
 // payloads without the need to decode and re-encode them.
@@ -33,8 +37,15 @@ type Payload struct {
 	data *payloadData
 }
 
-// NewPayload creates a new payload from an arbitrary value.
+// NewPayload creates a new payload from an arbitrary value, using the default
+// CBOR codec.
 func NewPayload(v interface{}) *Payload {
+	return NewPayloadWithCodec(v, codec.CBOR)
+}
+
+// NewPayloadWithCodec creates a new payload from an arbitrary value, using c to
+// produce the payload's binary representation.
+func NewPayloadWithCodec(v interface{}, c codec.Codec) *Payload {
 	if v == nil {
 		return nil
 	}
@@ -46,9 +57,11 @@ func NewPayload(v interface{}) *Payload {
 
 	return &Payload{
 		&payloadData{
-			value:    v,
-			hasValue: true,
-			refCount: 1,
+			codec:       c,
+			contentType: c.ContentType(),
+			value:       v,
+			hasValue:    true,
+			refCount:    1,
 		},
 	}
 }
@@ -56,15 +69,36 @@ func NewPayload(v interface{}) *Payload {
 // NewPayloadFromBytes creates a new payload from a binary representation.
 // Ownership of the byte-slice is transferred to the payload. An empty
 // byte-slice is equivalent to the nil value.
+//
+// The binary representation must be CBOR encoded.
 func NewPayloadFromBytes(buf []byte) *Payload {
+	return NewPayloadFromBytesWithContentType(buf, "")
+}
+
+// NewPayloadFromBytesWithContentType creates a new payload from a binary
+// representation that is encoded using the codec registered for the
+// content-type ct. Ownership of the byte-slice is transferred to the payload.
+// An empty byte-slice is equivalent to the nil value.
+//
+// An empty content-type is equivalent to CBOR encoding. If no codec is
+// registered for ct, the payload can still be forwarded or transcoded to
+// its binary representation, but it can not be decoded.
+func NewPayloadFromBytesWithContentType(buf []byte, ct string) *Payload {
 	if len(buf) == 0 {
 		return nil
 	}
 
+	c, _ := codec.Lookup(ct)
+	if ct == "" {
+		ct = c.ContentType()
+	}
+
 	return &Payload{
 		&payloadData{
-			buffer:   bytes.NewBuffer(buf),
-			refCount: 1,
+			codec:       c,
+			contentType: ct,
+			buffer:      bytes.NewBuffer(buf),
+			refCount:    1,
 		},
 	}
 }
@@ -83,7 +117,19 @@ func (p *Payload) Clone() *Payload {
 	return &Payload{p.data}
 }
 
-// Bytes returns the binary representation of the payload, in CBOR encoding.
+// ContentType returns the MIME type of the payload's binary representation.
+//
+// The content-type of a nil payload is that of the default CBOR codec.
+func (p *Payload) ContentType() string {
+	if p == nil || p.data == nil {
+		return codec.CBOR.ContentType()
+	}
+
+	return p.data.contentType
+}
+
+// Bytes returns the binary representation of the payload, encoded using the
+// payload's codec.
 //
 // The returned byte-slice is invalidated when the payload is closed, it must be
 // copied if it is intended to be used for longer than the lifetime of the
@@ -93,26 +139,46 @@ func (p *Payload) Clone() *Payload {
 // always that same byte-slice, unless the payload has been closed.
 //
 // If the payload was created from a nil value, the returned byte-slice is nil.
+//
+// It panics if the payload value can not be encoded by the payload's codec,
+// use Encode() to obtain the error instead.
 func (p *Payload) Bytes() []byte {
+	buf, err := p.Encode()
+	if err != nil {
+		panic(err)
+	}
+
+	return buf
+}
+
+// Encode returns the binary representation of the payload, encoded using the
+// payload's codec, or an error if the payload value can not be encoded.
+//
+// The returned byte-slice is subject to the same rules as the result of
+// Bytes().
+func (p *Payload) Encode() ([]byte, error) {
 	if p == nil || p.data == nil {
-		return nil
+		return nil, nil
 	}
 
 	p.data.readMutex.Lock()
 	defer p.data.readMutex.Unlock()
 
 	if p.data.buffer != nil {
-		return p.data.buffer.Bytes()
+		return p.data.buffer.Bytes(), nil
 	}
 
 	p.data.writeMutex.Lock()
 	defer p.data.writeMutex.Unlock()
 
 	buffer := bufferpool.Get()
-	cbor.MustEncode(buffer, p.data.value)
+	if err := p.data.codec.Encode(buffer, p.data.value); err != nil {
+		bufferpool.Put(buffer)
+		return nil, err
+	}
 	p.data.buffer = buffer
 
-	return buffer.Bytes()
+	return buffer.Bytes(), nil
 }
 
 // Len returns the encoded payload length, in bytes.
@@ -121,36 +187,103 @@ func (p *Payload) Len() int {
 	return len(p.Bytes())
 }
 
-// Decode unpacks the payload into the given value.
+// Decode unpacks the payload into the given value, according to the payload's
+// content-type.
 func (p *Payload) Decode(value interface{}) error {
-	buf := p.Bytes()
-	if buf == nil {
-		buf = cbor.Nil
+	buf, err := p.Encode()
+	if err != nil {
+		return err
+	} else if buf == nil {
+		return codec.CBOR.Decode(nil, value)
+	}
+
+	if p.data.codec == nil {
+		return fmt.Errorf("can not decode payload, no codec is registered for '%s'", p.data.contentType)
 	}
 
-	return cbor.DecodeBytes(buf, value)
+	return p.data.codec.Decode(buf, value)
 }
 
 // Value returns the payload value.
+//
+// It panics if the payload can not be decoded, use DecodeValue() to obtain the
+// error instead.
 func (p *Payload) Value() interface{} {
+	v, err := p.value()
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+// DecodeValue returns the payload value, or an error if the payload can not be
+// decoded into a generic value. This is always the case for payloads encoded
+// with codec.Protobuf, which must be decoded into a specific message type
+// using Decode().
+func (p *Payload) DecodeValue() (interface{}, error) {
+	return p.value()
+}
+
+// Transcode returns a new payload with the same value as p, encoded using c.
+//
+// If p already uses the content-type of c, the result is a clone of p.
+func (p *Payload) Transcode(c codec.Codec) (*Payload, error) {
+	if p.ContentType() == c.ContentType() {
+		return p.Clone(), nil
+	}
+
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+
+	t := NewPayloadWithCodec(v, c)
+
+	// encode the value immediately so that encoding errors are reported
+	// here rather than when the payload is sent.
+	if t != nil {
+		buffer := bufferpool.Get()
+		if err := c.Encode(buffer, v); err != nil {
+			bufferpool.Put(buffer)
+			return nil, err
+		}
+		t.data.buffer = buffer
+	}
+
+	return t, nil
+}
+
+// value returns the payload value, decoding it if necessary.
+func (p *Payload) value() (interface{}, error) {
 	if p == nil || p.data == nil {
-		return nil
+		return nil, nil
 	}
 
 	p.data.readMutex.Lock()
 	defer p.data.readMutex.Unlock()
 
 	if p.data.hasValue {
-		return p.data.value
+		return p.data.value, nil
+	}
+
+	if p.data.codec == nil {
+		return nil, fmt.Errorf("can not decode payload, no codec is registered for '%s'", p.data.contentType)
+	}
+
+	if p.data.contentType == codec.Protobuf.ContentType() {
+		return nil, fmt.Errorf("can not decode '%s' payload into a generic value, use Decode() with a proto.Message", p.data.contentType)
 	}
 
 	p.data.writeMutex.Lock()
 	defer p.data.writeMutex.Unlock()
 
-	cbor.MustDecodeBytes(p.data.buffer.Bytes(), &p.data.value)
+	if err := p.data.codec.Decode(p.data.buffer.Bytes(), &p.data.value); err != nil {
+		return nil, err
+	}
 	p.data.hasValue = true
 
-	return p.data.value
+	return p.data.value, nil
 }
 
 // Close releases any resources held by the payload, resetting the payload to
@@ -176,20 +309,42 @@ func (p *Payload) Close() {
 // String returns a human-readable representation of the payload.
 // No guarantees are made about the format of the string.
 func (p *Payload) String() string {
+	v, err := p.value()
+	if err != nil {
+		return p.describe()
+	}
+
 	buffer := bufferpool.Get()
 	defer bufferpool.Put(buffer)
 
-	encoder := jsonEncoders.Get().(*codec.Encoder)
+	encoder := jsonEncoders.Get().(*ugorji.Encoder)
+	defer jsonEncoders.Put(encoder)
+
 	encoder.Reset(buffer)
-	encoder.MustEncode(p.Value())
+	if err := encoder.Encode(v); err != nil {
+		return p.describe()
+	}
 
 	return buffer.String()
 }
 
+// describe returns a description of the payload's binary representation, for
+// use by String() when the value can not be rendered.
+func (p *Payload) describe() string {
+	buf, _ := p.Encode()
+	return fmt.Sprintf("<%d bytes of %s>", len(buf), p.ContentType())
+}
+
 type payloadData struct {
 	readMutex  sync.Mutex
 	writeMutex sync.Mutex
 
+	// The codec used to encode and decode the payload, and its content-type.
+	// The codec is nil if the payload was created from bytes with a
+	// content-type that has no registered codec.
+	codec       codec.Codec
+	contentType string
+
 	// The binary representation of the payload. If the payload has never been
 	// encoded, buffer is nil.
 	buffer *bytes.Buffer
@@ -206,9 +361,9 @@ type payloadData struct {
 	refCount uint
 }
 
-var jsonHandle codec.JsonHandle
+var jsonHandle ugorji.JsonHandle
 var jsonEncoders = sync.Pool{
 	New: func() interface{} {
-		return codec.NewEncoder(nil, &jsonHandle)
+		return ugorji.NewEncoder(nil, &jsonHandle)
 	},
 }
@@ -5,6 +5,7 @@ import (
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/codec"
 )
 
 var _ = Describe("Payload", func() {
@@ -33,7 +34,7 @@ var _ = Describe("Payload", func() {
 				c := p.Clone()
 				defer c.Close()
 
-				Expect(c.Value()).To(BeEquivalentTo(c.Value()))
+				Expect(c.Value()).To(BeEquivalentTo(p.Value()))
 			},
 			Entry("created from bytes", rinq.NewPayloadFromBytes([]byte{24, 123})),
 			Entry("created from value", rinq.NewPayload(123)),
@@ -98,6 +99,64 @@ var _ = Describe("Payload", func() {
 			Entry("created from bytes", rinq.NewPayloadFromBytes([]byte{24, 123}), 123),
 			Entry("created from value", rinq.NewPayload(123), 123),
 		)
+
+		It("panics if the payload can not be decoded", func() {
+			p := rinq.NewPayloadFromBytesWithContentType([]byte{8, 1}, codec.Protobuf.ContentType())
+			defer p.Close()
+
+			Expect(func() {
+				p.Value()
+			}).To(Panic())
+		})
+	})
+
+	Describe("DecodeValue", func() {
+		It("returns the payload value", func() {
+			p := rinq.NewPayloadFromBytes([]byte{24, 123})
+			defer p.Close()
+
+			v, err := p.DecodeValue()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(v).To(BeEquivalentTo(123))
+		})
+
+		It("returns an error if the payload is protobuf encoded", func() {
+			p := rinq.NewPayloadFromBytesWithContentType([]byte{8, 1}, codec.Protobuf.ContentType())
+			defer p.Close()
+
+			_, err := p.DecodeValue()
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("Encode", func() {
+		It("returns the binary representation of the payload", func() {
+			p := rinq.NewPayload(123)
+			defer p.Close()
+
+			buf, err := p.Encode()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(buf).To(Equal([]byte{24, 123}))
+		})
+
+		It("returns an error if the value can not be encoded", func() {
+			p := rinq.NewPayloadWithCodec("<value>", codec.Protobuf)
+			defer p.Close()
+
+			_, err := p.Encode()
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("Bytes (encoding failure)", func() {
+		It("panics if the value can not be encoded", func() {
+			p := rinq.NewPayloadWithCodec("<value>", codec.Protobuf)
+			defer p.Close()
+
+			Expect(func() {
+				p.Bytes()
+			}).To(Panic())
+		})
 	})
 
 	Describe("Decode", func() {
@@ -153,6 +212,86 @@ var _ = Describe("Payload", func() {
 
 			Expect(p.String()).To(Equal(`{"foo":"bar"}`))
 		})
+
+		It("returns a description of the payload if it can not be decoded", func() {
+			p := rinq.NewPayloadFromBytesWithContentType([]byte("<data>"), "<unknown>")
+			defer p.Close()
+
+			Expect(p.String()).To(Equal("<6 bytes of <unknown>>"))
+		})
+	})
+
+	Describe("ContentType", func() {
+		DescribeTable(
+			"returns the expected content-type",
+			func(p *rinq.Payload, expected string) {
+				defer p.Close()
+
+				Expect(p.ContentType()).To(Equal(expected))
+			},
+			Entry("nil pointer", nil, "application/cbor"),
+			Entry("created from bytes", rinq.NewPayloadFromBytes([]byte{24, 123}), "application/cbor"),
+			Entry("created from value", rinq.NewPayload(123), "application/cbor"),
+			Entry("created from bytes with content-type", rinq.NewPayloadFromBytesWithContentType([]byte("123"), "application/json"), "application/json"),
+			Entry("created from value with codec", rinq.NewPayloadWithCodec(123, codec.JSON), "application/json"),
+		)
+	})
+
+	Describe("Decode", func() {
+		It("decodes according to the content-type", func() {
+			p := rinq.NewPayloadFromBytesWithContentType([]byte(`{"foo":"bar"}`), "application/json")
+			defer p.Close()
+
+			var v map[string]string
+			err := p.Decode(&v)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(v).To(Equal(map[string]string{"foo": "bar"}))
+		})
+
+		It("returns an error if there is no codec for the content-type", func() {
+			p := rinq.NewPayloadFromBytesWithContentType([]byte("<data>"), "<unknown>")
+			defer p.Close()
+
+			var v interface{}
+			err := p.Decode(&v)
+
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("Transcode", func() {
+		It("returns a payload encoded with the given codec", func() {
+			p := rinq.NewPayload(map[string]string{"foo": "bar"})
+			defer p.Close()
+
+			t, err := p.Transcode(codec.JSON)
+			defer t.Close()
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(t.ContentType()).To(Equal("application/json"))
+			Expect(string(t.Bytes())).To(Equal(`{"foo":"bar"}`))
+		})
+
+		It("returns a clone if the payload already uses the codec", func() {
+			p := rinq.NewPayloadFromBytes([]byte{24, 123})
+			defer p.Close()
+
+			t, err := p.Transcode(codec.CBOR)
+			defer t.Close()
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(t.Bytes()).To(Equal(p.Bytes()))
+		})
+
+		It("returns an error if the value can not be encoded with the codec", func() {
+			p := rinq.NewPayload("<value>")
+			defer p.Close()
+
+			_, err := p.Transcode(codec.Protobuf)
+
+			Expect(err).Should(HaveOccurred())
+		})
 	})
 })
 
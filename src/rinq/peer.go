@@ -1,6 +1,11 @@
 package rinq
 
-import "github.com/rinq/rinq-go/src/rinq/ident"
+import (
+	"context"
+
+	"github.com/rinq/rinq-go/src/rinq/constraint"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
 
 // Peer represents a connection to a Rinq network.
 //
@@ -25,7 +30,33 @@ type Peer interface {
 	//
 	// Sessions created after the peer has been stopped are unusable. Any
 	// operation will fail immediately.
-	Session() Session
+	//
+	// By default sessions live until they are destroyed or the peer stops. The
+	// TTL() and IdleTimeout() options cause the session to be destroyed
+	// automatically, see Expiry.
+	Session(opts ...SessionOption) Session
+
+	// Sessions returns the sessions owned by this peer that have not been
+	// destroyed, ordered by session ID.
+	//
+	// This includes any sessions that were restored from the session store
+	// when the peer connected, see options.SessionStore().
+	Sessions() []Session
+
+	// SessionEvents returns a channel that receives an event each time a
+	// session owned by this peer is created, updated, cleared or destroyed,
+	// including changes made by other peers via remote revisions.
+	//
+	// Only events that occur after the call are delivered. Events for each
+	// session are delivered in revision order. Events are queued for
+	// delivery, so a slow receiver never blocks operations on the sessions.
+	//
+	// The channel is closed when ctx is canceled, or once the peer has
+	// stopped and all queued events have been delivered. Sessions that are
+	// migrated to another peer, or closed because the peer stopped while
+	// using a persistent session store, do not produce SessionDestroyed
+	// events.
+	SessionEvents(ctx context.Context) <-chan SessionEvent
 
 	// Listen starts listening for command requests in the given namespace.
 	//
@@ -55,6 +86,43 @@ type Peer interface {
 	// GracefulStop() has been called.
 	Err() error
 
+	// Health returns a report of the current health of the peer, including
+	// the state of its broker connection, internal services and pending work.
+	//
+	// It is intended for use by liveness and readiness probes. See the
+	// healthcheck package for an HTTP handler that serves the report.
+	Health() Health
+
+	// Inspect fetches runtime diagnostic information from the peer with the
+	// given ID, which may be this peer.
+	//
+	// The target peer must have been started with the options.Introspection()
+	// option. Requests made to peers without introspection enabled are never
+	// answered, and fail when the context deadline is met.
+	Inspect(ctx context.Context, target ident.PeerID) (PeerInfo, error)
+
+	// InspectSession fetches the current revision and attributes of the
+	// session with the given ID, which may be owned by any peer.
+	//
+	// If the session does not exist, or has been destroyed, a NotFoundError is
+	// returned.
+	InspectSession(ctx context.Context, id ident.SessionID) (SessionInfo, error)
+
+	// FindSessions searches the network for sessions with attributes that
+	// match the constraint c. ns is the namespace in which c is evaluated,
+	// unless c specifies a different namespace using constraint.Within().
+	//
+	// The query is sent to every peer, including this one, each of which
+	// evaluates c against the current revision of the sessions it owns.
+	// The returned refs identify each matching session at that revision.
+	//
+	// Results are collected until limit sessions have been found, or the
+	// ctx deadline is met, whichever happens first. A limit of zero means
+	// there is no limit. If ctx has no deadline, the default timeout is used,
+	// see options.DefaultTimeout(). Meeting the deadline is not an error, the
+	// sessions found up to that point are returned.
+	FindSessions(ctx context.Context, ns string, c constraint.Constraint, limit int) ([]ident.Ref, error)
+
 	// Stop instructs the peer to disconnect from the network immediately.
 	//
 	// Stop does NOT block until the peer is disconnected. Use the Done()
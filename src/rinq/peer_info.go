@@ -0,0 +1,51 @@
+package rinq
+
+import (
+	"time"
+
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// PeerInfo contains runtime diagnostic information about a peer, as returned
+// by Peer.Inspect().
+type PeerInfo struct {
+	// ID is the ID of the peer that produced the information.
+	ID ident.PeerID `json:"id"`
+
+	// Version is the version of the rinq-go library used by the peer.
+	Version string `json:"version"`
+
+	// Product is the application-defined product string, as specified by
+	// options.Product().
+	Product string `json:"product,omitempty"`
+
+	// StartedAt is the time at which the peer connected to the network.
+	StartedAt time.Time `json:"started_at"`
+
+	// Uptime is the amount of time that the peer had been connected at the
+	// time the information was produced.
+	Uptime time.Duration `json:"uptime"`
+
+	// Namespaces is the sorted list of command namespaces that the peer is
+	// listening to, including internal namespaces.
+	Namespaces []string `json:"namespaces"`
+
+	// Sessions contains the IDs of the sessions owned by the peer.
+	Sessions []ident.SessionID `json:"sessions"`
+
+	// PendingCalls is the number of outgoing command requests that are
+	// awaiting a response.
+	PendingCalls int `json:"pending_calls"`
+
+	// InFlightCommands is the number of incoming command requests that are
+	// currently being handled.
+	InFlightCommands int `json:"in_flight_commands"`
+
+	// InFlightNotifications is the number of incoming notifications that are
+	// currently being handled.
+	InFlightNotifications int `json:"in_flight_notifications"`
+
+	// Options is a human-readable description of the options in effect on the
+	// peer, keyed by option name.
+	Options map[string]string `json:"options"`
+}
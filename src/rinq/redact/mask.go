@@ -0,0 +1,87 @@
+package redact
+
+import (
+	"fmt"
+
+	"github.com/rinq/rinq-go/src/rinq"
+	ugorji "github.com/ugorji/go/codec"
+)
+
+// Masked is the value that replaces masked fields.
+const Masked = "***"
+
+// Mask returns a Redactor that replaces the values of the given keys with
+// Masked, wherever they occur in the payload, including within nested maps
+// and arrays.
+//
+// The result is rendered as JSON with map keys sorted, so that the same
+// payload is always redacted to the same string. Payloads that cannot be
+// decoded are suppressed.
+func Mask(keys ...string) Redactor {
+	set := map[string]struct{}{}
+	for _, k := range keys {
+		set[k] = struct{}{}
+	}
+
+	return Func(func(_, _ string, p *rinq.Payload) string {
+		var v interface{}
+		if err := p.Decode(&v); err != nil {
+			return fmt.Sprintf("<%d bytes redacted>", p.Len())
+		}
+
+		var b []byte
+		if err := ugorji.NewEncoderBytes(&b, jsonHandle).Encode(mask(v, set)); err != nil {
+			return fmt.Sprintf("<%d bytes redacted>", p.Len())
+		}
+
+		return string(b)
+	})
+}
+
+// jsonHandle renders masked payloads as JSON, with map keys sorted.
+var jsonHandle = &ugorji.JsonHandle{
+	BasicHandle: ugorji.BasicHandle{
+		EncodeOptions: ugorji.EncodeOptions{
+			Canonical: true,
+		},
+	},
+}
+
+// mask returns a copy of v with the values of any keys in set replaced.
+func mask(v interface{}, set map[string]struct{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		r := make(map[string]interface{}, len(x))
+		for k, e := range x {
+			if _, ok := set[k]; ok {
+				r[k] = Masked
+			} else {
+				r[k] = mask(e, set)
+			}
+		}
+		return r
+
+	case map[interface{}]interface{}:
+		r := make(map[interface{}]interface{}, len(x))
+		for k, e := range x {
+			if s, ok := k.(string); ok {
+				if _, ok := set[s]; ok {
+					r[k] = Masked
+					continue
+				}
+			}
+			r[k] = mask(e, set)
+		}
+		return r
+
+	case []interface{}:
+		r := make([]interface{}, len(x))
+		for i, e := range x {
+			r[i] = mask(e, set)
+		}
+		return r
+
+	default:
+		return v
+	}
+}
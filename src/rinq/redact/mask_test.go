@@ -0,0 +1,45 @@
+package redact_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/redact"
+)
+
+var _ = Describe("Mask", func() {
+	It("replaces the values of the given keys", func() {
+		p := rinq.NewPayload(map[string]interface{}{
+			"user":     "bob",
+			"password": "hunter2",
+		})
+		defer p.Close()
+
+		s := redact.Mask("password").Redact("ns", "cmd", p)
+
+		Expect(s).To(Equal(`{"password":"***","user":"bob"}`))
+	})
+
+	It("replaces values within nested maps and arrays", func() {
+		p := rinq.NewPayload(map[string]interface{}{
+			"accounts": []interface{}{
+				map[string]interface{}{"token": "abc", "id": 1},
+				map[string]interface{}{"token": "def", "id": 2},
+			},
+		})
+		defer p.Close()
+
+		s := redact.Mask("token").Redact("ns", "cmd", p)
+
+		Expect(s).NotTo(ContainSubstring("abc"))
+		Expect(s).NotTo(ContainSubstring("def"))
+		Expect(s).To(ContainSubstring(redact.Masked))
+	})
+
+	It("renders payloads without matching keys unchanged", func() {
+		p := rinq.NewPayload([]interface{}{1, "two"})
+		defer p.Close()
+
+		Expect(redact.Mask("password").Redact("ns", "cmd", p)).To(Equal(p.String()))
+	})
+})
@@ -0,0 +1,4 @@
+// Package redact provides hooks for controlling how payloads are rendered in
+// debug logs and tracing spans, so that sensitive values are not leaked to
+// logging or tracing backends.
+package redact
@@ -0,0 +1,72 @@
+package redact
+
+import (
+	"fmt"
+
+	"github.com/rinq/rinq-go/src/rinq"
+)
+
+// Redactor renders a payload in a form that is safe to include in logs.
+type Redactor interface {
+	// Redact returns a human-readable representation of p, which was sent or
+	// received as part of the command or notification with the given
+	// namespace and name.
+	Redact(ns, name string, p *rinq.Payload) string
+}
+
+// Func is a function that implements Redactor.
+type Func func(ns, name string, p *rinq.Payload) string
+
+// Redact returns fn(ns, name, p).
+func (fn Func) Redact(ns, name string, p *rinq.Payload) string {
+	return fn(ns, name, p)
+}
+
+// None is a Redactor that renders payloads in full, using Payload.String().
+var None Redactor = none{}
+
+type none struct{}
+
+func (none) Redact(_, _ string, p *rinq.Payload) string {
+	return p.String()
+}
+
+// Suppress returns a Redactor that omits the payload entirely, rendering only
+// its size.
+func Suppress() Redactor {
+	return Func(func(_, _ string, p *rinq.Payload) string {
+		return fmt.Sprintf("<%d bytes redacted>", p.Len())
+	})
+}
+
+// Truncate returns a Redactor that renders payloads using r, and truncates the
+// result to at most n bytes.
+func Truncate(n int, r Redactor) Redactor {
+	return Func(func(ns, name string, p *rinq.Payload) string {
+		s := r.Redact(ns, name, p)
+
+		if len(s) <= n {
+			return s
+		}
+
+		return fmt.Sprintf("%s... <%d bytes truncated>", s[:n], len(s)-n)
+	})
+}
+
+// Lazy returns a fmt.Stringer that renders p using r only when it is
+// formatted. It is used to avoid the cost of redaction when a log entry is
+// not written.
+func Lazy(r Redactor, ns, name string, p *rinq.Payload) fmt.Stringer {
+	return lazy{r, ns, name, p}
+}
+
+type lazy struct {
+	redactor Redactor
+	ns       string
+	name     string
+	payload  *rinq.Payload
+}
+
+func (l lazy) String() string {
+	return l.redactor.Redact(l.ns, l.name, l.payload)
+}
@@ -0,0 +1,67 @@
+package redact_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/redact"
+)
+
+var _ = Describe("None", func() {
+	It("renders the payload in full", func() {
+		p := rinq.NewPayload(map[string]interface{}{"password": "hunter2"})
+		defer p.Close()
+
+		Expect(redact.None.Redact("ns", "cmd", p)).To(Equal(p.String()))
+	})
+})
+
+var _ = Describe("Suppress", func() {
+	It("renders only the payload size", func() {
+		p := rinq.NewPayload("hunter2")
+		defer p.Close()
+
+		s := redact.Suppress().Redact("ns", "cmd", p)
+
+		Expect(s).To(Equal(fmt.Sprintf("<%d bytes redacted>", p.Len())))
+	})
+})
+
+var _ = Describe("Truncate", func() {
+	It("renders short payloads in full", func() {
+		p := rinq.NewPayload("abc")
+		defer p.Close()
+
+		s := redact.Truncate(100, redact.None).Redact("ns", "cmd", p)
+
+		Expect(s).To(Equal(p.String()))
+	})
+
+	It("truncates long payloads", func() {
+		p := rinq.NewPayload("abcdefghij")
+		defer p.Close()
+
+		full := p.String()
+		s := redact.Truncate(3, redact.None).Redact("ns", "cmd", p)
+
+		Expect(s).To(Equal(fmt.Sprintf("%s... <%d bytes truncated>", full[:3], len(full)-3)))
+	})
+})
+
+var _ = Describe("Lazy", func() {
+	It("does not invoke the redactor until formatted", func() {
+		called := false
+		r := redact.Func(func(ns, name string, p *rinq.Payload) string {
+			called = true
+			return ns + "::" + name
+		})
+
+		s := redact.Lazy(r, "ns", "cmd", nil)
+		Expect(called).To(BeFalse())
+
+		Expect(fmt.Sprintf("%s", s)).To(Equal("ns::cmd"))
+		Expect(called).To(BeTrue())
+	})
+})
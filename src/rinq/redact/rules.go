@@ -0,0 +1,72 @@
+package redact
+
+import (
+	"sync"
+
+	"github.com/rinq/rinq-go/src/internal/namespaces"
+	"github.com/rinq/rinq-go/src/rinq"
+)
+
+// Rules is a Redactor that selects another redactor based on the namespace
+// and name of the command or notification.
+//
+// The most specific rule is used. A rule for a specific namespace and name
+// takes precedence over a rule for the whole namespace, which takes precedence
+// over the default.
+type Rules struct {
+	def Redactor
+
+	mutex sync.RWMutex
+	rules map[string]Redactor
+}
+
+// NewRules returns a new set of rules that uses def for any payload that does
+// not match a more specific rule.
+func NewRules(def Redactor) *Rules {
+	if def == nil {
+		def = None
+	}
+
+	return &Rules{
+		def:   def,
+		rules: map[string]Redactor{},
+	}
+}
+
+// Namespace sets the redactor to use for all payloads in the ns namespace.
+func (r *Rules) Namespace(ns string, red Redactor) *Rules {
+	namespaces.MustValidate(ns)
+	return r.set(ns, red)
+}
+
+// Name sets the redactor to use for payloads of the command or notification
+// with the given name in the ns namespace.
+func (r *Rules) Name(ns, name string, red Redactor) *Rules {
+	namespaces.MustValidate(ns)
+	return r.set(ns+"::"+name, red)
+}
+
+// Redact renders p using the most specific matching redactor.
+func (r *Rules) Redact(ns, name string, p *rinq.Payload) string {
+	r.mutex.RLock()
+	red, ok := r.rules[ns+"::"+name]
+	if !ok {
+		red, ok = r.rules[ns]
+	}
+	r.mutex.RUnlock()
+
+	if !ok {
+		red = r.def
+	}
+
+	return red.Redact(ns, name, p)
+}
+
+func (r *Rules) set(k string, red Redactor) *Rules {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.rules[k] = red
+
+	return r
+}
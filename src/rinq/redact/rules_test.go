@@ -0,0 +1,55 @@
+package redact_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/redact"
+)
+
+var _ = Describe("Rules", func() {
+	var (
+		rules   *redact.Rules
+		payload *rinq.Payload
+	)
+
+	constant := func(s string) redact.Redactor {
+		return redact.Func(func(string, string, *rinq.Payload) string {
+			return s
+		})
+	}
+
+	BeforeEach(func() {
+		payload = rinq.NewPayload("value")
+
+		rules = redact.NewRules(constant("default")).
+			Namespace("ns", constant("namespace")).
+			Name("ns", "cmd", constant("name"))
+	})
+
+	AfterEach(func() {
+		payload.Close()
+	})
+
+	It("uses the rule for the namespace and name when present", func() {
+		Expect(rules.Redact("ns", "cmd", payload)).To(Equal("name"))
+	})
+
+	It("falls back to the rule for the namespace", func() {
+		Expect(rules.Redact("ns", "other", payload)).To(Equal("namespace"))
+	})
+
+	It("falls back to the default", func() {
+		Expect(rules.Redact("other", "cmd", payload)).To(Equal("default"))
+	})
+
+	It("renders payloads in full when no default is given", func() {
+		Expect(redact.NewRules(nil).Redact("ns", "cmd", payload)).To(Equal(payload.String()))
+	})
+
+	It("panics if the namespace is invalid", func() {
+		Expect(func() {
+			rules.Namespace("_reserved", redact.None)
+		}).To(Panic())
+	})
+})
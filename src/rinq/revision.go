@@ -68,6 +68,40 @@ type Revision interface {
 	// If err is nil, t contains all of the attributes specified in k.
 	GetMany(ctx context.Context, ns string, k ...string) (t AttrTable, err error)
 
+	// GetAll returns all of the attributes within the ns namespace of the
+	// attribute table.
+	//
+	// The returned attributes are guaranteed to be correct as of Ref().Rev.
+	// Attributes that had been created by Ref().Rev are included even if their
+	// value is empty. Attributes created after Ref().Rev are omitted.
+	//
+	// For remote sessions, the entire namespace is fetched from the owning
+	// peer.
+	//
+	// If any of the attributes can not be retrieved because they have already
+	// been modified, ShouldRetry(err) returns true. To fetch the attribute
+	// values at the later revision, first call Refresh() then retry the
+	// GetAll() on the newer revision.
+	//
+	// If IsNotFound(err) returns true, the session has been destroyed and the
+	// revision can not be queried.
+	GetAll(ctx context.Context, ns string) (t AttrTable, err error)
+
+	// Namespaces returns the sorted names of the namespaces that contain at
+	// least one attribute with a non-empty value as of Ref().Rev.
+	//
+	// For remote sessions, the entire attribute table is fetched from the
+	// owning peer.
+	//
+	// If the namespaces can not be determined because attributes have already
+	// been modified, ShouldRetry(err) returns true. To fetch the namespaces at
+	// the later revision, first call Refresh() then retry the Namespaces() on
+	// the newer revision.
+	//
+	// If IsNotFound(err) returns true, the session has been destroyed and the
+	// revision can not be queried.
+	Namespaces(ctx context.Context) (ns []string, err error)
+
 	// Update atomically modifies a set of attributes within the ns namespace of
 	// the attribute table.
 	//
@@ -89,11 +123,48 @@ type Revision interface {
 	//
 	// If attrs is empty no update occurs, rev is this revision and err is nil.
 	//
+	// Attributes with a non-zero TTL are cleared automatically once the TTL
+	// elapses, producing another revision. See Attr.TTL.
+	//
 	// As a convenience, if the update fails for any reason, rev is this
 	// revision. This allows the caller to assign the return value to an
 	// existing variable without first checking for errors.
 	Update(ctx context.Context, ns string, attrs ...Attr) (rev Revision, err error)
 
+	// UpdateIf atomically modifies a set of attributes within the ns namespace
+	// of the attribute table, provided that the attributes described by conds
+	// currently have the expected values.
+	//
+	// Unlike Update(), this revision does not need to be the latest revision.
+	// Instead, the conditions are evaluated against the latest revision at the
+	// time of the update, so concurrent changes to unrelated attributes do not
+	// cause the update to fail. At least one condition must be given.
+	//
+	// The following conditions must be met for an update to succeed:
+	//
+	// 1. Every condition in conds must be satisfied by the latest revision. If
+	//    any condition is not satisfied the update fails with a
+	//    ConditionFailedError and ShouldRetry(err) returns false.
+	//
+	// 2. All attribute changes must reference non-frozen attributes. If any of
+	//    attributes being updated are already frozen the update fails and
+	//    ShouldRetry(err) returns false.
+	//
+	// On success, rev is the newly created revision. As with Update(), if the
+	// update fails for any reason, rev is this revision.
+	UpdateIf(ctx context.Context, ns string, conds []Condition, attrs ...Attr) (rev Revision, err error)
+
+	// UpdateMany atomically modifies attributes in several namespaces of the
+	// attribute table. attrs maps each namespace to the attributes to modify
+	// within that namespace.
+	//
+	// All of the changes produce a single new revision; either all of the
+	// attributes are updated, or the attribute table remains unchanged.
+	// Otherwise, the semantics are the same as for Update().
+	//
+	// If attrs is empty no update occurs, rev is this revision and err is nil.
+	UpdateMany(ctx context.Context, attrs map[string][]Attr) (rev Revision, err error)
+
 	// Clear is an update operation that atomically sets the value of each
 	// attribute within the ns namespace to the empty string.
 	//
@@ -111,10 +182,37 @@ type Revision interface {
 	// revision. If Ref().Rev is not the latest revision the destroy fails;
 	// ShouldRetry(err) returns true.
 	Destroy(ctx context.Context) (err error)
+
+	// Watch returns a channel that receives a Change each time attributes in
+	// the ns namespace are modified after this revision. If keys are given,
+	// only changes to those attributes are delivered.
+	//
+	// If ns is empty, changes to every namespace are delivered, with a
+	// separate Change for each namespace modified by a revision. Keys, if
+	// given, apply to every namespace.
+	//
+	// Any changes made between this revision and the session's current
+	// revision are delivered first, as a single change per namespace.
+	//
+	// The channel is closed when ctx is canceled, or after the session is
+	// destroyed, in which case the last change has IsDestroyed set. Changes
+	// are queued for delivery, so a slow receiver never blocks updates to the
+	// session.
+	//
+	// If the session is migrated to another peer the channel is closed
+	// without a destroyed change. Call Refresh() and Watch() again to
+	// continue watching the session at its new location.
+	//
+	// For remote sessions, changes are pushed to this peer by the owning
+	// peer. ctx is also used as the context for the initial request.
+	//
+	// If IsNotFound(err) returns true, the session has been destroyed and can
+	// not be watched.
+	Watch(ctx context.Context, ns string, keys ...string) (<-chan Change, error)
 }
 
-// ShouldRetry returns true if a call to Revision.Get(), GetMany(), Update() or
-// Destroy() failed because the revision is out of date.
+// ShouldRetry returns true if a call to Revision.Get(), GetMany(), GetAll(),
+// Update() or Destroy() failed because the revision is out of date.
 //
 // The operation should be retried on the latest revision of the session,
 // which can be retrieved with Revision.Refresh().
@@ -153,6 +251,21 @@ func (err StaleUpdateError) Error() string {
 	)
 }
 
+// ConditionFailedError indicates a failure to perform a conditional update
+// because an attribute does not have the expected value.
+type ConditionFailedError struct {
+	Ref       ident.Ref
+	Condition Condition
+}
+
+func (err ConditionFailedError) Error() string {
+	return fmt.Sprintf(
+		"can not update %s, the condition '%s' is not satisfied",
+		err.Ref,
+		err.Condition,
+	)
+}
+
 // FrozenAttributesError indicates a failure to update a session because at least
 // one of the attributes being updated is frozen.
 type FrozenAttributesError struct {
@@ -30,6 +30,11 @@ var _ = Describe("Revision", func() {
 			Expect(r).To(BeTrue())
 		})
 
+		It("returns false for ConditionFailedError", func() {
+			r := rinq.ShouldRetry(rinq.ConditionFailedError{})
+			Expect(r).To(BeFalse())
+		})
+
 		It("returns false for other error types", func() {
 			r := rinq.ShouldRetry(rinq.FrozenAttributesError{})
 			Expect(r).To(BeFalse())
@@ -68,4 +73,18 @@ var _ = Describe("Revision", func() {
 			})
 		})
 	})
+
+	Describe("ConditionFailedError", func() {
+		Describe("Error", func() {
+			It("returns the message", func() {
+				err := rinq.ConditionFailedError{
+					Ref:       sessionRef,
+					Condition: rinq.Expect("a", "1"),
+				}
+				Expect(err.Error()).To(Equal(
+					"can not update 1-0002.3@4, the condition 'a==1' is not satisfied",
+				))
+			})
+		})
+	})
 })
@@ -154,6 +154,13 @@ type Session interface {
 	// returned immediately.
 	Unlisten(ns string) error
 
+	// SetExpiry replaces the session's expiry, which determines when it is
+	// destroyed automatically. The TTL is measured from the time SetExpiry()
+	// is called. A zero Expiry means the session never expires.
+	//
+	// If IsNotFound(err) returns true, the session has already been destroyed.
+	SetExpiry(e Expiry) error
+
 	// Destroy terminates the session.
 	//
 	// Destroy does NOT block until the session is destroyed, use the
@@ -164,7 +171,8 @@ type Session interface {
 	// any pending Session.Call() operations have completed.
 	//
 	// The session may be destroyed directly with Destroy(), or via a Revision
-	// that refers to this session, either locally or remotely.
+	// that refers to this session, either locally or remotely. It is also
+	// destroyed when it expires, see SetExpiry().
 	//
 	// All sessions are destroyed when their owning peer is stopped.
 	Done() <-chan struct{}
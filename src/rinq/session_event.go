@@ -0,0 +1,111 @@
+package rinq
+
+import (
+	"github.com/rinq/rinq-go/src/internal/x/bufferpool"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// SessionEventType is an enumeration of the kinds of change described by a
+// SessionEvent.
+type SessionEventType int
+
+const (
+	// SessionCreated indicates that a session was created. Sessions that are
+	// migrated to a peer are reported as created, as they are assigned a new
+	// ID; the event's source is the peer from which the session migrated.
+	// Sessions restored from the session store are not reported.
+	SessionCreated SessionEventType = iota
+
+	// SessionUpdated indicates that attributes in a single namespace were
+	// updated.
+	SessionUpdated
+
+	// SessionCleared indicates that the attributes in a single namespace were
+	// cleared.
+	SessionCleared
+
+	// SessionDestroyed indicates that a session was destroyed, either
+	// explicitly or because it expired.
+	SessionDestroyed
+)
+
+func (t SessionEventType) String() string {
+	switch t {
+	case SessionCreated:
+		return "created"
+	case SessionUpdated:
+		return "updated"
+	case SessionCleared:
+		return "cleared"
+	case SessionDestroyed:
+		return "destroyed"
+	default:
+		return "unknown"
+	}
+}
+
+// SessionEvent describes a change to a session owned by a peer, as delivered
+// by Peer.SessionEvents().
+type SessionEvent struct {
+	// Type is the kind of change that occurred.
+	Type SessionEventType
+
+	// Ref refers to the session revision produced by the change. For
+	// SessionDestroyed events it is the last revision of the session.
+	Ref ident.Ref
+
+	// Namespace is the namespace that contains the changed attributes. It is
+	// empty for SessionCreated and SessionDestroyed events.
+	Namespace string
+
+	// Attrs contains the new state of each attribute that was changed, sorted
+	// by key. It is empty for SessionCreated and SessionDestroyed events.
+	Attrs []Attr
+
+	// Source identifies the origin of the change.
+	Source SessionEventSource
+}
+
+// String returns a representation of the event, such as
+// "58AEE146-191C.45@3 updated ns::{a=1, b@2}".
+func (e SessionEvent) String() string {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+
+	buf.WriteString(e.Ref.String())
+	buf.WriteRune(' ')
+	buf.WriteString(e.Type.String())
+
+	if e.Namespace == "" {
+		return buf.String()
+	}
+
+	buf.WriteRune(' ')
+	buf.WriteString(e.Namespace)
+	buf.WriteString("::{")
+
+	for n, attr := range e.Attrs {
+		if n != 0 {
+			buf.WriteString(", ")
+		}
+
+		buf.WriteString(attr.String())
+	}
+
+	buf.WriteRune('}')
+
+	return buf.String()
+}
+
+// SessionEventSource identifies the origin of a SessionEvent.
+type SessionEventSource struct {
+	// Peer is the ID of the peer that made the change. Changes made via
+	// revisions held by other peers carry the ID of that peer; all other
+	// events, including expiry, carry the ID of the owning peer.
+	Peer ident.PeerID
+
+	// TraceID is the trace ID of the operation that made the change, if any.
+	// It is empty for events that are not caused by an operation, such as
+	// expiry.
+	TraceID string
+}
@@ -0,0 +1,46 @@
+package rinq_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+var _ = Describe("SessionEvent", func() {
+	var sessionRef = ident.Ref{
+		ID: ident.SessionID{
+			Peer: ident.PeerID{
+				Clock: 1,
+				Rand:  2,
+			},
+			Seq: 3,
+		},
+		Rev: 4,
+	}
+
+	Describe("String", func() {
+		It("includes the type, namespace and attributes", func() {
+			e := rinq.SessionEvent{
+				Type:      rinq.SessionUpdated,
+				Ref:       sessionRef,
+				Namespace: "ns",
+				Attrs: []rinq.Attr{
+					rinq.Set("a", "1"),
+					rinq.Freeze("b", "2"),
+				},
+			}
+
+			Expect(e.String()).To(Equal("1-0002.3@4 updated ns::{a=1, b@2}"))
+		})
+
+		It("omits the namespace for events that do not have one", func() {
+			e := rinq.SessionEvent{
+				Type: rinq.SessionDestroyed,
+				Ref:  sessionRef,
+			}
+
+			Expect(e.String()).To(Equal("1-0002.3@4 destroyed"))
+		})
+	})
+})
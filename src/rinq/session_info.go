@@ -0,0 +1,57 @@
+package rinq
+
+import (
+	"sort"
+
+	"github.com/rinq/rinq-go/src/internal/x/bufferpool"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// SessionInfo contains the state of a session, as returned by
+// Peer.InspectSession().
+type SessionInfo struct {
+	// Ref refers to the session's revision at the time the information was
+	// produced.
+	Ref ident.Ref
+
+	// Attrs contains the session's attributes, keyed by namespace. The
+	// attributes within each namespace are sorted by key.
+	Attrs map[string][]Attr
+}
+
+// Namespaces returns the sorted list of namespaces that contain attributes.
+func (i SessionInfo) Namespaces() []string {
+	namespaces := make([]string, 0, len(i.Attrs))
+
+	for ns, attrs := range i.Attrs {
+		if len(attrs) != 0 {
+			namespaces = append(namespaces, ns)
+		}
+	}
+
+	sort.Strings(namespaces)
+
+	return namespaces
+}
+
+// String returns a representation of the attributes in the ns namespace, in
+// the same notation as Attr.String().
+func (i SessionInfo) String(ns string) string {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+
+	buf.WriteString(ns)
+	buf.WriteString("::{")
+
+	for n, attr := range i.Attrs[ns] {
+		if n != 0 {
+			buf.WriteString(", ")
+		}
+
+		buf.WriteString(attr.String())
+	}
+
+	buf.WriteRune('}')
+
+	return buf.String()
+}
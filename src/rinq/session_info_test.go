@@ -0,0 +1,34 @@
+package rinq_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+)
+
+var _ = Describe("SessionInfo", func() {
+	info := rinq.SessionInfo{
+		Attrs: map[string][]rinq.Attr{
+			"ns2": {rinq.Set("a", "1"), rinq.Freeze("b", "2")},
+			"ns1": {rinq.Set("c", "")},
+			"ns3": {},
+		},
+	}
+
+	Describe("Namespaces", func() {
+		It("returns the sorted namespaces that contain attributes", func() {
+			Expect(info.Namespaces()).To(Equal([]string{"ns1", "ns2"}))
+		})
+	})
+
+	Describe("String", func() {
+		It("uses the same notation as Attr.String()", func() {
+			Expect(info.String("ns2")).To(Equal("ns2::{a=1, b@2}"))
+			Expect(info.String("ns1")).To(Equal("ns1::{-c}"))
+		})
+
+		It("returns empty braces if there are no attributes in the namespace", func() {
+			Expect(info.String("ns3")).To(Equal("ns3::{}"))
+		})
+	})
+})
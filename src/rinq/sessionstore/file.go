@@ -0,0 +1,253 @@
+package sessionstore
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// File is a Store that persists sessions to an append-only log file.
+//
+// Each change to a session is appended to the log as a single line of JSON.
+// The log is replayed when it is opened, and compacted so that it contains
+// only a single entry for each session. It is also compacted automatically
+// once it contains many more entries than there are sessions.
+//
+// Entries are written to the operating system as each change is made, so they
+// survive a crash of the process, but they are not synced to disk.
+type File struct {
+	mutex    sync.Mutex
+	path     string
+	file     *os.File
+	writer   *bufio.Writer
+	sessions map[ident.SessionID]Session
+	entries  int // number of entries in the log
+}
+
+// compactThreshold is the minimum number of entries in the log before it is
+// compacted automatically.
+const compactThreshold = 1024
+
+// fileEntry is a single entry in the log.
+type fileEntry struct {
+	Session string            `json:"session"`
+	Rev     ident.Revision    `json:"rev,omitempty"`
+	Attrs   map[string][]Attr `json:"attrs,omitempty"`
+	Expires int64             `json:"expires,omitempty"`
+	Idle    time.Duration     `json:"idle,omitempty"`
+	Deleted bool              `json:"deleted,omitempty"`
+}
+
+// newFileEntry returns the log entry that records the state of s.
+func newFileEntry(s Session) fileEntry {
+	return fileEntry{
+		Session: s.ID.String(),
+		Rev:     s.Rev,
+		Attrs:   s.Attrs,
+		Expires: s.ExpiresAt,
+		Idle:    s.IdleTimeout,
+	}
+}
+
+// OpenFile opens the log file at path, creating it if necessary.
+func OpenFile(path string) (*File, error) {
+	f := &File{
+		path:     path,
+		sessions: map[ident.SessionID]Session{},
+	}
+
+	if err := f.replay(); err != nil {
+		return nil, err
+	}
+
+	if err := f.compact(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Save persists the state of a session.
+func (f *File) Save(s Session) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	err := f.append(newFileEntry(s))
+	if err != nil {
+		return err
+	}
+
+	f.sessions[s.ID] = s
+	return f.maybeCompact()
+}
+
+// Delete removes the persisted state of the session with the given ID.
+func (f *File) Delete(id ident.SessionID) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if _, ok := f.sessions[id]; !ok {
+		return nil
+	}
+
+	err := f.append(fileEntry{
+		Session: id.String(),
+		Deleted: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	delete(f.sessions, id)
+	return f.maybeCompact()
+}
+
+// Load returns the persisted state of each session owned by the given peer.
+func (f *File) Load(peerID ident.PeerID) ([]Session, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return filter(f.sessions, peerID), nil
+}
+
+// Close closes the log file.
+func (f *File) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+	f.writer = nil
+
+	return err
+}
+
+// replay reads the existing log, if any, into f.sessions.
+//
+// An incomplete entry at the end of the log, as left by a crash while writing,
+// is ignored.
+func (f *File) replay() error {
+	file, err := os.Open(f.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	dec := json.NewDecoder(file)
+
+	for {
+		var e fileEntry
+
+		err := dec.Decode(&e)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("can not read session log %s: %s", f.path, err)
+		}
+
+		id, err := ident.ParseSessionID(e.Session)
+		if err != nil {
+			return fmt.Errorf("can not read session log %s: %s", f.path, err)
+		}
+
+		if e.Deleted {
+			delete(f.sessions, id)
+		} else {
+			f.sessions[id] = Session{
+				ID:          id,
+				Rev:         e.Rev,
+				Attrs:       e.Attrs,
+				ExpiresAt:   e.Expires,
+				IdleTimeout: e.Idle,
+			}
+		}
+	}
+}
+
+// append writes e to the end of the log.
+func (f *File) append(e fileEntry) error {
+	if f.file == nil {
+		return errors.New("session log is closed")
+	}
+
+	buf, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	buf = append(buf, '\n')
+
+	if _, err := f.writer.Write(buf); err != nil {
+		return err
+	}
+
+	f.entries++
+	return f.writer.Flush()
+}
+
+// maybeCompact compacts the log if it contains many more entries than there
+// are sessions.
+func (f *File) maybeCompact() error {
+	if f.entries < compactThreshold || f.entries < 4*len(f.sessions) {
+		return nil
+	}
+
+	return f.compact()
+}
+
+// compact atomically replaces the log with one that contains a single entry
+// for each session, and opens it for appending.
+func (f *File) compact() error {
+	tmp := f.path + ".tmp"
+
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	enc := json.NewEncoder(writer)
+
+	for _, s := range f.sessions {
+		if err == nil {
+			err = enc.Encode(newFileEntry(s))
+		}
+	}
+
+	if err == nil {
+		err = writer.Flush()
+	}
+
+	if err == nil {
+		err = os.Rename(tmp, f.path)
+	}
+
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	if f.file != nil {
+		_ = f.file.Close()
+	}
+
+	f.file = file
+	f.writer = writer
+	f.entries = len(f.sessions)
+
+	return nil
+}
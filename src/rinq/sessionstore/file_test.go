@@ -0,0 +1,126 @@
+package sessionstore_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/sessionstore"
+)
+
+var _ = Describe("File", func() {
+	var (
+		dir, path string
+		subject   *sessionstore.File
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "rinq-sessionstore-")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		path = filepath.Join(dir, "sessions.log")
+
+		subject, err = sessionstore.OpenFile(path)
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_ = subject.Close()
+		_ = os.RemoveAll(dir)
+	})
+
+	reopen := func() {
+		Expect(subject.Close()).To(Succeed())
+
+		var err error
+		subject, err = sessionstore.OpenFile(path)
+		Expect(err).ShouldNot(HaveOccurred())
+	}
+
+	It("restores saved sessions when the file is reopened", func() {
+		Expect(subject.Save(sessionState(peerA.Session(1), 1))).To(Succeed())
+		Expect(subject.Save(sessionState(peerA.Session(1), 2))).To(Succeed())
+		Expect(subject.Save(sessionState(peerA.Session(2), 1))).To(Succeed())
+		Expect(subject.Save(sessionState(peerB.Session(1), 1))).To(Succeed())
+		Expect(subject.Delete(peerA.Session(2))).To(Succeed())
+
+		reopen()
+
+		sessions, err := subject.Load(peerA)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sessions).To(Equal([]sessionstore.Session{
+			sessionState(peerA.Session(1), 2),
+		}))
+	})
+
+	It("restores the session expiry", func() {
+		s := sessionState(peerA.Session(1), 1)
+		s.ExpiresAt = 1234567890
+		s.IdleTimeout = 5 * time.Second
+
+		Expect(subject.Save(s)).To(Succeed())
+
+		reopen()
+
+		sessions, err := subject.Load(peerA)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sessions).To(Equal([]sessionstore.Session{s}))
+	})
+
+	It("compacts the log when it is opened", func() {
+		for rev := ident.Revision(1); rev <= 10; rev++ {
+			Expect(subject.Save(sessionState(peerA.Session(1), rev))).To(Succeed())
+		}
+
+		reopen()
+
+		buf, err := os.ReadFile(path)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(bytes.Count(buf, []byte("\n"))).To(Equal(1))
+	})
+
+	It("ignores an incomplete entry at the end of the log", func() {
+		Expect(subject.Save(sessionState(peerA.Session(1), 1))).To(Succeed())
+		Expect(subject.Close()).To(Succeed())
+
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = f.WriteString(`{"session":"1-0002.2","rev":`)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(f.Close()).To(Succeed())
+
+		subject, err = sessionstore.OpenFile(path)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		sessions, err := subject.Load(peerA)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sessions).To(Equal([]sessionstore.Session{
+			sessionState(peerA.Session(1), 1),
+		}))
+	})
+
+	It("returns an error if the log is corrupt", func() {
+		Expect(subject.Close()).To(Succeed())
+		Expect(os.WriteFile(path, []byte("<corrupt>\n"), 0600)).To(Succeed())
+
+		_, err := sessionstore.OpenFile(path)
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns an error when saving to a closed file", func() {
+		Expect(subject.Close()).To(Succeed())
+
+		err := subject.Save(sessionState(peerA.Session(1), 1))
+
+		Expect(err).To(MatchError("session log is closed"))
+	})
+})
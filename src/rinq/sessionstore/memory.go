@@ -0,0 +1,69 @@
+package sessionstore
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// Memory is a Store that keeps sessions in memory.
+//
+// Sessions persisted to a Memory store outlive the peer, but not the process.
+// It is useful for restoring sessions when a peer reconnects to the network,
+// and in tests.
+type Memory struct {
+	mutex    sync.RWMutex
+	sessions map[ident.SessionID]Session
+}
+
+// NewMemory returns a new, empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{
+		sessions: map[ident.SessionID]Session{},
+	}
+}
+
+// Save persists the state of a session.
+func (m *Memory) Save(s Session) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.sessions[s.ID] = s
+	return nil
+}
+
+// Delete removes the persisted state of the session with the given ID.
+func (m *Memory) Delete(id ident.SessionID) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.sessions, id)
+	return nil
+}
+
+// Load returns the persisted state of each session owned by the given peer.
+func (m *Memory) Load(peerID ident.PeerID) ([]Session, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return filter(m.sessions, peerID), nil
+}
+
+// filter returns the sessions in m that are owned by peerID, ordered by
+// session ID.
+func filter(m map[ident.SessionID]Session, peerID ident.PeerID) []Session {
+	var sessions []Session
+
+	for id, s := range m {
+		if id.Peer == peerID {
+			sessions = append(sessions, s)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].ID.Seq < sessions[j].ID.Seq
+	})
+
+	return sessions
+}
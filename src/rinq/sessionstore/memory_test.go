@@ -0,0 +1,60 @@
+package sessionstore_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/sessionstore"
+)
+
+var (
+	peerA = ident.PeerID{Clock: 1, Rand: 2}
+	peerB = ident.PeerID{Clock: 3, Rand: 4}
+)
+
+// sessionState returns a session with a single attribute, for use in tests.
+func sessionState(id ident.SessionID, rev ident.Revision) sessionstore.Session {
+	return sessionstore.Session{
+		ID:  id,
+		Rev: rev,
+		Attrs: map[string][]sessionstore.Attr{
+			"ns": {
+				{Attr: rinq.Set("a", "1"), CreatedAt: 1, UpdatedAt: rev},
+			},
+		},
+	}
+}
+
+var _ = Describe("Memory", func() {
+	var subject *sessionstore.Memory
+
+	BeforeEach(func() {
+		subject = sessionstore.NewMemory()
+	})
+
+	It("returns the saved sessions owned by the peer, in order", func() {
+		Expect(subject.Save(sessionState(peerA.Session(2), 1))).To(Succeed())
+		Expect(subject.Save(sessionState(peerA.Session(1), 1))).To(Succeed())
+		Expect(subject.Save(sessionState(peerB.Session(1), 1))).To(Succeed())
+		Expect(subject.Save(sessionState(peerA.Session(1), 2))).To(Succeed())
+
+		sessions, err := subject.Load(peerA)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sessions).To(Equal([]sessionstore.Session{
+			sessionState(peerA.Session(1), 2),
+			sessionState(peerA.Session(2), 1),
+		}))
+	})
+
+	It("does not return deleted sessions", func() {
+		Expect(subject.Save(sessionState(peerA.Session(1), 1))).To(Succeed())
+		Expect(subject.Delete(peerA.Session(1))).To(Succeed())
+
+		sessions, err := subject.Load(peerA)
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sessions).To(BeEmpty())
+	})
+})
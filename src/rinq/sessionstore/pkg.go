@@ -0,0 +1,8 @@
+// Package sessionstore defines how the attributes of local sessions are
+// persisted, so that sessions can be restored when a peer restarts.
+//
+// By default sessions are kept only in memory, and are lost when the peer
+// stops. Use options.SessionStore() to persist sessions to a Store, and set
+// rinqamqp.Dialer.RestoreSessions to recreate them when a peer with the same
+// pinned peer ID connects to the network.
+package sessionstore
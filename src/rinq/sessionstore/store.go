@@ -0,0 +1,68 @@
+package sessionstore
+
+import (
+	"time"
+
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+)
+
+// Store persists the state of the sessions owned by a peer.
+//
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Save persists the state of a session, replacing any state previously
+	// saved for the same session.
+	Save(s Session) error
+
+	// Delete removes the persisted state of the session with the given ID.
+	// It is not an error to delete a session that has not been saved.
+	Delete(id ident.SessionID) error
+
+	// Load returns the persisted state of each session owned by the given
+	// peer, ordered by session ID.
+	Load(peerID ident.PeerID) ([]Session, error)
+}
+
+// Session is the persisted state of a session.
+type Session struct {
+	// ID is the session's ID.
+	ID ident.SessionID
+
+	// Rev is the session's current revision.
+	Rev ident.Revision
+
+	// Attrs contains the session's attributes, keyed by namespace.
+	Attrs map[string][]Attr
+
+	// ExpiresAt is the time at which the session's TTL elapses, as a Unix
+	// time in nanoseconds. It is zero if the session has no TTL.
+	ExpiresAt int64
+
+	// IdleTimeout is the session's idle timeout. It is zero if the session
+	// never becomes idle.
+	IdleTimeout time.Duration
+}
+
+// Attr is a session attribute along with the revisions at which it was created
+// and last updated.
+type Attr struct {
+	rinq.Attr
+
+	CreatedAt ident.Revision `json:"cr,omitempty"`
+	UpdatedAt ident.Revision `json:"ur,omitempty"`
+
+	// ExpiresAt is the time at which the attribute expires, as a Unix time in
+	// nanoseconds. It is zero if the attribute does not expire.
+	ExpiresAt int64 `json:"x,omitempty"`
+}
+
+// None is a Store that does not persist sessions. It is the default store,
+// sessions are kept only in memory and can not be restored.
+var None Store = none{}
+
+type none struct{}
+
+func (none) Save(Session) error                   { return nil }
+func (none) Delete(ident.SessionID) error         { return nil }
+func (none) Load(ident.PeerID) ([]Session, error) { return nil, nil }
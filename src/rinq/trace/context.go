@@ -29,21 +29,36 @@ func With(parent context.Context, t string) context.Context {
 // If parent already contains a trace ID, ctx is parent, id is the trace ID from
 // parent and ok is false. Otherwise, ctx is the derived context containing  t
 // as the trace ID, id is t and ok is true.
+//
+// A W3C traceparent added by WithTraceParent() is considered to be an existing
+// trace ID.
 func WithRoot(parent context.Context, t string) (ctx context.Context, id string, ok bool) {
-	existing := parent.Value(key)
+	if existing := parent.Value(key); existing != nil {
+		return parent, existing.(string), false
+	}
 
-	if existing == nil {
-		return With(parent, t), t, true
+	if tp, ok := GetTraceParent(parent); ok {
+		return parent, tp.TraceIDString(), false
 	}
 
-	return parent, existing.(string), false
+	return With(parent, t), t, true
 }
 
 // Get returns the trace identifier from ctx, or an empty string if none is
 // present.
+//
+// If ctx does not contain a trace ID added by With(), but does contain a W3C
+// traceparent, the hex-encoded W3C trace ID is returned.
 func Get(ctx context.Context) string {
-	str, _ := ctx.Value(key).(string)
-	return str
+	if str, ok := ctx.Value(key).(string); ok {
+		return str
+	}
+
+	if tp, ok := GetTraceParent(ctx); ok {
+		return tp.TraceIDString()
+	}
+
+	return ""
 }
 
 type keyType struct{}
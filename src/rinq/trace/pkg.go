@@ -1,2 +1,3 @@
-// Package trace provides functions for configuring custom trace identifiers.
+// Package trace provides functions for configuring custom trace identifiers,
+// including interoperability with W3C Trace Context.
 package trace
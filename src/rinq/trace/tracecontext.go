@@ -0,0 +1,159 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// TraceParent is a W3C Trace Context "traceparent" value.
+//
+// When a context containing a TraceParent is used to perform an operation, the
+// traceparent (and tracestate, if present) is sent to the receiving peer and is
+// present in the ctx supplied to command and notification handlers. This allows
+// Rinq operations to participate in traces that span other systems, such as
+// HTTP services.
+//
+// Rinq does not record spans of its own in the W3C trace, and so forwards the
+// traceparent unchanged, as per the specification's requirements for
+// intermediaries.
+//
+// See https://www.w3.org/TR/trace-context/ for more information.
+type TraceParent struct {
+	// TraceID is the ID of the whole trace.
+	TraceID [16]byte
+
+	// ParentID is the ID of the caller's span.
+	ParentID [8]byte
+
+	// Flags is the set of trace flags, such as FlagSampled.
+	Flags byte
+}
+
+// FlagSampled is the trace flag that indicates that the caller may have
+// recorded trace data.
+const FlagSampled byte = 0x01
+
+// NewTraceParent returns a new traceparent with a random trace ID and parent
+// ID, and the sampled flag set.
+func NewTraceParent() TraceParent {
+	tp := TraceParent{Flags: FlagSampled}
+
+	if _, err := rand.Read(tp.TraceID[:]); err != nil {
+		panic(err)
+	}
+
+	if _, err := rand.Read(tp.ParentID[:]); err != nil {
+		panic(err)
+	}
+
+	return tp
+}
+
+// ParseTraceParent parses a traceparent from its string representation, such
+// as the value of a "traceparent" HTTP header.
+func ParseTraceParent(s string) (tp TraceParent, err error) {
+	// version "-" trace-id "-" parent-id "-" trace-flags
+	const length = 2 + 1 + 32 + 1 + 16 + 1 + 2
+
+	if len(s) < length || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return tp, fmt.Errorf("traceparent '%s' is malformed", s)
+	}
+
+	var version [1]byte
+	if err := decodeHex(version[:], s[0:2]); err != nil || version[0] == 0xff {
+		return tp, fmt.Errorf("traceparent '%s' has an invalid version", s)
+	}
+
+	// version 00 has a fixed length, future versions may append fields.
+	if (version[0] == 0 && len(s) != length) || (len(s) > length && s[length] != '-') {
+		return tp, fmt.Errorf("traceparent '%s' is malformed", s)
+	}
+
+	if err := decodeHex(tp.TraceID[:], s[3:35]); err != nil || tp.TraceID == [16]byte{} {
+		return tp, fmt.Errorf("traceparent '%s' has an invalid trace ID", s)
+	}
+
+	if err := decodeHex(tp.ParentID[:], s[36:52]); err != nil || tp.ParentID == [8]byte{} {
+		return tp, fmt.Errorf("traceparent '%s' has an invalid parent ID", s)
+	}
+
+	var flags [1]byte
+	if err := decodeHex(flags[:], s[53:55]); err != nil {
+		return tp, fmt.Errorf("traceparent '%s' has invalid trace flags", s)
+	}
+	tp.Flags = flags[0]
+
+	return tp, nil
+}
+
+// IsSampled returns true if the sampled flag is set.
+func (tp TraceParent) IsSampled() bool {
+	return tp.Flags&FlagSampled != 0
+}
+
+// TraceIDString returns the hex-encoded trace ID.
+func (tp TraceParent) TraceIDString() string {
+	return hex.EncodeToString(tp.TraceID[:])
+}
+
+// String returns the traceparent representation, using version 00.
+func (tp TraceParent) String() string {
+	return fmt.Sprintf(
+		"00-%s-%s-%02x",
+		hex.EncodeToString(tp.TraceID[:]),
+		hex.EncodeToString(tp.ParentID[:]),
+		tp.Flags,
+	)
+}
+
+// WithTraceParent returns a new context derived from parent that includes tp as
+// its W3C traceparent.
+//
+// If the context does not also contain a trace ID added by With(), the
+// hex-encoded W3C trace ID is used as the trace ID. See Get() for more
+// information.
+func WithTraceParent(parent context.Context, tp TraceParent) context.Context {
+	return context.WithValue(parent, traceParentKey, tp)
+}
+
+// GetTraceParent returns the W3C traceparent from ctx. ok is false if none is
+// present.
+func GetTraceParent(ctx context.Context) (tp TraceParent, ok bool) {
+	tp, ok = ctx.Value(traceParentKey).(TraceParent)
+	return
+}
+
+// WithTraceState returns a new context derived from parent that includes s as
+// its W3C tracestate. The tracestate is only sent to other peers if the context
+// also contains a traceparent.
+func WithTraceState(parent context.Context, s string) context.Context {
+	return context.WithValue(parent, traceStateKey, s)
+}
+
+// GetTraceState returns the W3C tracestate from ctx, or an empty string if none
+// is present.
+func GetTraceState(ctx context.Context) string {
+	s, _ := ctx.Value(traceStateKey).(string)
+	return s
+}
+
+// decodeHex decodes the lower-case hex string s into buf.
+func decodeHex(buf []byte, s string) error {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= 'A' && c <= 'F' {
+			return errors.New("hex digits must be lower-case")
+		}
+	}
+
+	_, err := hex.Decode(buf, []byte(s))
+	return err
+}
+
+type traceParentKeyType struct{}
+type traceStateKeyType struct{}
+
+var traceParentKey traceParentKeyType
+var traceStateKey traceStateKeyType
@@ -0,0 +1,109 @@
+package trace_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/rinq/rinq-go/src/rinq/trace"
+)
+
+var _ = Describe("TraceParent", func() {
+	const valid = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	Describe("ParseTraceParent", func() {
+		It("parses a valid traceparent", func() {
+			tp, err := ParseTraceParent(valid)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(tp.TraceIDString()).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+			Expect(tp.ParentID).To(Equal([8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}))
+			Expect(tp.IsSampled()).To(BeTrue())
+			Expect(tp.String()).To(Equal(valid))
+		})
+
+		It("accepts additional fields in future versions", func() {
+			tp, err := ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future")
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(tp.IsSampled()).To(BeFalse())
+		})
+
+		DescribeTable(
+			"returns an error if the traceparent is invalid",
+			func(s string) {
+				_, err := ParseTraceParent(s)
+				Expect(err).Should(HaveOccurred())
+			},
+			Entry("empty", ""),
+			Entry("too short", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0"),
+			Entry("too long for version 00", valid+"-00"),
+			Entry("invalid version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"),
+			Entry("upper-case hex", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"),
+			Entry("zero trace ID", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"),
+			Entry("zero parent ID", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"),
+			Entry("non-hex flags", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-zz"),
+			Entry("wrong separators", "00_4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7_01"),
+		)
+	})
+
+	Describe("NewTraceParent", func() {
+		It("returns a valid, sampled traceparent", func() {
+			tp := NewTraceParent()
+
+			Expect(tp.IsSampled()).To(BeTrue())
+
+			p, err := ParseTraceParent(tp.String())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(p).To(Equal(tp))
+		})
+
+		It("returns a different traceparent each time", func() {
+			Expect(NewTraceParent()).NotTo(Equal(NewTraceParent()))
+		})
+	})
+
+	Describe("WithTraceParent", func() {
+		It("adds the traceparent", func() {
+			tp := NewTraceParent()
+			ctx := WithTraceParent(context.Background(), tp)
+
+			p, ok := GetTraceParent(ctx)
+			Expect(ok).To(BeTrue())
+			Expect(p).To(Equal(tp))
+		})
+
+		It("causes Get() to return the W3C trace ID", func() {
+			tp, _ := ParseTraceParent(valid)
+			ctx := WithTraceParent(context.Background(), tp)
+
+			Expect(Get(ctx)).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+		})
+
+		It("does not override an explicit trace ID", func() {
+			tp, _ := ParseTraceParent(valid)
+			ctx := WithTraceParent(With(context.Background(), "<id>"), tp)
+
+			Expect(Get(ctx)).To(Equal("<id>"))
+		})
+
+		It("is treated as an existing trace ID by WithRoot()", func() {
+			tp, _ := ParseTraceParent(valid)
+			parent := WithTraceParent(context.Background(), tp)
+			ctx, id, added := WithRoot(parent, "<id>")
+
+			Expect(ctx).To(BeIdenticalTo(parent))
+			Expect(id).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+			Expect(added).To(BeFalse())
+		})
+	})
+
+	Describe("WithTraceState", func() {
+		It("adds the tracestate", func() {
+			ctx := WithTraceState(context.Background(), "vendor=value")
+
+			Expect(GetTraceState(ctx)).To(Equal("vendor=value"))
+		})
+	})
+})
@@ -2,6 +2,7 @@ package rinqamqp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -9,14 +10,16 @@ import (
 	"time"
 
 	version "github.com/hashicorp/go-version"
-	"github.com/jmalloc/twelf/src/twelf"
+	"github.com/rinq/rinq-go/src/internal/introspection"
 	"github.com/rinq/rinq-go/src/internal/localsession"
 	"github.com/rinq/rinq-go/src/internal/remotesession"
 	"github.com/rinq/rinq-go/src/internal/revisions"
 	"github.com/rinq/rinq-go/src/internal/x/env"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
 	"github.com/rinq/rinq-go/src/rinq/options"
+	"github.com/rinq/rinq-go/src/rinq/sessionstore"
 	"github.com/rinq/rinq-go/src/rinqamqp/internal/amqputil"
 	"github.com/rinq/rinq-go/src/rinqamqp/internal/commandamqp"
 	"github.com/rinq/rinq-go/src/rinqamqp/internal/notifyamqp"
@@ -32,6 +35,21 @@ type Dialer struct {
 
 	// Configuration for the underlying AMQP connection.
 	AMQPConfig amqp.Config
+
+	// PeerID is the ID to use for the peer. If it is the zero-value, a random
+	// ID is allocated. Dial fails if another peer is already connected to the
+	// network using the same ID.
+	PeerID ident.PeerID
+
+	// RestoreSessions, if true, causes the peer to recreate any sessions that
+	// were persisted to the session store by a previous peer with the same ID,
+	// with their original session IDs, revisions and attributes. It requires
+	// PeerID to be set. See options.SessionStore().
+	//
+	// Restored sessions are available via Peer.Sessions(). Notification and
+	// asynchronous call handlers are not persisted, and must be re-registered
+	// by the application.
+	RestoreSessions bool
 }
 
 const (
@@ -59,6 +77,8 @@ func Dial(dsn string, opts ...options.Option) (rinq.Peer, error) {
 // - RINQ_AMQP_HEARTBEAT (duration in milliseconds, non-zero)
 // - RINQ_AMQP_CHANNELS (channel pool size, positive integer, non-zero)
 // - RINQ_AMQP_CONNECTION_TIMEOUT (duration in milliseconds, non-zero)
+// - RINQ_AMQP_PEER_ID (peer ID, such as "58AEE146-191C")
+// - RINQ_AMQP_RESTORE_SESSIONS (true/false)
 //
 // Note that for consistency with other environment variables, RINQ_AMQP_HEARTBEAT
 // is specified in milliseconds, but AMQP only supports 1-second resolution for
@@ -88,6 +108,20 @@ func DialEnv(opts ...options.Option) (rinq.Peer, error) {
 		d.PoolSize = chans
 	}
 
+	if s := os.Getenv("RINQ_AMQP_PEER_ID"); s != "" {
+		d.PeerID, err = ident.ParsePeerID(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	restore, ok, err := env.Bool("RINQ_AMQP_RESTORE_SESSIONS")
+	if err != nil {
+		return nil, err
+	} else if ok {
+		d.RestoreSessions = restore
+	}
+
 	ctx := context.Background()
 
 	timeout, ok, err := env.Duration("RINQ_AMQP_CONNECTION_TIMEOUT")
@@ -127,6 +161,18 @@ func (d *Dialer) Dial(
 		return nil, err
 	}
 
+	if d.RestoreSessions && d.PeerID == (ident.PeerID{}) {
+		return nil, errors.New("sessions can only be restored when the peer ID is pinned")
+	}
+
+	var restored []sessionstore.Session
+	if d.RestoreSessions {
+		restored, err = opts.SessionStore.Load(d.PeerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	amqpCfg := d.AMQPConfig
 	if amqpCfg.Properties == nil {
 		product := opts.Product
@@ -166,20 +212,23 @@ func (d *Dialer) Dial(
 		poolSize = DefaultPoolSize
 	}
 
-	channels := amqputil.NewChannelPool(broker, poolSize)
-	peerID, err := d.establishIdentity(ctx, channels, opts.Logger)
+	channels := amqputil.NewChannelPool(broker, poolSize, opts.Metrics)
+	peerID, err := d.establishIdentity(ctx, channels, opts.StructuredLogger)
 	if err != nil {
 		return nil, err
 	}
 
-	opts.Logger.Log(
+	opts.StructuredLogger.Log(
+		[]logging.Field{
+			logging.Peer(peerID),
+		},
 		"%s connected to '%s' as %s",
 		peerID.ShortString(),
 		dsn,
 		peerID,
 	)
 
-	localStore := localsession.NewStore()
+	localStore := localsession.NewStore(opts.SessionStore)
 	revStore := revisions.NewAggregateStore(
 		peerID,
 		localStore,
@@ -196,32 +245,64 @@ func (d *Dialer) Dial(
 		return nil, err
 	}
 
-	remoteStore := remotesession.NewStore(peerID, invoker, opts.PruneInterval, opts.Logger, opts.Tracer)
+	remoteStore := remotesession.NewStore(
+		peerID,
+		invoker,
+		opts.PruneInterval,
+		opts.DefaultTimeout,
+		opts.StructuredLogger,
+		opts.Tracer,
+		opts.Metrics,
+	)
 	revStore.Remote = remoteStore
 
-	if err := remotesession.Listen(server, peerID, localStore, opts.Logger); err != nil {
+	if err := remotesession.Listen(server, peerID, localStore, remoteStore, opts.StructuredLogger); err != nil {
 		return nil, err
 	}
 
-	return newPeer(
+	p := newPeer(
 		peerID,
 		broker,
+		channels,
 		localStore,
 		remoteStore,
 		invoker,
 		server,
 		notifier,
 		listener,
-		opts.Logger,
+		opts.StructuredLogger,
 		opts.Tracer,
-	), nil
+		opts.Redactor,
+		introspection.NewClient(peerID, invoker, opts.Tracer),
+		opts.Product,
+		introspection.DescribeOptions(opts),
+	)
+
+	p.restore(restored)
+
+	if opts.Introspection {
+		if err = introspection.Listen(server, p.info); err != nil {
+			p.Stop()
+			return nil, err
+		}
+	}
+
+	if opts.MigrationHandler != nil {
+		if err = p.enableMigration(opts.MigrationHandler); err != nil {
+			p.Stop()
+			return nil, err
+		}
+	}
+
+	return p, nil
 }
 
-// establishIdentity allocates a new peer ID on the broker.
+// establishIdentity allocates a new peer ID on the broker, or reserves
+// d.PeerID if it is set.
 func (d *Dialer) establishIdentity(
 	ctx context.Context,
 	channels amqputil.ChannelPool,
-	logger twelf.Logger,
+	logger logging.Logger,
 ) (id ident.PeerID, err error) {
 	var channel *amqp.Channel
 
@@ -231,7 +312,17 @@ func (d *Dialer) establishIdentity(
 			return
 		}
 
-		id = ident.NewPeerID()
+		pinned := d.PeerID != (ident.PeerID{})
+		if pinned {
+			if err = d.PeerID.Validate(); err != nil {
+				return
+			}
+
+			id = d.PeerID
+		} else {
+			id = ident.NewPeerID()
+		}
+
 		_, err = channel.QueueDeclare(
 			id.ShortString(), // this queue is used purely to reserve the peer ID
 			false,            // durable
@@ -249,12 +340,20 @@ func (d *Dialer) establishIdentity(
 			return
 		}
 
+		if pinned {
+			err = fmt.Errorf("peer ID %s is already in use", id)
+			return
+		}
+
 		select {
 		case <-ctx.Done():
 			err = ctx.Err()
 			return
 		default:
 			logger.Debug(
+				[]logging.Field{
+					logging.Peer(id),
+				},
 				"%s already registered, retrying with a different peer ID",
 				id.ShortString(),
 			)
@@ -3,6 +3,7 @@ package amqputil
 import (
 	"errors"
 
+	"github.com/rinq/rinq-go/src/rinq/metrics"
 	"github.com/streadway/amqp"
 )
 
@@ -20,26 +21,40 @@ type ChannelPool interface {
 
 	// Put returns a channel to the pool.
 	Put(*amqp.Channel)
+
+	// Stats returns the number of idle channels in the pool, and the maximum
+	// number of channels that the pool retains.
+	Stats() (idle, capacity int)
 }
 
 // NewChannelPool returns a channel pool of the given size.
-func NewChannelPool(broker *amqp.Connection, size uint) ChannelPool {
+func NewChannelPool(
+	broker *amqp.Connection,
+	size uint,
+	recorder metrics.Recorder,
+) ChannelPool {
 	return &channelPool{
 		broker:   broker,
 		channels: make(chan *amqp.Channel, size),
+		metrics:  recorder,
 	}
 }
 
 type channelPool struct {
 	broker   *amqp.Connection
 	channels chan *amqp.Channel
+	metrics  metrics.Recorder
 }
 
 func (p *channelPool) Get() (channel *amqp.Channel, err error) {
 	select {
 	case channel = <-p.channels: // fetch from the pool
+		p.metrics.ChannelAcquired(false)
 	default: // none available, make a new channel
 		channel, err = p.broker.Channel()
+		if err == nil {
+			p.metrics.ChannelAcquired(true)
+		}
 	}
 
 	return
@@ -79,12 +94,19 @@ func (p *channelPool) Put(channel *amqp.Channel) {
 	// set the QoS state back to unlimited, both to "reset" the channel, and to
 	// verify that it is still usable.
 	if err := channel.Qos(0, 0, true); err != nil {
+		p.metrics.ChannelReleased(false)
 		return
 	}
 
 	select {
 	case p.channels <- channel: // return to the pool
+		p.metrics.ChannelReleased(true)
 	default: // pool is full, close channel
 		_ = channel.Close()
+		p.metrics.ChannelReleased(false)
 	}
 }
+
+func (p *channelPool) Stats() (idle, capacity int) {
+	return len(p.channels), cap(p.channels)
+}
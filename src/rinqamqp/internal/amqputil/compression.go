@@ -0,0 +1,67 @@
+package amqputil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/rinq/rinq-go/src/rinq/options"
+)
+
+var (
+	// zstdEncoder and zstdDecoder are shared by all messages, as zstd encoders
+	// and decoders are expensive to create, and safe for concurrent use when
+	// operating on whole buffers.
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// compress returns the result of compressing buf using the algorithm a.
+func compress(a options.CompressionAlgorithm, buf []byte) []byte {
+	switch a {
+	case options.Gzip:
+		out := &bytes.Buffer{}
+		w := gzip.NewWriter(out)
+
+		// writes to a bytes.Buffer never fail.
+		_, _ = w.Write(buf)
+		_ = w.Close()
+
+		return out.Bytes()
+
+	case options.Zstd:
+		return zstdEncoder.EncodeAll(buf, nil)
+
+	case options.Snappy:
+		return snappy.Encode(nil, buf)
+
+	default:
+		panic(fmt.Sprintf("unsupported compression algorithm: %s", a))
+	}
+}
+
+// decompress returns the result of decompressing buf using the algorithm a.
+func decompress(a options.CompressionAlgorithm, buf []byte) ([]byte, error) {
+	switch a {
+	case options.Gzip:
+		r, err := gzip.NewReader(bytes.NewReader(buf))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		return ioutil.ReadAll(r)
+
+	case options.Zstd:
+		return zstdDecoder.DecodeAll(buf, nil)
+
+	case options.Snappy:
+		return snappy.Decode(nil, buf)
+
+	default:
+		return nil, fmt.Errorf("unsupported content-encoding: %s", a)
+	}
+}
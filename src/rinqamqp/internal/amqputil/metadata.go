@@ -0,0 +1,55 @@
+package amqputil
+
+import (
+	"context"
+
+	"github.com/rinq/rinq-go/src/rinq/metadata"
+	"github.com/streadway/amqp"
+)
+
+// metadataHeader contains the application-defined metadata key/value pairs.
+const metadataHeader = "md"
+
+// PackMetadata packs the metadata from ctx, if any, into the headers of msg.
+func PackMetadata(ctx context.Context, msg *amqp.Publishing) {
+	md := metadata.Get(ctx)
+	if len(md) == 0 {
+		return
+	}
+
+	t := make(amqp.Table, len(md))
+	for k, v := range md {
+		t[k] = v
+	}
+
+	if msg.Headers == nil {
+		msg.Headers = amqp.Table{}
+	}
+
+	msg.Headers[metadataHeader] = t
+}
+
+// UnpackMetadata creates a new context based on parent which contains the
+// metadata packed in the headers of msg, if any.
+//
+// If msg does not contain any metadata, the returned context contains no
+// metadata, even if parent does.
+func UnpackMetadata(parent context.Context, msg *amqp.Delivery) context.Context {
+	t, _ := msg.Headers[metadataHeader].(amqp.Table)
+	if len(t) == 0 {
+		if metadata.Get(parent) == nil {
+			return parent
+		}
+
+		return metadata.With(parent, nil)
+	}
+
+	md := make(metadata.MD, len(t))
+	for k, v := range t {
+		if s, ok := v.(string); ok {
+			md[k] = s
+		}
+	}
+
+	return metadata.With(parent, md)
+}
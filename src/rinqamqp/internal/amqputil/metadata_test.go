@@ -0,0 +1,51 @@
+package amqputil_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq/metadata"
+	"github.com/rinq/rinq-go/src/rinqamqp/internal/amqputil"
+	"github.com/streadway/amqp"
+)
+
+var _ = Describe("Metadata", func() {
+	Describe("PackMetadata", func() {
+		It("packs the metadata into the headers", func() {
+			ctx := metadata.With(context.Background(), metadata.Pairs("<k>", "<v>"))
+			pub := amqp.Publishing{}
+			amqputil.PackMetadata(ctx, &pub)
+
+			Expect(pub.Headers).To(HaveKeyWithValue("md", amqp.Table{"<k>": "<v>"}))
+		})
+
+		It("does not add a header if there is no metadata", func() {
+			pub := amqp.Publishing{}
+			amqputil.PackMetadata(context.Background(), &pub)
+
+			Expect(pub.Headers).To(BeEmpty())
+		})
+	})
+
+	Describe("UnpackMetadata", func() {
+		It("returns a context containing the metadata from the headers", func() {
+			del := amqp.Delivery{
+				Headers: amqp.Table{
+					"md": amqp.Table{"<k>": "<v>"},
+				},
+			}
+			ctx := amqputil.UnpackMetadata(context.Background(), &del)
+
+			Expect(metadata.Get(ctx)).To(Equal(metadata.MD{"<k>": "<v>"}))
+		})
+
+		It("returns a context without metadata if there is no header", func() {
+			parent := metadata.With(context.Background(), metadata.Pairs("<k>", "<v>"))
+			del := amqp.Delivery{}
+			ctx := amqputil.UnpackMetadata(parent, &del)
+
+			Expect(metadata.Get(ctx)).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,71 @@
+package amqputil
+
+import (
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/codec"
+	"github.com/rinq/rinq-go/src/rinq/options"
+	"github.com/streadway/amqp"
+)
+
+// Compression describes how message bodies are compressed.
+type Compression struct {
+	// Algorithm is the compression algorithm to use. If it is empty, message
+	// bodies are never compressed.
+	Algorithm options.CompressionAlgorithm
+
+	// Threshold is the minimum size, in bytes, of a message body before it is
+	// compressed.
+	Threshold uint
+}
+
+// PackPayload sets the body of msg to the binary representation of p.
+//
+// msg.ContentType is set to the payload's content-type, unless the payload uses
+// the default CBOR codec. If the payload meets the threshold described by c,
+// the body is compressed and msg.ContentEncoding is set to the name of the
+// compression algorithm.
+//
+// It returns an error if the payload can not be encoded.
+func PackPayload(msg *amqp.Publishing, p *rinq.Payload, c Compression) error {
+	buf, err := p.Encode()
+	if err != nil {
+		return err
+	}
+
+	if ct := p.ContentType(); len(buf) != 0 && ct != codec.CBOR.ContentType() {
+		msg.ContentType = ct
+	}
+
+	if c.Algorithm == options.NoCompression || uint(len(buf)) < c.Threshold {
+		msg.Body = buf
+		return nil
+	}
+
+	msg.Body = compress(c.Algorithm, buf)
+	msg.ContentEncoding = string(c.Algorithm)
+
+	return nil
+}
+
+// UnpackPayload returns a payload containing the body of msg, decompressing it
+// according to msg.ContentEncoding, if necessary. The payload is decoded
+// according to msg.ContentType.
+//
+// Messages without a content-encoding are assumed to be uncompressed, and
+// messages without a content-type are assumed to be CBOR encoded, allowing
+// payloads to be received from peers that do not support these features.
+func UnpackPayload(msg *amqp.Delivery) (*rinq.Payload, error) {
+	if msg.ContentEncoding == "" {
+		return rinq.NewPayloadFromBytesWithContentType(msg.Body, msg.ContentType), nil
+	}
+
+	buf, err := decompress(
+		options.CompressionAlgorithm(msg.ContentEncoding),
+		msg.Body,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return rinq.NewPayloadFromBytesWithContentType(buf, msg.ContentType), nil
+}
@@ -0,0 +1,154 @@
+package amqputil_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/codec"
+	"github.com/rinq/rinq-go/src/rinq/options"
+	"github.com/rinq/rinq-go/src/rinqamqp/internal/amqputil"
+	"github.com/streadway/amqp"
+)
+
+var _ = Describe("Payload", func() {
+	value := bytes.Repeat([]byte("<value>"), 100)
+
+	Describe("PackPayload", func() {
+		It("does not set the content-type for CBOR payloads", func() {
+			p := rinq.NewPayload(value)
+			defer p.Close()
+
+			pub := amqp.Publishing{}
+			err := amqputil.PackPayload(&pub, p, amqputil.Compression{})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(pub.ContentType).To(Equal(""))
+		})
+
+		It("sets the content-type for payloads that use other codecs", func() {
+			p := rinq.NewPayloadWithCodec(value, codec.JSON)
+			defer p.Close()
+
+			pub := amqp.Publishing{}
+			err := amqputil.PackPayload(&pub, p, amqputil.Compression{})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(pub.ContentType).To(Equal("application/json"))
+		})
+
+		It("returns an error if the payload can not be encoded", func() {
+			p := rinq.NewPayloadWithCodec("<value>", codec.Protobuf)
+			defer p.Close()
+
+			pub := amqp.Publishing{}
+			err := amqputil.PackPayload(&pub, p, amqputil.Compression{})
+
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("does not compress the body if compression is disabled", func() {
+			p := rinq.NewPayload(value)
+			defer p.Close()
+
+			pub := amqp.Publishing{}
+			err := amqputil.PackPayload(&pub, p, amqputil.Compression{})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(pub.Body).To(Equal(p.Bytes()))
+			Expect(pub.ContentEncoding).To(Equal(""))
+		})
+
+		It("does not compress the body if it is smaller than the threshold", func() {
+			p := rinq.NewPayload(value)
+			defer p.Close()
+
+			pub := amqp.Publishing{}
+			err := amqputil.PackPayload(&pub, p, amqputil.Compression{
+				Algorithm: options.Gzip,
+				Threshold: uint(p.Len() + 1),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(pub.Body).To(Equal(p.Bytes()))
+			Expect(pub.ContentEncoding).To(Equal(""))
+		})
+	})
+
+	DescribeTable(
+		"round-trips compressed payloads",
+		func(a options.CompressionAlgorithm) {
+			p := rinq.NewPayload(value)
+			defer p.Close()
+
+			pub := amqp.Publishing{}
+			err := amqputil.PackPayload(&pub, p, amqputil.Compression{
+				Algorithm: a,
+				Threshold: uint(p.Len()),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(pub.ContentEncoding).To(Equal(string(a)))
+			Expect(len(pub.Body)).To(BeNumerically("<", p.Len()))
+
+			del := amqp.Delivery{
+				ContentEncoding: pub.ContentEncoding,
+				Body:            pub.Body,
+			}
+			r, err := amqputil.UnpackPayload(&del)
+			defer r.Close()
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(r.Bytes()).To(Equal(p.Bytes()))
+		},
+		Entry("gzip", options.Gzip),
+		Entry("zstd", options.Zstd),
+		Entry("snappy", options.Snappy),
+	)
+
+	Describe("UnpackPayload", func() {
+		It("returns the body as-is if there is no content-encoding", func() {
+			del := amqp.Delivery{Body: []byte("<body>")}
+			p, err := amqputil.UnpackPayload(&del)
+			defer p.Close()
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(p.Bytes()).To(Equal([]byte("<body>")))
+		})
+
+		It("uses the codec that matches the content-type", func() {
+			del := amqp.Delivery{
+				ContentType: "application/json",
+				Body:        []byte(`{"foo":"bar"}`),
+			}
+			p, err := amqputil.UnpackPayload(&del)
+			defer p.Close()
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(p.ContentType()).To(Equal("application/json"))
+			Expect(p.Value()).To(Equal(map[interface{}]interface{}{"foo": "bar"}))
+		})
+
+		It("returns an error if the content-encoding is not supported", func() {
+			del := amqp.Delivery{
+				ContentEncoding: "<unknown>",
+				Body:            []byte("<body>"),
+			}
+			_, err := amqputil.UnpackPayload(&del)
+
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("returns an error if the body is malformed", func() {
+			del := amqp.Delivery{
+				ContentEncoding: "gzip",
+				Body:            []byte("<body>"),
+			}
+			_, err := amqputil.UnpackPayload(&del)
+
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+})
@@ -13,10 +13,18 @@ import (
 const (
 	// spanContextHeader contains the serialied OpenTracing span context.
 	spanContextHeader = "sc"
+
+	// spanTextMapHeader contains the OpenTracing span context as a table of
+	// strings. It is used for tracers that do not support the binary format,
+	// such as the OpenTelemetry tracer.
+	spanTextMapHeader = "sm"
 )
 
 // PackSpanContext packs a serialized "span context" into the headers of msg
 // based on the span in ctx, if any.
+//
+// If the span's tracer does not support the binary format, the span context is
+// packed in the text-map format instead.
 func PackSpanContext(ctx context.Context, msg *amqp.Publishing) error {
 	span := opentracing.SpanFromContext(ctx)
 	if span == nil {
@@ -25,11 +33,16 @@ func PackSpanContext(ctx context.Context, msg *amqp.Publishing) error {
 
 	buf := bufferpool.Get()
 
-	if err := span.Tracer().Inject(
+	err := span.Tracer().Inject(
 		span.Context(),
 		opentracing.Binary,
 		buf,
-	); err != nil {
+	)
+
+	if err == opentracing.ErrUnsupportedFormat {
+		bufferpool.Put(buf)
+		return packSpanTextMap(span, msg)
+	} else if err != nil {
 		return err
 	}
 
@@ -44,25 +57,84 @@ func PackSpanContext(ctx context.Context, msg *amqp.Publishing) error {
 	return nil
 }
 
+// packSpanTextMap packs the context of span into the headers of msg in the
+// text-map format.
+func packSpanTextMap(span opentracing.Span, msg *amqp.Publishing) error {
+	m := opentracing.TextMapCarrier{}
+
+	if err := span.Tracer().Inject(
+		span.Context(),
+		opentracing.TextMap,
+		m,
+	); err != nil {
+		return err
+	}
+
+	if len(m) > 0 {
+		t := amqp.Table{}
+		for k, v := range m {
+			t[k] = v
+		}
+
+		if msg.Headers == nil {
+			msg.Headers = amqp.Table{}
+		}
+
+		msg.Headers[spanTextMapHeader] = t
+	}
+
+	return nil
+}
+
 // UnpackSpanContext extracts a span context from the headers of msg. If no
 // span context is packed in the headers, nil is returned.
+//
+// A span context packed in a format that is not supported by t is ignored.
 func UnpackSpanContext(msg *amqp.Delivery, t opentracing.Tracer) (opentracing.SpanContext, error) {
-	v, ok := msg.Headers[spanContextHeader]
-	if !ok {
-		return nil, nil
+	if v, ok := msg.Headers[spanContextHeader]; ok {
+		b, ok := v.([]byte)
+		if !ok {
+			return nil, errors.New("span context header is not a byte slice")
+		}
+
+		buf := bytes.NewBuffer(b)
+		defer bufferpool.Put(buf)
+
+		return extractSpanContext(t, opentracing.Binary, buf)
 	}
 
-	b, ok := v.([]byte)
-	if !ok {
-		return nil, errors.New("span context header is not a byte slice")
+	if v, ok := msg.Headers[spanTextMapHeader]; ok {
+		tab, ok := v.(amqp.Table)
+		if !ok {
+			return nil, errors.New("span text-map header is not a table")
+		}
+
+		m := opentracing.TextMapCarrier{}
+		for k, v := range tab {
+			s, ok := v.(string)
+			if !ok {
+				return nil, errors.New("span text-map header contains a non-string value")
+			}
+
+			m[k] = s
+		}
+
+		return extractSpanContext(t, opentracing.TextMap, m)
 	}
 
-	buf := bytes.NewBuffer(b)
-	defer bufferpool.Put(buf)
+	return nil, nil
+}
 
-	sc, err := t.Extract(opentracing.Binary, buf)
+// extractSpanContext extracts a span context from carrier using t.
+func extractSpanContext(
+	t opentracing.Tracer,
+	format interface{},
+	carrier interface{},
+) (opentracing.SpanContext, error) {
+	sc, err := t.Extract(format, carrier)
 
-	if err == opentracing.ErrSpanContextNotFound {
+	switch err {
+	case opentracing.ErrSpanContextNotFound, opentracing.ErrUnsupportedFormat:
 		return nil, nil
 	}
 
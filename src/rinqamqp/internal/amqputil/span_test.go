@@ -0,0 +1,64 @@
+package amqputil_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/rinq/rinq-go/src/internal/oteltr"
+	"github.com/rinq/rinq-go/src/rinqamqp/internal/amqputil"
+	"github.com/streadway/amqp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+var _ = Describe("SpanContext", func() {
+	Context("when the tracer does not support the binary format", func() {
+		var tracer *oteltr.Tracer
+
+		BeforeEach(func() {
+			tracer = oteltr.NewTracer(sdktrace.NewTracerProvider())
+		})
+
+		It("packs the span context as a text-map", func() {
+			span := tracer.StartSpan("")
+			defer span.Finish()
+			ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+			pub := amqp.Publishing{}
+			err := amqputil.PackSpanContext(ctx, &pub)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(pub.Headers).NotTo(HaveKey("sc"))
+			Expect(pub.Headers).To(HaveKey("sm"))
+		})
+
+		It("unpacks a span context packed as a text-map", func() {
+			span := tracer.StartSpan("")
+			defer span.Finish()
+			ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+			pub := amqp.Publishing{}
+			err := amqputil.PackSpanContext(ctx, &pub)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			del := amqp.Delivery{Headers: pub.Headers}
+			sc, err := amqputil.UnpackSpanContext(&del, tracer)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(sc).NotTo(BeNil())
+		})
+
+		It("ignores a span context packed in the binary format", func() {
+			del := amqp.Delivery{
+				Headers: amqp.Table{
+					"sc": []byte("<binary>"),
+				},
+			}
+			sc, err := amqputil.UnpackSpanContext(&del, tracer)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(sc).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,60 @@
+package amqputil
+
+import (
+	"context"
+
+	"github.com/rinq/rinq-go/src/rinq/trace"
+	"github.com/streadway/amqp"
+)
+
+const (
+	// traceParentHeader contains the W3C traceparent value.
+	traceParentHeader = "tp"
+
+	// traceStateHeader contains the W3C tracestate value.
+	traceStateHeader = "ts"
+)
+
+// PackTraceContext packs the W3C trace context from ctx, if any, into the
+// headers of msg.
+func PackTraceContext(ctx context.Context, msg *amqp.Publishing) {
+	tp, ok := trace.GetTraceParent(ctx)
+	if !ok {
+		return
+	}
+
+	if msg.Headers == nil {
+		msg.Headers = amqp.Table{}
+	}
+
+	msg.Headers[traceParentHeader] = tp.String()
+
+	if ts := trace.GetTraceState(ctx); ts != "" {
+		msg.Headers[traceStateHeader] = ts
+	}
+}
+
+// UnpackTraceContext creates a new context based on parent which contains the
+// W3C trace context packed in the headers of msg, if any.
+//
+// As per the W3C specification, an invalid traceparent is ignored, along with
+// the tracestate.
+func UnpackTraceContext(parent context.Context, msg *amqp.Delivery) context.Context {
+	s, _ := msg.Headers[traceParentHeader].(string)
+	if s == "" {
+		return parent
+	}
+
+	tp, err := trace.ParseTraceParent(s)
+	if err != nil {
+		return parent
+	}
+
+	ctx := trace.WithTraceParent(parent, tp)
+
+	if ts, _ := msg.Headers[traceStateHeader].(string); ts != "" {
+		ctx = trace.WithTraceState(ctx, ts)
+	}
+
+	return ctx
+}
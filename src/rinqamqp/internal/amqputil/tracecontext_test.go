@@ -0,0 +1,70 @@
+package amqputil_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rinq/rinq-go/src/rinq/trace"
+	"github.com/rinq/rinq-go/src/rinqamqp/internal/amqputil"
+	"github.com/streadway/amqp"
+)
+
+var _ = Describe("TraceContext", func() {
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	Describe("PackTraceContext", func() {
+		It("packs the traceparent and tracestate into the headers", func() {
+			tp, _ := trace.ParseTraceParent(traceParent)
+			ctx := trace.WithTraceParent(context.Background(), tp)
+			ctx = trace.WithTraceState(ctx, "vendor=value")
+
+			pub := amqp.Publishing{}
+			amqputil.PackTraceContext(ctx, &pub)
+
+			Expect(pub.Headers).To(Equal(amqp.Table{
+				"tp": traceParent,
+				"ts": "vendor=value",
+			}))
+		})
+
+		It("does not pack the tracestate without a traceparent", func() {
+			ctx := trace.WithTraceState(context.Background(), "vendor=value")
+
+			pub := amqp.Publishing{}
+			amqputil.PackTraceContext(ctx, &pub)
+
+			Expect(pub.Headers).To(BeEmpty())
+		})
+	})
+
+	Describe("UnpackTraceContext", func() {
+		It("returns a context containing the trace context", func() {
+			del := amqp.Delivery{
+				Headers: amqp.Table{
+					"tp": traceParent,
+					"ts": "vendor=value",
+				},
+			}
+			ctx := amqputil.UnpackTraceContext(context.Background(), &del)
+
+			tp, ok := trace.GetTraceParent(ctx)
+			Expect(ok).To(BeTrue())
+			Expect(tp.String()).To(Equal(traceParent))
+			Expect(trace.GetTraceState(ctx)).To(Equal("vendor=value"))
+		})
+
+		It("ignores an invalid traceparent", func() {
+			del := amqp.Delivery{
+				Headers: amqp.Table{
+					"tp": "<invalid>",
+					"ts": "vendor=value",
+				},
+			}
+			parent := context.Background()
+			ctx := amqputil.UnpackTraceContext(parent, &del)
+
+			Expect(ctx).To(BeIdenticalTo(parent))
+		})
+	})
+})
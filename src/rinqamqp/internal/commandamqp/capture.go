@@ -0,0 +1,146 @@
+package commandamqp
+
+import (
+	"context"
+	"time"
+
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/capture"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/metadata"
+)
+
+// shouldCapture returns true if traffic in the namespace ns should be sent to
+// the recorder r. Traffic within internal namespaces is never captured.
+func shouldCapture(r capture.Recorder, ns string) bool {
+	return r != capture.Discard && ns != "" && ns[0] != '_'
+}
+
+// newRecord returns a capture record for a command request.
+func newRecord(
+	ctx context.Context,
+	kind capture.Kind,
+	msgID ident.MessageID,
+	traceID string,
+	ns string,
+	cmd string,
+	payload *rinq.Payload,
+	start time.Time,
+) capture.Record {
+	return capture.Record{
+		Kind:      kind,
+		MessageID: msgID.String(),
+		TraceID:   traceID,
+		Metadata:  metadata.Get(ctx),
+		Namespace: ns,
+		Command:   cmd,
+		Request:   capture.NewPayload(payload),
+		StartedAt: start,
+	}
+}
+
+// captureCall records a call that was sent by the invoker, along with its
+// result. target is empty for load-balanced calls.
+func (i *invoker) captureCall(
+	ctx context.Context,
+	msgID ident.MessageID,
+	traceID string,
+	target string,
+	ns string,
+	cmd string,
+	out *rinq.Payload,
+	start time.Time,
+	elapsed time.Duration,
+	in *rinq.Payload,
+	err error,
+) {
+	if !shouldCapture(i.capture, ns) {
+		return
+	}
+
+	r := newRecord(ctx, capture.Call, msgID, traceID, ns, cmd, out, start)
+	r.Target = target
+	r.Duration = elapsed
+	r.SetResult(in, err)
+
+	i.capture.Record(r)
+}
+
+// captureSend records a command request that was sent by the invoker without
+// waiting for a response. err is the error that occurred while sending, if
+// any.
+func (i *invoker) captureSend(
+	ctx context.Context,
+	kind capture.Kind,
+	msgID ident.MessageID,
+	traceID string,
+	ns string,
+	cmd string,
+	out *rinq.Payload,
+	start time.Time,
+	err error,
+) {
+	if !shouldCapture(i.capture, ns) {
+		return
+	}
+
+	r := newRecord(ctx, kind, msgID, traceID, ns, cmd, out, start)
+	if err != nil {
+		r.Error = err.Error()
+	}
+
+	i.capture.Record(r)
+}
+
+// captureResponse wraps a "parent" response and records the result produced
+// by a command handler.
+type captureResponse struct {
+	res rinq.Response
+
+	Record    capture.Record
+	Responded bool
+}
+
+func newCaptureResponse(parent rinq.Response, r capture.Record) *captureResponse {
+	return &captureResponse{
+		res:    parent,
+		Record: r,
+	}
+}
+
+func (r *captureResponse) IsRequired() bool {
+	return r.res.IsRequired()
+}
+
+func (r *captureResponse) IsClosed() bool {
+	return r.res.IsClosed()
+}
+
+func (r *captureResponse) Done(payload *rinq.Payload) {
+	r.res.Done(payload)
+	r.Record.SetResult(payload, nil)
+	r.Responded = true
+}
+
+func (r *captureResponse) Error(err error) {
+	r.res.Error(err)
+	r.Record.SetResult(nil, err)
+	r.Responded = true
+}
+
+func (r *captureResponse) Fail(t, f string, v ...interface{}) rinq.Failure {
+	err := r.res.Fail(t, f, v...)
+	r.Record.SetResult(nil, err)
+	r.Responded = true
+	return err
+}
+
+func (r *captureResponse) Close() bool {
+	if !r.res.Close() {
+		return false
+	}
+
+	r.Record.SetResult(nil, nil)
+	r.Responded = true
+	return true
+}
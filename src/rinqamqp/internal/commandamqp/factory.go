@@ -28,6 +28,10 @@ func New(
 	}
 
 	queues := &queueSet{}
+	compression := amqputil.Compression{
+		Algorithm: opts.Compression,
+		Threshold: opts.CompressionThreshold,
+	}
 
 	invoker, err := newInvoker(
 		peerID,
@@ -36,8 +40,12 @@ func New(
 		sessions,
 		queues,
 		channels,
-		opts.Logger,
+		compression,
+		opts.StructuredLogger,
 		opts.Tracer,
+		opts.Metrics,
+		opts.Redactor,
+		opts.Capture,
 	)
 	if err != nil {
 		return nil, nil, err
@@ -49,8 +57,12 @@ func New(
 		revs,
 		queues,
 		channels,
-		opts.Logger,
+		compression,
+		opts.StructuredLogger,
 		opts.Tracer,
+		opts.Metrics,
+		opts.Redactor,
+		opts.Capture,
 	)
 	if err != nil {
 		invoker.Stop()
@@ -3,16 +3,21 @@ package commandamqp
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/jmalloc/twelf/src/twelf"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/rinq/rinq-go/src/internal/command"
 	"github.com/rinq/rinq-go/src/internal/localsession"
+	"github.com/rinq/rinq-go/src/internal/opentr"
 	"github.com/rinq/rinq-go/src/internal/service"
 	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/capture"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/metrics"
+	"github.com/rinq/rinq-go/src/rinq/redact"
 	"github.com/rinq/rinq-go/src/rinq/trace"
 	"github.com/rinq/rinq-go/src/rinqamqp/internal/amqputil"
 	"github.com/streadway/amqp"
@@ -30,8 +35,12 @@ type invoker struct {
 	queues         *queueSet
 	channels       amqputil.ChannelPool
 	channel        *amqp.Channel // channel used for consuming
-	logger         twelf.Logger
+	compression    amqputil.Compression
+	logger         logging.Logger
 	tracer         opentracing.Tracer
+	metrics        metrics.Recorder
+	redactor       redact.Redactor
+	capture        capture.Recorder
 
 	mutex    sync.RWMutex
 	handlers map[ident.SessionID]rinq.AsyncHandler
@@ -40,6 +49,7 @@ type invoker struct {
 	cancel     chan call            // remove call information from pending
 	deliveries <-chan amqp.Delivery // incoming command responses
 	amqpClosed chan *amqp.Error
+	numPending int32 // len(pending), accessed atomically
 
 	// state-machine data
 	pending map[string]chan *amqp.Delivery // map of message ID to reply channel
@@ -60,8 +70,12 @@ func newInvoker(
 	sessions *localsession.Store,
 	queues *queueSet,
 	channels amqputil.ChannelPool,
-	logger twelf.Logger,
+	compression amqputil.Compression,
+	logger logging.Logger,
 	tracer opentracing.Tracer,
+	recorder metrics.Recorder,
+	redactor redact.Redactor,
+	capturer capture.Recorder,
 ) (command.Invoker, error) {
 	i := &invoker{
 		peerID:         peerID,
@@ -70,8 +84,12 @@ func newInvoker(
 		sessions:       sessions,
 		queues:         queues,
 		channels:       channels,
+		compression:    compression,
 		logger:         logger,
 		tracer:         tracer,
+		metrics:        recorder,
+		redactor:       redactor,
+		capture:        capturer,
 
 		handlers: map[ident.SessionID]rinq.AsyncHandler{},
 
@@ -107,11 +125,18 @@ func (i *invoker) CallUnicast(
 		MessageId: msgID.String(),
 		Priority:  callUnicastPriority,
 	}
-	packRequest(msg, traceID, ns, cmd, out, replyCorrelated)
+	if err := packRequest(msg, traceID, ns, cmd, out, replyCorrelated, i.compression); err != nil {
+		return nil, err
+	}
 
-	logUnicastCallBegin(i.logger, i.peerID, msgID, target, ns, cmd, traceID, out)
+	logUnicastCallBegin(i.logger, i.redactor, i.peerID, msgID, target, ns, cmd, traceID, out)
+	i.metrics.CommandSent(ns, cmd, metrics.Call, out.Len())
+	start := time.Now()
 	in, err := i.call(ctx, unicastExchange, target.String(), msg)
-	logCallEnd(i.logger, i.peerID, msgID, ns, cmd, traceID, in, err)
+	elapsed := time.Since(start)
+	i.metrics.CallCompleted(ns, cmd, elapsed, metrics.OutcomeOf(err), responseSize(in, err))
+	i.captureCall(ctx, msgID, traceID, target.String(), ns, cmd, out, start, elapsed, in, err)
+	logCallEnd(i.logger, i.redactor, i.peerID, msgID, ns, cmd, traceID, in, err)
 
 	return in, err
 }
@@ -128,11 +153,18 @@ func (i *invoker) CallBalanced(
 		MessageId: msgID.String(),
 		Priority:  callBalancedPriority,
 	}
-	packRequest(msg, traceID, ns, cmd, out, replyCorrelated)
+	if err := packRequest(msg, traceID, ns, cmd, out, replyCorrelated, i.compression); err != nil {
+		return nil, err
+	}
 
-	logBalancedCallBegin(i.logger, i.peerID, msgID, ns, cmd, traceID, out)
+	logBalancedCallBegin(i.logger, i.redactor, i.peerID, msgID, ns, cmd, traceID, out)
+	i.metrics.CommandSent(ns, cmd, metrics.Call, out.Len())
+	start := time.Now()
 	in, err := i.call(ctx, balancedExchange, ns, msg)
-	logCallEnd(i.logger, i.peerID, msgID, ns, cmd, traceID, in, err)
+	elapsed := time.Since(start)
+	i.metrics.CallCompleted(ns, cmd, elapsed, metrics.OutcomeOf(err), responseSize(in, err))
+	i.captureCall(ctx, msgID, traceID, "", ns, cmd, out, start, elapsed, in, err)
+	logCallEnd(i.logger, i.redactor, i.peerID, msgID, ns, cmd, traceID, in, err)
 
 	return in, err
 }
@@ -151,10 +183,17 @@ func (i *invoker) CallBalancedAsync(
 		MessageId: msgID.String(),
 		Priority:  callBalancedPriority,
 	}
-	packRequest(msg, traceID, ns, cmd, out, replyUncorrelated)
+	if err := packRequest(msg, traceID, ns, cmd, out, replyUncorrelated, i.compression); err != nil {
+		return err
+	}
 
+	start := time.Now()
 	err := i.send(ctx, balancedExchange, ns, msg)
-	logAsyncRequest(i.logger, i.peerID, msgID, ns, cmd, traceID, out, err)
+	if err == nil {
+		i.metrics.CommandSent(ns, cmd, metrics.CallAsync, out.Len())
+	}
+	i.captureSend(ctx, capture.CallAsync, msgID, traceID, ns, cmd, out, start, err)
+	logAsyncRequest(i.logger, i.redactor, i.peerID, msgID, ns, cmd, traceID, out, err)
 
 	return err
 }
@@ -185,10 +224,17 @@ func (i *invoker) ExecuteBalanced(
 		Priority:     executePriority,
 		DeliveryMode: amqp.Persistent,
 	}
-	packRequest(msg, traceID, ns, cmd, out, replyNone)
+	if err := packRequest(msg, traceID, ns, cmd, out, replyNone, i.compression); err != nil {
+		return err
+	}
 
+	start := time.Now()
 	err := i.send(ctx, balancedExchange, ns, msg)
-	logBalancedExecute(i.logger, i.peerID, msgID, ns, cmd, traceID, out, err)
+	if err == nil {
+		i.metrics.CommandSent(ns, cmd, metrics.Execute, out.Len())
+	}
+	i.captureSend(ctx, capture.Execute, msgID, traceID, ns, cmd, out, start, err)
+	logBalancedExecute(i.logger, i.redactor, i.peerID, msgID, ns, cmd, traceID, out, err)
 
 	return err
 }
@@ -205,10 +251,17 @@ func (i *invoker) ExecuteMulticast(
 		MessageId: msgID.String(),
 		Priority:  executePriority,
 	}
-	packRequest(msg, traceID, ns, cmd, out, replyNone)
+	if err := packRequest(msg, traceID, ns, cmd, out, replyNone, i.compression); err != nil {
+		return err
+	}
 
+	start := time.Now()
 	err := i.send(ctx, multicastExchange, ns, msg)
-	logMulticastExecute(i.logger, i.peerID, msgID, ns, cmd, traceID, out, err)
+	if err == nil {
+		i.metrics.CommandSent(ns, cmd, metrics.Execute, out.Len())
+	}
+	i.captureSend(ctx, capture.ExecuteMany, msgID, traceID, ns, cmd, out, start, err)
+	logMulticastExecute(i.logger, i.redactor, i.peerID, msgID, ns, cmd, traceID, out, err)
 
 	return err
 }
@@ -260,6 +313,19 @@ func (i *invoker) initialize() error {
 	return err
 }
 
+// Pending returns the number of calls that are awaiting a response.
+func (i *invoker) Pending() int {
+	return int(atomic.LoadInt32(&i.numPending))
+}
+
+// updatePending publishes the number of pending calls after i.pending has
+// been modified.
+func (i *invoker) updatePending() {
+	n := len(i.pending)
+	atomic.StoreInt32(&i.numPending, int32(n))
+	i.metrics.SetPendingCalls(n)
+}
+
 // run is the state entered when the service starts
 func (i *invoker) run() (service.State, error) {
 	logInvokerStart(i.logger, i.peerID, i.preFetch)
@@ -268,9 +334,11 @@ func (i *invoker) run() (service.State, error) {
 		select {
 		case c := <-i.track:
 			i.pending[c.ID] = c.Reply
+			i.updatePending()
 
 		case c := <-i.cancel:
 			delete(i.pending, c.ID)
+			i.updatePending()
 
 		case msg, ok := <-i.deliveries:
 			if !ok {
@@ -299,6 +367,7 @@ func (i *invoker) graceful() (service.State, error) {
 		select {
 		case c := <-i.cancel:
 			delete(i.pending, c.ID)
+			i.updatePending()
 
 		case msg, ok := <-i.deliveries:
 			if !ok {
@@ -429,6 +498,9 @@ func (i *invoker) publish(
 		return err
 	}
 
+	amqputil.PackMetadata(ctx, msg)
+	amqputil.PackTraceContext(ctx, msg)
+
 	channel, err := i.channels.Get()
 	if err != nil {
 		return err
@@ -473,6 +545,7 @@ func (i *invoker) replySync(msg *amqp.Delivery) bool {
 	}
 
 	delete(i.pending, msg.RoutingKey)
+	i.updatePending()
 	channel <- msg // buffered chan
 	close(channel)
 
@@ -518,9 +591,9 @@ func (i *invoker) replyAsync(msg *amqp.Delivery) bool {
 	payload, err := unpackResponse(msg)
 
 	span := i.tracer.StartSpan("", spanOpts...)
-	ctx = opentracing.ContextWithSpan(ctx, span)
+	ctx = opentr.ContextWithSpan(ctx, span)
 
-	logAsyncResponse(i.logger, i.peerID, msgID, ns, cmd, trace.Get(ctx), payload, err)
+	logAsyncResponse(i.logger, i.redactor, i.peerID, msgID, ns, cmd, trace.Get(ctx), payload, err)
 
 	go func() {
 		defer span.Finish()
@@ -529,3 +602,13 @@ func (i *invoker) replyAsync(msg *amqp.Delivery) bool {
 
 	return true
 }
+
+// responseSize returns the size of the payload of a command response, where
+// err is the error returned by the call, if any.
+func responseSize(in *rinq.Payload, err error) int {
+	if f, ok := err.(rinq.Failure); ok {
+		return f.Payload.Len()
+	}
+
+	return in.Len()
+}
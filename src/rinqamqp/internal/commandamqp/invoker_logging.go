@@ -1,17 +1,22 @@
 package commandamqp
 
 import (
-	"github.com/jmalloc/twelf/src/twelf"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/redact"
 )
 
 func logInvokerInvalidMessageID(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	msgID string,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Any(logging.MessageIDKey, msgID),
+		},
 		"%s invoker ignored AMQP message, '%s' is not a valid message ID",
 		peerID.ShortString(),
 		msgID,
@@ -19,12 +24,17 @@ func logInvokerInvalidMessageID(
 }
 
 func logInvokerIgnoredMessage(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	msgID ident.MessageID,
 	err error,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.MessageID(msgID),
+			logging.Error(err),
+		},
 		"%s invoker ignored AMQP message %s, %s",
 		peerID.ShortString(),
 		msgID.ShortString(),
@@ -33,7 +43,8 @@ func logInvokerIgnoredMessage(
 }
 
 func logUnicastCallBegin(
-	logger twelf.Logger,
+	logger logging.Logger,
+	redactor redact.Redactor,
 	peerID ident.PeerID,
 	msgID ident.MessageID,
 	target ident.PeerID,
@@ -43,6 +54,14 @@ func logUnicastCallBegin(
 	payload *rinq.Payload,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Namespace(ns),
+			logging.Command(cmd),
+			logging.MessageID(msgID),
+			logging.Any("target", target.String()),
+			logging.TraceID(traceID),
+		},
 		"%s invoker began unicast '%s::%s' call %s to %s [%s] >>> %s",
 		peerID.ShortString(),
 		ns,
@@ -50,12 +69,13 @@ func logUnicastCallBegin(
 		msgID.ShortString(),
 		target.ShortString(),
 		traceID,
-		payload,
+		redact.Lazy(redactor, ns, cmd, payload),
 	)
 }
 
 func logBalancedCallBegin(
-	logger twelf.Logger,
+	logger logging.Logger,
+	redactor redact.Redactor,
 	peerID ident.PeerID,
 	msgID ident.MessageID,
 	ns string,
@@ -64,18 +84,26 @@ func logBalancedCallBegin(
 	payload *rinq.Payload,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Namespace(ns),
+			logging.Command(cmd),
+			logging.MessageID(msgID),
+			logging.TraceID(traceID),
+		},
 		"%s invoker began '%s::%s' call %s [%s] >>> %s",
 		peerID.ShortString(),
 		ns,
 		cmd,
 		msgID.ShortString(),
 		traceID,
-		payload,
+		redact.Lazy(redactor, ns, cmd, payload),
 	)
 }
 
 func logCallEnd(
-	logger twelf.Logger,
+	logger logging.Logger,
+	redactor redact.Redactor,
 	peerID ident.PeerID,
 	msgID ident.MessageID,
 	ns string,
@@ -91,13 +119,20 @@ func logCallEnd(
 	switch e := err.(type) {
 	case nil:
 		logger.Debug(
+			[]logging.Field{
+				logging.Peer(peerID),
+				logging.Namespace(ns),
+				logging.Command(cmd),
+				logging.MessageID(msgID),
+				logging.TraceID(traceID),
+			},
 			"%s invoker completed '%s::%s' call %s successfully [%s] <<< %s",
 			peerID.ShortString(),
 			ns,
 			cmd,
 			msgID.ShortString(),
 			traceID,
-			payload,
+			redact.Lazy(redactor, ns, cmd, payload),
 		)
 	case rinq.Failure:
 		var message string
@@ -106,6 +141,14 @@ func logCallEnd(
 		}
 
 		logger.Debug(
+			[]logging.Field{
+				logging.Peer(peerID),
+				logging.Namespace(ns),
+				logging.Command(cmd),
+				logging.MessageID(msgID),
+				logging.FailureType(e.Type),
+				logging.TraceID(traceID),
+			},
 			"%s invoker completed '%s::%s' call %s with '%s' failure%s [%s] <<< %s",
 			peerID.ShortString(),
 			ns,
@@ -114,10 +157,18 @@ func logCallEnd(
 			e.Type,
 			message,
 			traceID,
-			payload,
+			redact.Lazy(redactor, ns, cmd, payload),
 		)
 	default:
 		logger.Debug(
+			[]logging.Field{
+				logging.Peer(peerID),
+				logging.Namespace(ns),
+				logging.Command(cmd),
+				logging.MessageID(msgID),
+				logging.TraceID(traceID),
+				logging.Error(err),
+			},
 			"%s invoker completed '%s::%s' call %s with error [%s] <<< %s",
 			peerID.ShortString(),
 			ns,
@@ -130,7 +181,8 @@ func logCallEnd(
 }
 
 func logAsyncRequest(
-	logger twelf.Logger,
+	logger logging.Logger,
+	redactor redact.Redactor,
 	peerID ident.PeerID,
 	msgID ident.MessageID,
 	ns string,
@@ -140,18 +192,26 @@ func logAsyncRequest(
 	err error,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Namespace(ns),
+			logging.Command(cmd),
+			logging.MessageID(msgID),
+			logging.TraceID(traceID),
+		},
 		"%s invoker sent asynchronous '%s::%s' call request %s [%s] >>> %s",
 		peerID.ShortString(),
 		ns,
 		cmd,
 		msgID.ShortString(),
 		traceID,
-		payload,
+		redact.Lazy(redactor, ns, cmd, payload),
 	)
 }
 
 func logAsyncResponse(
-	logger twelf.Logger,
+	logger logging.Logger,
+	redactor redact.Redactor,
 	peerID ident.PeerID,
 	msgID ident.MessageID,
 	ns string,
@@ -161,18 +221,26 @@ func logAsyncResponse(
 	err error,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Namespace(ns),
+			logging.Command(cmd),
+			logging.MessageID(msgID),
+			logging.TraceID(traceID),
+		},
 		"%s invoker received asynchronous '%s::%s' call response %s [%s] >>> %s",
 		peerID.ShortString(),
 		ns,
 		cmd,
 		msgID.ShortString(),
 		traceID,
-		payload,
+		redact.Lazy(redactor, ns, cmd, payload),
 	)
 }
 
 func logBalancedExecute(
-	logger twelf.Logger,
+	logger logging.Logger,
+	redactor redact.Redactor,
 	peerID ident.PeerID,
 	msgID ident.MessageID,
 	ns string,
@@ -182,18 +250,26 @@ func logBalancedExecute(
 	err error,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Namespace(ns),
+			logging.Command(cmd),
+			logging.MessageID(msgID),
+			logging.TraceID(traceID),
+		},
 		"%s invoker sent '%s::%s' execution %s [%s] >>> %s",
 		peerID.ShortString(),
 		ns,
 		cmd,
 		msgID.ShortString(),
 		traceID,
-		payload,
+		redact.Lazy(redactor, ns, cmd, payload),
 	)
 }
 
 func logMulticastExecute(
-	logger twelf.Logger,
+	logger logging.Logger,
+	redactor redact.Redactor,
 	peerID ident.PeerID,
 	msgID ident.MessageID,
 	ns string,
@@ -203,22 +279,33 @@ func logMulticastExecute(
 	err error,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Namespace(ns),
+			logging.Command(cmd),
+			logging.MessageID(msgID),
+			logging.TraceID(traceID),
+		},
 		"%s invoker sent multicast '%s::%s' execution %s [%s] >>> %s",
 		peerID.ShortString(),
 		ns,
 		cmd,
 		msgID.ShortString(),
 		traceID,
-		payload,
+		redact.Lazy(redactor, ns, cmd, payload),
 	)
 }
 
 func logInvokerStart(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	preFetch uint,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Any("pre_fetch", preFetch),
+		},
 		"%s invoker started (pre-fetch: %d)",
 		peerID.ShortString(),
 		preFetch,
@@ -226,11 +313,15 @@ func logInvokerStart(
 }
 
 func logInvokerStopping(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	pending int,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Any("pending", pending),
+		},
 		"%s invoker stopping gracefully (pending: %d)",
 		peerID.ShortString(),
 		pending,
@@ -238,17 +329,24 @@ func logInvokerStopping(
 }
 
 func logInvokerStop(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	err error,
 ) {
 	if err == nil {
 		logger.Debug(
+			[]logging.Field{
+				logging.Peer(peerID),
+			},
 			"%s invoker stopped",
 			peerID.ShortString(),
 		)
 	} else {
 		logger.Debug(
+			[]logging.Field{
+				logging.Peer(peerID),
+				logging.Error(err),
+			},
 			"%s invoker stopped: %s",
 			peerID.ShortString(),
 			err,
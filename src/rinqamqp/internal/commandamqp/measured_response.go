@@ -0,0 +1,59 @@
+package commandamqp
+
+import (
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/metrics"
+)
+
+// measuredResponse wraps a "parent" response and captures the outcome and
+// payload size for use in metrics.
+type measuredResponse struct {
+	res rinq.Response
+
+	Outcome metrics.Outcome
+	Size    int
+}
+
+func newMeasuredResponse(parent rinq.Response) *measuredResponse {
+	return &measuredResponse{
+		res:     parent,
+		Outcome: metrics.NoResponse,
+	}
+}
+
+func (r *measuredResponse) IsRequired() bool {
+	return r.res.IsRequired()
+}
+
+func (r *measuredResponse) IsClosed() bool {
+	return r.res.IsClosed()
+}
+
+func (r *measuredResponse) Done(payload *rinq.Payload) {
+	r.res.Done(payload)
+	r.Outcome = metrics.Success
+	r.Size = payload.Len()
+}
+
+func (r *measuredResponse) Error(err error) {
+	r.res.Error(err)
+	r.Outcome = metrics.OutcomeOf(err)
+	r.Size = responseSize(nil, err)
+}
+
+func (r *measuredResponse) Fail(t, f string, v ...interface{}) rinq.Failure {
+	err := r.res.Fail(t, f, v...)
+	r.Outcome = metrics.Failure
+	r.Size = 0
+	return err
+}
+
+func (r *measuredResponse) Close() bool {
+	if !r.res.Close() {
+		return false
+	}
+
+	r.Outcome = metrics.Success
+	r.Size = 0
+	return true
+}
@@ -99,26 +99,33 @@ func packRequest(
 	cmd string,
 	p *rinq.Payload,
 	m replyMode,
-) {
+	c amqputil.Compression,
+) error {
 	packNamespaceAndCommand(msg, ns, cmd)
 	packReplyMode(msg, m)
 	amqputil.PackTrace(msg, traceID)
-	msg.Body = p.Bytes()
+	return amqputil.PackPayload(msg, p, c)
 }
 
-func packSuccessResponse(msg *amqp.Publishing, p *rinq.Payload) {
+func packSuccessResponse(msg *amqp.Publishing, p *rinq.Payload, c amqputil.Compression) error {
 	msg.Type = successResponse
-	msg.Body = p.Bytes()
+	return amqputil.PackPayload(msg, p, c)
 }
 
-func packErrorResponse(msg *amqp.Publishing, err error) {
+func packErrorResponse(msg *amqp.Publishing, err error, c amqputil.Compression) {
 	if f, ok := err.(rinq.Failure); ok {
 		if f.Type == "" {
 			panic("failure type is empty")
 		}
 
+		if perr := amqputil.PackPayload(msg, f.Payload, c); perr != nil {
+			// the failure can not be sent as-is, send the encoding error
+			// instead so that the caller is not left waiting.
+			packErrorResponse(msg, perr, c)
+			return
+		}
+
 		msg.Type = failureResponse
-		msg.Body = f.Payload.Bytes()
 
 		if msg.Headers == nil {
 			msg.Headers = amqp.Table{}
@@ -138,7 +145,7 @@ func packErrorResponse(msg *amqp.Publishing, err error) {
 func unpackResponse(msg *amqp.Delivery) (*rinq.Payload, error) {
 	switch msg.Type {
 	case successResponse:
-		return rinq.NewPayloadFromBytes(msg.Body), nil
+		return amqputil.UnpackPayload(msg)
 
 	case failureResponse:
 		failureType, _ := msg.Headers[failureTypeHeader].(string)
@@ -148,13 +155,13 @@ func unpackResponse(msg *amqp.Delivery) (*rinq.Payload, error) {
 
 		failureMessage, _ := msg.Headers[failureMessageHeader].(string)
 
-		payload := rinq.NewPayloadFromBytes(msg.Body)
-		return payload, rinq.Failure{
-			Type:    failureType,
-			Message: failureMessage,
-			Payload: payload,
+		payload, err := amqputil.UnpackPayload(msg)
+		if err != nil {
+			return nil, err
 		}
 
+		return payload, rinq.NewFailure(failureType, failureMessage, payload)
+
 	case errorResponse:
 		return nil, rinq.CommandError(msg.Body)
 
@@ -14,9 +14,10 @@ import (
 // response is used to send responses to command requests, it implements
 // rinq.Response.
 type response struct {
-	context  context.Context
-	channels amqputil.ChannelPool
-	request  rinq.Request
+	context     context.Context
+	channels    amqputil.ChannelPool
+	request     rinq.Request
+	compression amqputil.Compression
 
 	mutex     sync.RWMutex
 	replyMode replyMode
@@ -28,12 +29,14 @@ func newResponse(
 	channels amqputil.ChannelPool,
 	request rinq.Request,
 	replyMode replyMode,
+	compression amqputil.Compression,
 ) (rinq.Response, func() bool) {
 	r := &response{
-		context:   ctx,
-		channels:  channels,
-		request:   request,
-		replyMode: replyMode,
+		context:     ctx,
+		channels:    channels,
+		request:     request,
+		compression: compression,
+		replyMode:   replyMode,
 	}
 
 	return r, r.finalize
@@ -75,7 +78,10 @@ func (r *response) Done(payload *rinq.Payload) {
 	}
 
 	msg := &amqp.Publishing{}
-	packSuccessResponse(msg, payload)
+	if err := packSuccessResponse(msg, payload, r.compression); err != nil {
+		msg = &amqp.Publishing{}
+		packErrorResponse(msg, err, r.compression)
+	}
 	r.respond(msg)
 }
 
@@ -88,7 +94,7 @@ func (r *response) Error(err error) {
 	}
 
 	msg := &amqp.Publishing{}
-	packErrorResponse(msg, err)
+	packErrorResponse(msg, err, r.compression)
 	r.respond(msg)
 }
 
@@ -112,7 +118,7 @@ func (r *response) Close() bool {
 	}
 
 	msg := &amqp.Publishing{}
-	packSuccessResponse(msg, nil)
+	_ = packSuccessResponse(msg, nil, r.compression) // a nil payload always encodes
 	r.respond(msg)
 
 	return true
@@ -3,15 +3,23 @@ package commandamqp
 import (
 	"context"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/jmalloc/twelf/src/twelf"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/rinq/rinq-go/src/internal/command"
+	"github.com/rinq/rinq-go/src/internal/opentr"
 	"github.com/rinq/rinq-go/src/internal/revisions"
 	"github.com/rinq/rinq-go/src/internal/service"
 	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/capture"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/metadata"
+	"github.com/rinq/rinq-go/src/rinq/metrics"
+	"github.com/rinq/rinq-go/src/rinq/redact"
+	"github.com/rinq/rinq-go/src/rinq/trace"
 	"github.com/rinq/rinq-go/src/rinqamqp/internal/amqputil"
 	"github.com/streadway/amqp"
 )
@@ -20,13 +28,17 @@ type server struct {
 	service.Service
 	sm *service.StateMachine
 
-	peerID    ident.PeerID
-	preFetch  uint
-	revisions revisions.Store
-	queues    *queueSet
-	channels  amqputil.ChannelPool
-	logger    twelf.Logger
-	tracer    opentracing.Tracer
+	peerID      ident.PeerID
+	preFetch    uint
+	revisions   revisions.Store
+	queues      *queueSet
+	channels    amqputil.ChannelPool
+	compression amqputil.Compression
+	logger      logging.Logger
+	tracer      opentracing.Tracer
+	metrics     metrics.Recorder
+	redactor    redact.Redactor
+	capture     capture.Recorder
 
 	parentCtx context.Context // parent of all contexts passed to handlers
 	cancelCtx func()          // cancels parentCtx when the server stops
@@ -35,7 +47,8 @@ type server struct {
 	channel    *amqp.Channel      // channel used for consuming
 	deliveries chan amqp.Delivery // incoming command requests
 	amqpClosed chan *amqp.Error
-	pending    uint // number of requests currently being handled
+	pending    uint  // number of requests currently being handled
+	numPending int32 // copy of pending, accessed atomically
 
 	mutex    sync.RWMutex                   // guards handlers so handler can be read in dispatch() goroutine
 	handlers map[string]rinq.CommandHandler // map of namespace to handler
@@ -48,17 +61,25 @@ func newServer(
 	revs revisions.Store,
 	queues *queueSet,
 	channels amqputil.ChannelPool,
-	logger twelf.Logger,
+	compression amqputil.Compression,
+	logger logging.Logger,
 	tracer opentracing.Tracer,
+	recorder metrics.Recorder,
+	redactor redact.Redactor,
+	capturer capture.Recorder,
 ) (command.Server, error) {
 	s := &server{
-		peerID:    peerID,
-		preFetch:  preFetch,
-		revisions: revs,
-		queues:    queues,
-		channels:  channels,
-		logger:    logger,
-		tracer:    tracer,
+		peerID:      peerID,
+		preFetch:    preFetch,
+		revisions:   revs,
+		queues:      queues,
+		channels:    channels,
+		compression: compression,
+		logger:      logger,
+		tracer:      tracer,
+		metrics:     recorder,
+		redactor:    redactor,
+		capture:     capturer,
 
 		deliveries: make(chan amqp.Delivery, preFetch),
 		amqpClosed: make(chan *amqp.Error, 1),
@@ -115,6 +136,18 @@ func (s *server) Unlisten(ns string) (removed bool, err error) {
 	return
 }
 
+func (s *server) Namespaces() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	namespaces := make([]string, 0, len(s.handlers))
+	for ns := range s.handlers {
+		namespaces = append(namespaces, ns)
+	}
+
+	return namespaces
+}
+
 func (s *server) bind(ns string) error {
 	if err := s.channel.QueueBind(
 		requestQueue(s.peerID),
@@ -216,6 +249,18 @@ func (s *server) initialize() error {
 	return nil
 }
 
+// Pending returns the number of command requests currently being handled.
+func (s *server) Pending() int {
+	return int(atomic.LoadInt32(&s.numPending))
+}
+
+// updatePending publishes the number of requests being handled after
+// s.pending has been modified.
+func (s *server) updatePending() {
+	atomic.StoreInt32(&s.numPending, int32(s.pending))
+	s.metrics.SetInFlightCommands(int(s.pending))
+}
+
 // run is the state entered when the service starts
 func (s *server) run() (service.State, error) {
 	logServerStart(s.logger, s.peerID, s.preFetch)
@@ -226,6 +271,7 @@ func (s *server) run() (service.State, error) {
 		select {
 		case msg := <-s.deliveries:
 			s.pending++
+			s.updatePending()
 			go s.dispatch(&msg)
 
 		case req := <-s.sm.Commands:
@@ -322,6 +368,7 @@ func (s *server) finalize(err error) error {
 func (s *server) dispatch(msg *amqp.Delivery) {
 	defer s.sm.DoGraceful(func() error {
 		s.pending--
+		s.updatePending()
 		return nil
 	})
 
@@ -366,7 +413,14 @@ func (s *server) dispatch(msg *amqp.Delivery) {
 		return
 	}
 
-	s.handle(msgID, msg, ns, cmd, source, h, spanOpts)
+	payload, err := amqputil.UnpackPayload(msg)
+	if err != nil {
+		_ = msg.Reject(false) // false = don't requeue
+		logIgnoredMessage(s.logger, s.peerID, msgID, err)
+		return
+	}
+
+	s.handle(msgID, msg, ns, cmd, source, payload, h, spanOpts)
 }
 
 // handle invokes the command handler for request.
@@ -376,46 +430,76 @@ func (s *server) handle(
 	ns string,
 	cmd string,
 	source rinq.Revision,
+	payload *rinq.Payload,
 	handler rinq.CommandHandler,
 	spanOpts []opentracing.StartSpanOption,
 ) {
 	ctx := amqputil.UnpackTrace(s.parentCtx, msg)
+	ctx = amqputil.UnpackTraceContext(ctx, msg)
+	ctx = amqputil.UnpackMetadata(ctx, msg)
 	ctx, cancel := amqputil.UnpackDeadline(ctx, msg)
 	defer cancel()
 
 	span := s.tracer.StartSpan("", spanOpts...)
 	defer span.Finish()
 
-	ctx = opentracing.ContextWithSpan(ctx, span)
+	ctx = opentr.ContextWithSpan(ctx, span)
 
 	req := rinq.Request{
 		ID:        msgID,
 		Source:    source,
 		Namespace: ns,
 		Command:   cmd,
-		Payload:   rinq.NewPayloadFromBytes(msg.Body),
+		Payload:   payload,
+		Metadata:  metadata.Get(ctx),
 	}
 
+	s.metrics.CommandReceived(ns, cmd, payload.Len())
+
 	res, finalize := newResponse(
 		ctx,
 		s.channels,
 		req,
 		unpackReplyMode(msg),
+		s.compression,
 	)
 
+	mr := newMeasuredResponse(res)
+	res = mr
+
+	var cr *captureResponse
+	if shouldCapture(s.capture, ns) {
+		cr = newCaptureResponse(
+			res,
+			newRecord(ctx, capture.Request, msgID, trace.Get(ctx), ns, cmd, payload, time.Now()),
+		)
+		res = cr
+	}
+
 	if s.logger.IsDebug() {
 		res = newDebugResponse(res)
-		logRequestBegin(ctx, s.logger, s.peerID, msgID, req)
+		logRequestBegin(ctx, s.logger, s.redactor, s.peerID, msgID, req)
 	}
 
+	start := time.Now()
 	handler(ctx, req, res)
+	elapsed := time.Since(start)
+	s.metrics.CommandHandled(ns, cmd, elapsed, mr.Outcome, mr.Size)
+
+	if cr != nil {
+		cr.Record.Duration = elapsed
+		if !cr.Responded {
+			cr.Record.Error = "handler did not respond"
+		}
+		s.capture.Record(cr.Record)
+	}
 
 	if finalize() {
 		_ = msg.Ack(false) // false = single message
 
 		if dr, ok := res.(*debugResponse); ok {
 			defer dr.Payload.Close()
-			logRequestEnd(ctx, s.logger, s.peerID, msgID, req, dr.Payload, dr.Err)
+			logRequestEnd(ctx, s.logger, s.redactor, s.peerID, msgID, req, dr.Payload, dr.Err)
 		}
 	} else if msg.Exchange == balancedExchange {
 		select {
@@ -3,18 +3,23 @@ package commandamqp
 import (
 	"context"
 
-	"github.com/jmalloc/twelf/src/twelf"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/redact"
 	"github.com/rinq/rinq-go/src/rinq/trace"
 )
 
 func logServerInvalidMessageID(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	msgID string,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Any(logging.MessageIDKey, msgID),
+		},
 		"%s server ignored AMQP message, '%s' is not a valid message ID",
 		peerID.ShortString(),
 		msgID,
@@ -22,12 +27,17 @@ func logServerInvalidMessageID(
 }
 
 func logIgnoredMessage(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	msgID ident.MessageID,
 	err error,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.MessageID(msgID),
+			logging.Error(err),
+		},
 		"%s server ignored AMQP message %s, %s",
 		peerID.ShortString(),
 		msgID.ShortString(),
@@ -37,25 +47,34 @@ func logIgnoredMessage(
 
 func logRequestBegin(
 	ctx context.Context,
-	logger twelf.Logger,
+	logger logging.Logger,
+	redactor redact.Redactor,
 	peerID ident.PeerID,
 	msgID ident.MessageID,
 	req rinq.Request,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Namespace(req.Namespace),
+			logging.Command(req.Command),
+			logging.MessageID(msgID),
+			logging.TraceID(trace.Get(ctx)),
+		},
 		"%s server began '%s::%s' command request %s [%s] <<< %s",
 		peerID.ShortString(),
 		req.Namespace,
 		req.Command,
 		msgID.ShortString(),
 		trace.Get(ctx),
-		req.Payload,
+		redact.Lazy(redactor, req.Namespace, req.Command, req.Payload),
 	)
 }
 
 func logRequestEnd(
 	ctx context.Context,
-	logger twelf.Logger,
+	logger logging.Logger,
+	redactor redact.Redactor,
 	peerID ident.PeerID,
 	msgID ident.MessageID,
 	req rinq.Request,
@@ -69,13 +88,20 @@ func logRequestEnd(
 	switch e := err.(type) {
 	case nil:
 		logger.Debug(
+			[]logging.Field{
+				logging.Peer(peerID),
+				logging.Namespace(req.Namespace),
+				logging.Command(req.Command),
+				logging.MessageID(msgID),
+				logging.TraceID(trace.Get(ctx)),
+			},
 			"%s server completed '%s::%s' command request %s successfully [%s] >>> %s",
 			peerID.ShortString(),
 			req.Namespace,
 			req.Command,
 			msgID.ShortString(),
 			trace.Get(ctx),
-			payload,
+			redact.Lazy(redactor, req.Namespace, req.Command, payload),
 		)
 	case rinq.Failure:
 		var message string
@@ -84,6 +110,14 @@ func logRequestEnd(
 		}
 
 		logger.Debug(
+			[]logging.Field{
+				logging.Peer(peerID),
+				logging.Namespace(req.Namespace),
+				logging.Command(req.Command),
+				logging.MessageID(msgID),
+				logging.FailureType(e.Type),
+				logging.TraceID(trace.Get(ctx)),
+			},
 			"%s server completed '%s::%s' command request %s with '%s' failure%s [%s] <<< %s",
 			peerID.ShortString(),
 			req.Namespace,
@@ -92,10 +126,18 @@ func logRequestEnd(
 			e.Type,
 			message,
 			trace.Get(ctx),
-			payload,
+			redact.Lazy(redactor, req.Namespace, req.Command, payload),
 		)
 	default:
 		logger.Debug(
+			[]logging.Field{
+				logging.Peer(peerID),
+				logging.Namespace(req.Namespace),
+				logging.Command(req.Command),
+				logging.MessageID(msgID),
+				logging.TraceID(trace.Get(ctx)),
+				logging.Error(err),
+			},
 			"%s server completed '%s::%s' command request %s with error [%s] <<< %s",
 			peerID.ShortString(),
 			req.Namespace,
@@ -108,12 +150,17 @@ func logRequestEnd(
 }
 
 func logNoLongerListening(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	msgID ident.MessageID,
 	ns string,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Namespace(ns),
+			logging.MessageID(msgID),
+		},
 		"%s is no longer listening to '%s' namespace, request %s has been re-queued",
 		peerID.ShortString(),
 		ns,
@@ -123,12 +170,19 @@ func logNoLongerListening(
 
 func logRequestRequeued(
 	ctx context.Context,
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	msgID ident.MessageID,
 	req rinq.Request,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Namespace(req.Namespace),
+			logging.Command(req.Command),
+			logging.MessageID(msgID),
+			logging.TraceID(trace.Get(ctx)),
+		},
 		"%s did not write a response for '%s::%s' command request, request %s has been re-queued [%s]",
 		peerID.ShortString(),
 		req.Namespace,
@@ -140,13 +194,21 @@ func logRequestRequeued(
 
 func logRequestRejected(
 	ctx context.Context,
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	msgID ident.MessageID,
 	req rinq.Request,
 	reason string,
 ) {
 	logger.Log(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Namespace(req.Namespace),
+			logging.Command(req.Command),
+			logging.MessageID(msgID),
+			logging.Any("reason", reason),
+			logging.TraceID(trace.Get(ctx)),
+		},
 		"%s did not write a response for '%s::%s' command request %s, request has been abandoned (%s) [%s]",
 		peerID.ShortString(),
 		req.Namespace,
@@ -158,11 +220,15 @@ func logRequestRejected(
 }
 
 func logServerStart(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	preFetch uint,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Any("pre_fetch", preFetch),
+		},
 		"%s server started with (pre-fetch: %d)",
 		peerID.ShortString(),
 		preFetch,
@@ -170,11 +236,15 @@ func logServerStart(
 }
 
 func logServerStopping(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	pending uint,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Any("pending", pending),
+		},
 		"%s server is stopping gracefully (pending: %d)",
 		peerID.ShortString(),
 		pending,
@@ -182,17 +252,24 @@ func logServerStopping(
 }
 
 func logServerStop(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	err error,
 ) {
 	if err == nil {
 		logger.Debug(
+			[]logging.Field{
+				logging.Peer(peerID),
+			},
 			"%s server stopped",
 			peerID.ShortString(),
 		)
 	} else {
 		logger.Debug(
+			[]logging.Field{
+				logging.Peer(peerID),
+				logging.Error(err),
+			},
 			"%s server stopped: %s",
 			peerID.ShortString(),
 			err,
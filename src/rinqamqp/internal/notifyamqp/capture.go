@@ -0,0 +1,42 @@
+package notifyamqp
+
+import (
+	"context"
+	"time"
+
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/capture"
+	"github.com/rinq/rinq-go/src/rinq/trace"
+)
+
+// captureNotification records a notification received by the listener, if
+// capture is enabled. sessions is the set of local sessions that receive the
+// notification. Notifications in internal namespaces are never captured.
+func (l *listener) captureNotification(
+	ctx context.Context,
+	n *rinq.Notification,
+	sessions []rinq.Session,
+) {
+	if l.capture == capture.Discard || n.Namespace == "" || n.Namespace[0] == '_' {
+		return
+	}
+
+	r := capture.Record{
+		Kind:      capture.Notification,
+		MessageID: n.ID.String(),
+		TraceID:   trace.Get(ctx),
+		Metadata:  n.Metadata,
+		Namespace: n.Namespace,
+		Type:      n.Type,
+		Request:   capture.NewPayload(n.Payload),
+		StartedAt: time.Now(),
+	}
+
+	if n.IsMulticast {
+		r.Constraint = n.Constraint.String()
+	} else if len(sessions) == 1 {
+		r.Target = sessions[0].ID().String()
+	}
+
+	l.capture.Record(r)
+}
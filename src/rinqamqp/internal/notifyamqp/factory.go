@@ -32,12 +32,20 @@ func New(
 		sessions,
 		revs,
 		channel,
-		opts.Logger,
+		opts.StructuredLogger,
+		opts.Redactor,
 		opts.Tracer,
+		opts.Metrics,
+		opts.Capture,
 	)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return newNotifier(peerID, channels, opts.Logger), listener, nil
+	compression := amqputil.Compression{
+		Algorithm: opts.Compression,
+		Threshold: opts.CompressionThreshold,
+	}
+
+	return newNotifier(peerID, channels, compression, opts.StructuredLogger, opts.Redactor, opts.Metrics), listener, nil
 }
@@ -4,15 +4,23 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/jmalloc/twelf/src/twelf"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/rinq/rinq-go/src/internal/localsession"
 	"github.com/rinq/rinq-go/src/internal/notify"
+	"github.com/rinq/rinq-go/src/internal/opentr"
 	"github.com/rinq/rinq-go/src/internal/revisions"
 	"github.com/rinq/rinq-go/src/internal/service"
 	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/capture"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/metadata"
+	"github.com/rinq/rinq-go/src/rinq/metrics"
+	"github.com/rinq/rinq-go/src/rinq/redact"
+	"github.com/rinq/rinq-go/src/rinq/trace"
 	"github.com/rinq/rinq-go/src/rinqamqp/internal/amqputil"
 	"github.com/streadway/amqp"
 )
@@ -25,8 +33,11 @@ type listener struct {
 	preFetch  uint
 	sessions  *localsession.Store
 	revisions revisions.Store
-	logger    twelf.Logger
+	logger    logging.Logger
+	redactor  redact.Redactor
 	tracer    opentracing.Tracer
+	metrics   metrics.Recorder
+	capture   capture.Recorder
 
 	parentCtx context.Context // parent of all contexts passed to handlers
 	cancelCtx func()          // cancels parentCtx when the server stops
@@ -36,7 +47,8 @@ type listener struct {
 	namespaces map[string]uint      // map of namespace to listener count
 	deliveries <-chan amqp.Delivery // incoming notifications
 	amqpClosed chan *amqp.Error
-	pending    uint // number of notifications currently being handled
+	pending    uint  // number of notifications currently being handled
+	numPending int32 // copy of pending, accessed atomically
 
 	mutex    sync.RWMutex // guards handlers so handler can be read in dispatch() goroutine
 	handlers map[ident.SessionID]map[string]rinq.NotificationHandler
@@ -49,8 +61,11 @@ func newListener(
 	sessions *localsession.Store,
 	revs revisions.Store,
 	channel *amqp.Channel,
-	logger twelf.Logger,
+	logger logging.Logger,
+	redactor redact.Redactor,
 	tracer opentracing.Tracer,
+	recorder metrics.Recorder,
+	capturer capture.Recorder,
 ) (notify.Listener, error) {
 	l := &listener{
 		peerID:    peerID,
@@ -58,7 +73,10 @@ func newListener(
 		sessions:  sessions,
 		revisions: revs,
 		logger:    logger,
+		redactor:  redactor,
 		tracer:    tracer,
+		metrics:   recorder,
+		capture:   capturer,
 
 		channel:    channel,
 		namespaces: map[string]uint{},
@@ -147,6 +165,20 @@ func (l *listener) UnlistenAll(id ident.SessionID) error {
 	})
 }
 
+func (l *listener) Namespaces(id ident.SessionID) []string {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	handlers := l.handlers[id]
+	namespaces := make([]string, 0, len(handlers))
+
+	for ns := range handlers {
+		namespaces = append(namespaces, ns)
+	}
+
+	return namespaces
+}
+
 func (l *listener) bind(ns string) error {
 	count := l.namespaces[ns]
 	l.namespaces[ns] = count + 1
@@ -234,6 +266,11 @@ func (l *listener) initialize() error {
 	return err
 }
 
+// Pending returns the number of notifications currently being handled.
+func (l *listener) Pending() int {
+	return int(atomic.LoadInt32(&l.numPending))
+}
+
 // run is the state entered when the service starts
 func (l *listener) run() (service.State, error) {
 	logListenerStart(l.logger, l.peerID, l.preFetch)
@@ -248,6 +285,7 @@ func (l *listener) run() (service.State, error) {
 				return nil, <-l.amqpClosed
 			}
 			l.pending++
+			atomic.StoreInt32(&l.numPending, int32(l.pending))
 			go l.dispatch(&msg)
 
 		case req := <-l.sm.Commands:
@@ -322,6 +360,7 @@ func (l *listener) finalize(err error) error {
 func (l *listener) dispatch(msg *amqp.Delivery) {
 	defer l.sm.DoGraceful(func() error {
 		l.pending--
+		atomic.StoreInt32(&l.numPending, int32(l.pending))
 		return nil
 	})
 
@@ -372,12 +411,19 @@ func (l *listener) dispatch(msg *amqp.Delivery) {
 	}
 
 	ctx := amqputil.UnpackTrace(l.parentCtx, msg)
+	ctx = amqputil.UnpackTraceContext(ctx, msg)
+	ctx = amqputil.UnpackMetadata(ctx, msg)
+	proto.Metadata = metadata.Get(ctx)
 
 	spanOpts, err := unpackSpanOptions(msg, l.tracer)
 	if err != nil {
 		return
 	}
 
+	l.metrics.NotificationReceived(proto.Namespace, proto.Type, proto.Payload.Len())
+	logNotificationReceived(l.logger, l.redactor, l.peerID, proto, len(sessions), trace.Get(ctx))
+	l.captureNotification(ctx, proto, sessions)
+
 	for _, sess := range sessions {
 		l.handle(
 			ctx,
@@ -452,10 +498,12 @@ func (l *listener) handle(
 		span := l.tracer.StartSpan("", spanOpts...)
 		defer span.Finish()
 
+		start := time.Now()
 		h(
-			opentracing.ContextWithSpan(ctx, span),
+			opentr.ContextWithSpan(ctx, span),
 			sess,
 			n,
 		)
+		l.metrics.NotificationHandled(n.Namespace, n.Type, time.Since(start))
 	}
 }
@@ -1,16 +1,22 @@
 package notifyamqp
 
 import (
-	"github.com/jmalloc/twelf/src/twelf"
+	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/redact"
 )
 
 func logInvalidMessageID(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	msgID string,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Any(logging.MessageIDKey, msgID),
+		},
 		"%s listener ignored AMQP message, '%s' is not a valid message ID",
 		peerID.ShortString(),
 		msgID,
@@ -18,12 +24,17 @@ func logInvalidMessageID(
 }
 
 func logIgnoredMessage(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	msgID ident.MessageID,
 	err error,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.MessageID(msgID),
+			logging.Error(err),
+		},
 		"%s listener ignored AMQP message %s, %s",
 		peerID.ShortString(),
 		msgID.ShortString(),
@@ -31,12 +42,44 @@ func logIgnoredMessage(
 	)
 }
 
+func logNotificationReceived(
+	logger logging.Logger,
+	redactor redact.Redactor,
+	peerID ident.PeerID,
+	n *rinq.Notification,
+	recipients int,
+	traceID string,
+) {
+	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Namespace(n.Namespace),
+			logging.NotificationType(n.Type),
+			logging.MessageID(n.ID),
+			logging.Any("recipients", recipients),
+			logging.TraceID(traceID),
+		},
+		"%s listener received '%s::%s' notification %s for %d session(s) [%s] <<< %s",
+		peerID.ShortString(),
+		n.Namespace,
+		n.Type,
+		n.ID.ShortString(),
+		recipients,
+		traceID,
+		redact.Lazy(redactor, n.Namespace, n.Type, n.Payload),
+	)
+}
+
 func logListenerStart(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	preFetch uint,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Any("pre_fetch", preFetch),
+		},
 		"%s listener started (pre-fetch: %d)",
 		peerID.ShortString(),
 		preFetch,
@@ -44,11 +87,15 @@ func logListenerStart(
 }
 
 func logListenerStopping(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	pending uint,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Any("pending", pending),
+		},
 		"%s listener stopping gracefully (pending: %d)",
 		peerID.ShortString(),
 		pending,
@@ -56,17 +103,24 @@ func logListenerStopping(
 }
 
 func logListenerStop(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	err error,
 ) {
 	if err == nil {
 		logger.Debug(
+			[]logging.Field{
+				logging.Peer(peerID),
+			},
 			"%s listener stopped",
 			peerID.ShortString(),
 		)
 	} else {
 		logger.Debug(
+			[]logging.Field{
+				logging.Peer(peerID),
+				logging.Error(err),
+			},
 			"%s listener stopped: %s",
 			peerID.ShortString(),
 			err,
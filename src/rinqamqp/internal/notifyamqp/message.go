@@ -36,9 +36,12 @@ func packCommonAttributes(
 	ns string,
 	t string,
 	p *rinq.Payload,
-) {
+	c amqputil.Compression,
+) error {
 	msg.Type = t
-	msg.Body = p.Bytes()
+	if err := amqputil.PackPayload(msg, p, c); err != nil {
+		return err
+	}
 
 	if msg.Headers == nil {
 		msg.Headers = amqp.Table{}
@@ -47,17 +50,21 @@ func packCommonAttributes(
 	msg.Headers[namespaceHeader] = ns
 
 	amqputil.PackTrace(msg, traceID)
+
+	return nil
 }
 
 func unpackCommonAttributes(msg *amqp.Delivery) (ns, t string, p *rinq.Payload, err error) {
 	t = msg.Type
-	p = rinq.NewPayloadFromBytes(msg.Body)
 
 	ns, ok := msg.Headers[namespaceHeader].(string)
 	if !ok {
 		err = errors.New("namespace header is not a string")
+		return
 	}
 
+	p, err = amqputil.UnpackPayload(msg)
+
 	return
 }
 
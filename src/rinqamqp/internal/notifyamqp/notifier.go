@@ -3,12 +3,14 @@ package notifyamqp
 import (
 	"context"
 
-	"github.com/jmalloc/twelf/src/twelf"
 	"github.com/rinq/rinq-go/src/internal/notify"
 	"github.com/rinq/rinq-go/src/internal/service"
 	"github.com/rinq/rinq-go/src/rinq"
 	"github.com/rinq/rinq-go/src/rinq/constraint"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/metrics"
+	"github.com/rinq/rinq-go/src/rinq/redact"
 	"github.com/rinq/rinq-go/src/rinqamqp/internal/amqputil"
 	"github.com/streadway/amqp"
 )
@@ -17,21 +19,30 @@ type notifier struct {
 	service.Service
 	sm *service.StateMachine
 
-	peerID   ident.PeerID
-	channels amqputil.ChannelPool
-	logger   twelf.Logger
+	peerID      ident.PeerID
+	channels    amqputil.ChannelPool
+	compression amqputil.Compression
+	logger      logging.Logger
+	redactor    redact.Redactor
+	metrics     metrics.Recorder
 }
 
 // newNotifier creates, initializes and returns a new notifier.
 func newNotifier(
 	peerID ident.PeerID,
 	channels amqputil.ChannelPool,
-	logger twelf.Logger,
+	compression amqputil.Compression,
+	logger logging.Logger,
+	redactor redact.Redactor,
+	recorder metrics.Recorder,
 ) notify.Notifier {
 	n := &notifier{
-		peerID:   peerID,
-		channels: channels,
-		logger:   logger,
+		peerID:      peerID,
+		channels:    channels,
+		compression: compression,
+		logger:      logger,
+		redactor:    redactor,
+		metrics:     recorder,
 	}
 
 	n.sm = service.NewStateMachine(n.run, n.finalize)
@@ -55,15 +66,24 @@ func (n *notifier) NotifyUnicast(
 		MessageId: msgID.String(),
 	}
 
-	packCommonAttributes(&msg, traceID, ns, notificationType, payload)
+	if err = packCommonAttributes(&msg, traceID, ns, notificationType, payload, n.compression); err != nil {
+		return
+	}
 	packTarget(&msg, target)
 
+	amqputil.PackMetadata(ctx, &msg)
+	amqputil.PackTraceContext(ctx, &msg)
 	err = amqputil.PackSpanContext(ctx, &msg)
 
 	if err == nil {
 		err = n.send(unicastExchange, unicastRoutingKey(ns, target.Peer), msg)
 	}
 
+	if err == nil {
+		n.metrics.NotificationSent(ns, notificationType, metrics.Unicast, payload.Len())
+		logUnicastNotification(n.logger, n.redactor, n.peerID, msgID, target, ns, notificationType, traceID, payload)
+	}
+
 	return
 }
 
@@ -80,15 +100,24 @@ func (n *notifier) NotifyMulticast(
 		MessageId: msgID.String(),
 	}
 
-	packCommonAttributes(&msg, traceID, ns, notificationType, payload)
+	if err = packCommonAttributes(&msg, traceID, ns, notificationType, payload, n.compression); err != nil {
+		return
+	}
 	packConstraint(&msg, con)
 
+	amqputil.PackMetadata(ctx, &msg)
+	amqputil.PackTraceContext(ctx, &msg)
 	err = amqputil.PackSpanContext(ctx, &msg)
 
 	if err == nil {
 		err = n.send(multicastExchange, ns, msg)
 	}
 
+	if err == nil {
+		n.metrics.NotificationSent(ns, notificationType, metrics.Multicast, payload.Len())
+		logMulticastNotification(n.logger, n.redactor, n.peerID, msgID, con, ns, notificationType, traceID, payload)
+	}
+
 	return
 }
 
@@ -1,35 +1,110 @@
 package notifyamqp
 
 import (
-	"github.com/jmalloc/twelf/src/twelf"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/redact"
 )
 
 func logNotifierStart(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 ) {
 	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+		},
 		"%s notifier started",
 		peerID.ShortString(),
 	)
 }
 
 func logNotifierStop(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	err error,
 ) {
 	if err == nil {
 		logger.Debug(
+			[]logging.Field{
+				logging.Peer(peerID),
+			},
 			"%s notifier stopped",
 			peerID.ShortString(),
 		)
 	} else {
 		logger.Debug(
+			[]logging.Field{
+				logging.Peer(peerID),
+				logging.Error(err),
+			},
 			"%s notifier stopped: %s",
 			peerID.ShortString(),
 			err,
 		)
 	}
 }
+
+func logUnicastNotification(
+	logger logging.Logger,
+	redactor redact.Redactor,
+	peerID ident.PeerID,
+	msgID ident.MessageID,
+	target ident.SessionID,
+	ns string,
+	t string,
+	traceID string,
+	payload *rinq.Payload,
+) {
+	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Namespace(ns),
+			logging.NotificationType(t),
+			logging.MessageID(msgID),
+			logging.Any("target", target.String()),
+			logging.TraceID(traceID),
+		},
+		"%s notifier sent unicast '%s::%s' notification %s to %s [%s] >>> %s",
+		peerID.ShortString(),
+		ns,
+		t,
+		msgID.ShortString(),
+		target.ShortString(),
+		traceID,
+		redact.Lazy(redactor, ns, t, payload),
+	)
+}
+
+func logMulticastNotification(
+	logger logging.Logger,
+	redactor redact.Redactor,
+	peerID ident.PeerID,
+	msgID ident.MessageID,
+	con constraint.Constraint,
+	ns string,
+	t string,
+	traceID string,
+	payload *rinq.Payload,
+) {
+	logger.Debug(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Namespace(ns),
+			logging.NotificationType(t),
+			logging.MessageID(msgID),
+			logging.Any("constraint", con.String()),
+			logging.TraceID(traceID),
+		},
+		"%s notifier sent multicast '%s::%s' notification %s to %s [%s] >>> %s",
+		peerID.ShortString(),
+		ns,
+		t,
+		msgID.ShortString(),
+		con,
+		traceID,
+		redact.Lazy(redactor, ns, t, payload),
+	)
+}
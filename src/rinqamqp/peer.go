@@ -2,20 +2,30 @@ package rinqamqp
 
 import (
 	"context"
+	"sort"
 	"sync/atomic"
+	"time"
 
-	"github.com/jmalloc/twelf/src/twelf"
 	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/rinq/rinq-go/src/internal/attributes"
 	"github.com/rinq/rinq-go/src/internal/command"
+	"github.com/rinq/rinq-go/src/internal/introspection"
+	"github.com/rinq/rinq-go/src/internal/lifecycle"
 	"github.com/rinq/rinq-go/src/internal/localsession"
+	"github.com/rinq/rinq-go/src/internal/migration"
 	"github.com/rinq/rinq-go/src/internal/namespaces"
 	"github.com/rinq/rinq-go/src/internal/notify"
 	"github.com/rinq/rinq-go/src/internal/opentr"
 	"github.com/rinq/rinq-go/src/internal/remotesession"
 	"github.com/rinq/rinq-go/src/internal/service"
 	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/redact"
+	"github.com/rinq/rinq-go/src/rinq/sessionstore"
 	"github.com/rinq/rinq-go/src/rinq/trace"
+	"github.com/rinq/rinq-go/src/rinqamqp/internal/amqputil"
 	"github.com/streadway/amqp"
 )
 
@@ -26,14 +36,25 @@ type peer struct {
 
 	id          ident.PeerID
 	broker      *amqp.Connection
+	channels    amqputil.ChannelPool
 	localStore  *localsession.Store
 	remoteStore remotesession.Store
 	invoker     command.Invoker
 	server      command.Server
 	notifier    notify.Notifier
 	listener    notify.Listener
-	logger      twelf.Logger
+	logger      logging.Logger
 	tracer      opentracing.Tracer
+	redactor    redact.Redactor
+	inspector   *introspection.Client
+	product     string
+	options     map[string]string
+	startedAt   time.Time
+	events      *lifecycle.Stream
+
+	// migrator and migrationHandler are nil if session migration is disabled.
+	migrator         *migration.Client
+	migrationHandler rinq.MigrationHandler
 
 	seq        uint32
 	amqpClosed chan *amqp.Error
@@ -42,18 +63,24 @@ type peer struct {
 func newPeer(
 	id ident.PeerID,
 	broker *amqp.Connection,
+	channels amqputil.ChannelPool,
 	localStore *localsession.Store,
 	remoteStore remotesession.Store,
 	invoker command.Invoker,
 	server command.Server,
 	notifier notify.Notifier,
 	listener notify.Listener,
-	logger twelf.Logger,
+	logger logging.Logger,
 	tracer opentracing.Tracer,
+	redactor redact.Redactor,
+	inspector *introspection.Client,
+	product string,
+	options map[string]string,
 ) *peer {
 	p := &peer{
 		id:          id,
 		broker:      broker,
+		channels:    channels,
 		localStore:  localStore,
 		remoteStore: remoteStore,
 		invoker:     invoker,
@@ -62,6 +89,12 @@ func newPeer(
 		listener:    listener,
 		logger:      logger,
 		tracer:      tracer,
+		redactor:    redactor,
+		inspector:   inspector,
+		product:     product,
+		options:     options,
+		startedAt:   time.Now(),
+		events:      &lifecycle.Stream{},
 
 		amqpClosed: make(chan *amqp.Error, 1),
 	}
@@ -80,7 +113,7 @@ func (p *peer) ID() ident.PeerID {
 	return p.id
 }
 
-func (p *peer) Session() rinq.Session {
+func (p *peer) Session(opts ...rinq.SessionOption) rinq.Session {
 	id := p.id.Session(
 		atomic.AddUint32(&p.seq, 1),
 	)
@@ -92,15 +125,155 @@ func (p *peer) Session() rinq.Session {
 		p.listener,
 		p.logger,
 		p.tracer,
+		p.redactor,
+		p.localStore.Persistence(),
+		p.events,
 	)
 
+	if e := rinq.NewExpiry(opts...); !e.IsZero() {
+		_ = sess.SetExpiry(e) // can not fail, the session has not been destroyed
+	}
+
+	p.add(sess)
+
+	return sess
+}
+
+func (p *peer) Sessions() []rinq.Session {
+	var sessions []rinq.Session
+
+	p.localStore.Each(func(sess *localsession.Session) {
+		sessions = append(sessions, sess)
+	})
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].ID().Seq < sessions[j].ID().Seq
+	})
+
+	return sessions
+}
+
+func (p *peer) SessionEvents(ctx context.Context) <-chan rinq.SessionEvent {
+	return p.events.Subscribe(ctx)
+}
+
+// add adds sess to the local store until it is destroyed.
+func (p *peer) add(sess *localsession.Session) {
 	p.localStore.Add(sess)
+
 	go func() {
 		<-sess.Done()
 		p.localStore.Remove(sess.ID())
 	}()
+}
 
-	return sess
+// restore recreates the sessions in states, which were persisted by a previous
+// peer with the same ID.
+func (p *peer) restore(states []sessionstore.Session) {
+	for _, state := range states {
+		sess := localsession.RestoreSession(
+			state,
+			p.invoker,
+			p.notifier,
+			p.listener,
+			p.logger,
+			p.tracer,
+			p.redactor,
+			p.localStore.Persistence(),
+			p.events,
+		)
+
+		p.add(sess)
+
+		// ensure new sessions do not reuse the IDs of restored sessions
+		if state.ID.Seq > atomic.LoadUint32(&p.seq) {
+			atomic.StoreUint32(&p.seq, state.ID.Seq)
+		}
+	}
+}
+
+// enableMigration enables session migration, h is called for each session
+// migrated to this peer.
+func (p *peer) enableMigration(h rinq.MigrationHandler) error {
+	p.migrator = migration.NewClient(p.id, p.invoker)
+	p.migrationHandler = h
+
+	return migration.Listen(p.server, p.adopt)
+}
+
+// adopt recreates a session migrated from another peer as a session owned by
+// this peer.
+func (p *peer) adopt(ctx context.Context, s migration.Session) ident.SessionID {
+	id := p.id.Session(
+		atomic.AddUint32(&p.seq, 1),
+	)
+
+	sess := localsession.AdoptSession(
+		id,
+		s.State,
+		p.invoker,
+		p.notifier,
+		p.listener,
+		p.logger,
+		p.tracer,
+		p.redactor,
+		p.localStore.Persistence(),
+		p.events,
+	)
+
+	p.add(sess)
+	p.migrationHandler(ctx, sess, s.State.ID, s.Listening)
+
+	return id
+}
+
+// migrate hands the local sessions to another peer with session migration
+// enabled, and informs all peers of their new IDs. If migration fails the
+// sessions are destroyed as usual.
+func (p *peer) migrate() {
+	var (
+		exported []*localsession.Session
+		sessions []migration.Session
+	)
+
+	p.localStore.Each(func(sess *localsession.Session) {
+		if state, listening, ok := sess.Export(); ok {
+			exported = append(exported, sess)
+			sessions = append(sessions, migration.Session{
+				State:     state,
+				Listening: listening,
+			})
+		}
+	})
+
+	if len(sessions) == 0 {
+		return
+	}
+
+	ctx := context.Background() // the invoker applies the default timeout
+
+	ids, err := p.migrator.Migrate(ctx, sessions)
+	if err != nil {
+		logMigrationError(p.logger, p.id, len(sessions), err)
+
+		for _, sess := range exported {
+			sess.NotMigrated()
+		}
+
+		return
+	}
+
+	moves := make(map[ident.SessionID]ident.SessionID, len(ids))
+	for i, s := range sessions {
+		moves[s.State.ID] = ids[i]
+
+		// the session is now persisted by the peer it was migrated to
+		_ = p.localStore.Persistence().Delete(s.State.ID)
+		exported[i].Migrated()
+	}
+
+	err = p.remoteStore.Moved(ctx, moves)
+	logMigrated(p.logger, p.id, moves, err)
 }
 
 func (p *peer) Listen(ns string, handler rinq.CommandHandler) error {
@@ -124,7 +297,7 @@ func (p *peer) Listen(ns string, handler rinq.CommandHandler) error {
 				req.Command,
 			)
 			opentr.AddTraceID(span, traceID)
-			opentr.LogServerRequest(span, p.id, req.Payload)
+			opentr.LogServerRequest(span, p.redactor, p.id, req)
 
 			handler(
 				ctx,
@@ -135,6 +308,7 @@ func (p *peer) Listen(ns string, handler rinq.CommandHandler) error {
 					p.id,
 					traceID,
 					p.logger,
+					p.redactor,
 					span,
 				),
 			)
@@ -160,6 +334,145 @@ func (p *peer) Unlisten(ns string) error {
 	return err
 }
 
+func (p *peer) Health() rinq.Health {
+	idle, capacity := p.channels.Stats()
+
+	return rinq.Health{
+		PeerID:    p.id,
+		Connected: !p.broker.IsClosed(),
+		Draining:  p.sm.Status() != service.Running,
+		Services: map[string]rinq.ServiceHealth{
+			rinq.InvokerService:     serviceHealth(p.invoker),
+			rinq.ServerService:      serviceHealth(p.server),
+			rinq.ListenerService:    serviceHealth(p.listener),
+			rinq.RemoteStoreService: serviceHealth(p.remoteStore),
+		},
+		ChannelPool: rinq.ChannelPoolHealth{
+			Idle:     idle,
+			Capacity: capacity,
+		},
+		PendingCalls:          p.invoker.Pending(),
+		InFlightCommands:      p.server.Pending(),
+		InFlightNotifications: p.listener.Pending(),
+	}
+}
+
+// serviceHealth returns a report of the state of s.
+func serviceHealth(s service.Service) rinq.ServiceHealth {
+	h := rinq.ServiceHealth{}
+
+	switch s.Status() {
+	case service.Running:
+		h.State = rinq.ServiceRunning
+	case service.Stopping:
+		h.State = rinq.ServiceStopping
+	default:
+		h.State = rinq.ServiceStopped
+		if err := s.Err(); err != nil {
+			h.Error = err.Error()
+		}
+	}
+
+	return h
+}
+
+func (p *peer) Inspect(ctx context.Context, target ident.PeerID) (rinq.PeerInfo, error) {
+	return p.inspector.Inspect(ctx, target)
+}
+
+func (p *peer) InspectSession(ctx context.Context, id ident.SessionID) (rinq.SessionInfo, error) {
+	if id.Peer != p.id {
+		rev, attrs, err := p.remoteStore.Dump(ctx, id)
+		if err != nil {
+			return rinq.SessionInfo{}, err
+		}
+
+		return sessionInfo(id.At(rev), attrs), nil
+	}
+
+	sess, ok := p.localStore.Get(id)
+	if !ok {
+		return rinq.SessionInfo{}, rinq.NotFoundError{ID: id}
+	}
+
+	ref, attrs := sess.Attrs()
+
+	return sessionInfo(ref, attrs), nil
+}
+
+func (p *peer) FindSessions(
+	ctx context.Context,
+	ns string,
+	con constraint.Constraint,
+	limit int,
+) ([]ident.Ref, error) {
+	namespaces.MustValidate(ns)
+
+	if limit < 0 {
+		panic("limit must not be negative")
+	}
+
+	return p.remoteStore.Find(ctx, ns, con, limit)
+}
+
+// sessionInfo returns the information about a session at ref, with the
+// attributes in cat. Attributes that have expired are omitted, as the owning
+// peer is about to clear them.
+func sessionInfo(ref ident.Ref, cat attributes.Catalog) rinq.SessionInfo {
+	info := rinq.SessionInfo{
+		Ref:   ref,
+		Attrs: map[string][]rinq.Attr{},
+	}
+
+	now := time.Now()
+
+	for ns, t := range cat {
+		attrs := make([]rinq.Attr, 0, len(t))
+		for _, attr := range t {
+			if !attr.IsExpiredAt(now) {
+				attrs = append(attrs, attr.Attr)
+			}
+		}
+
+		sort.Slice(attrs, func(i, j int) bool {
+			return attrs[i].Key < attrs[j].Key
+		})
+
+		info.Attrs[ns] = attrs
+	}
+
+	return info
+}
+
+// info returns runtime diagnostic information about the peer, it is used to
+// answer introspection requests.
+func (p *peer) info() rinq.PeerInfo {
+	namespaces := p.server.Namespaces()
+	sort.Strings(namespaces)
+
+	var sessions []ident.SessionID
+	p.localStore.Each(func(sess *localsession.Session) {
+		sessions = append(sessions, sess.ID())
+	})
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Seq < sessions[j].Seq
+	})
+
+	return rinq.PeerInfo{
+		ID:                    p.id,
+		Version:               rinq.Version,
+		Product:               p.product,
+		StartedAt:             p.startedAt,
+		Uptime:                time.Since(p.startedAt),
+		Namespaces:            namespaces,
+		Sessions:              sessions,
+		PendingCalls:          p.invoker.Pending(),
+		InFlightCommands:      p.server.Pending(),
+		InFlightNotifications: p.listener.Pending(),
+		Options:               p.options,
+	}
+}
+
 func (p *peer) run() (service.State, error) {
 	select {
 	case <-p.remoteStore.Done():
@@ -186,6 +499,23 @@ func (p *peer) run() (service.State, error) {
 }
 
 func (p *peer) graceful() (service.State, error) {
+	if p.migrator != nil {
+		// stop handling requests before migrating, so that the sessions are
+		// not modified during migration, and are not migrated to this peer.
+		p.server.GracefulStop()
+
+		select {
+		case <-p.server.Done():
+			p.migrate()
+
+		case <-p.sm.Forceful:
+			return nil, nil
+
+		case err := <-p.amqpClosed:
+			return nil, err
+		}
+	}
+
 	p.server.GracefulStop()
 	p.invoker.GracefulStop()
 	p.remoteStore.GracefulStop()
@@ -216,11 +546,21 @@ func (p *peer) finalize(err error) error {
 	p.remoteStore.Stop()
 	p.listener.Stop()
 
+	// sessions are only closed, rather than destroyed, if their state is
+	// persisted, so that they can be restored by a future peer.
+	persistent := p.localStore.Persistence() != sessionstore.None
+
 	p.localStore.Each(func(sess *localsession.Session) {
-		sess.Destroy()
+		if persistent {
+			sess.Close()
+		} else {
+			sess.Destroy()
+		}
 		<-sess.Done()
 	})
 
+	p.events.Close()
+
 	<-service.WaitAll(
 		p.remoteStore,
 		p.invoker,
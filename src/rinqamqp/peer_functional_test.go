@@ -4,12 +4,23 @@ package rinqamqp_test
 
 import (
 	"context"
+	"fmt"
 	"math/rand"
+	"os"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/rinq/rinq-go/src/internal/functest"
+	"github.com/rinq/rinq-go/src/rinq"
+	"github.com/rinq/rinq-go/src/rinq/capture"
+	"github.com/rinq/rinq-go/src/rinq/constraint"
+	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
+	"github.com/rinq/rinq-go/src/rinq/options"
+	"github.com/rinq/rinq-go/src/rinq/sessionstore"
+	"github.com/rinq/rinq-go/src/rinqamqp"
 )
 
 var _ = Describe("peer (functional)", func() {
@@ -62,6 +73,550 @@ var _ = Describe("peer (functional)", func() {
 
 			sess.Destroy()
 		})
+
+		It("returns a session that is destroyed when its TTL elapses", func() {
+			subject := functest.SharedPeer()
+
+			sess := subject.Session(rinq.TTL(50 * time.Millisecond))
+			defer sess.Destroy()
+
+			Eventually(sess.Done()).Should(BeClosed())
+		})
+
+		It("returns a session that still clears expired attributes after its expiry is changed", func() {
+			subject := functest.SharedPeer()
+
+			sess := subject.Session()
+			defer sess.Destroy()
+
+			rev, err := sess.CurrentRevision().Update(
+				context.Background(),
+				ns,
+				rinq.Set("a", "1").WithTTL(50*time.Millisecond),
+			)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(sess.SetExpiry(rinq.Expiry{TTL: time.Hour})).To(Succeed())
+
+			// the attribute is cleared in a new revision, so it can no longer
+			// be read at the revision in which it was set
+			Eventually(func() bool {
+				_, err := rev.Get(context.Background(), ns, "a")
+				return rinq.ShouldRetry(err)
+			}, 5*time.Second).Should(BeTrue())
+
+			attr, err := sess.CurrentRevision().Get(context.Background(), ns, "a")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(attr).To(Equal(rinq.Set("a", "")))
+		})
+
+		It("returns a session that is destroyed when it is idle", func() {
+			subject := functest.SharedPeer()
+
+			sess := subject.Session(rinq.IdleTimeout(1 * time.Second))
+			defer sess.Destroy()
+
+			rev := sess.CurrentRevision()
+
+			// keep the session active for longer than the idle timeout, with
+			// activity far more frequent than the timeout itself
+			Consistently(func() <-chan struct{} {
+				var err error
+				rev, err = rev.Update(context.Background(), ns, rinq.Set("a", "1"))
+				Expect(err).ShouldNot(HaveOccurred())
+				rev, err = rev.Clear(context.Background(), ns)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				return sess.Done()
+			}, 2*time.Second, 100*time.Millisecond).ShouldNot(BeClosed())
+
+			// the session survives for a while after the last activity ...
+			Consistently(sess.Done(), 500*time.Millisecond).ShouldNot(BeClosed())
+
+			// ... and is destroyed once the idle timeout elapses
+			Eventually(sess.Done(), 5*time.Second).Should(BeClosed())
+		})
+	})
+
+	Describe("Health", func() {
+		It("reports that a running peer is ready", func() {
+			subject := functest.SharedPeer()
+
+			h := subject.Health()
+
+			Expect(h.PeerID).To(Equal(subject.ID()))
+			Expect(h.Connected).To(BeTrue())
+			Expect(h.Draining).To(BeFalse())
+			Expect(h.IsReady()).To(BeTrue())
+		})
+
+		It("reports that a stopped peer is not live", func() {
+			subject := functest.NewPeer()
+
+			subject.Stop()
+			<-subject.Done()
+
+			h := subject.Health()
+
+			Expect(h.Draining).To(BeTrue())
+			Expect(h.IsLive()).To(BeFalse())
+			Expect(h.Services).To(HaveKeyWithValue(
+				rinq.ServerService,
+				rinq.ServiceHealth{State: rinq.ServiceStopped},
+			))
+		})
+	})
+
+	Describe("RestoreSessions", func() {
+		It("restores persisted sessions when a peer with the same ID reconnects", func() {
+			store := sessionstore.NewMemory()
+			dialer := rinqamqp.Dialer{
+				PeerID:          ident.NewPeerID(),
+				RestoreSessions: true,
+			}
+
+			dial := func() rinq.Peer {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				p, err := dialer.Dial(ctx, os.Getenv("RINQ_AMQP_DSN"), options.SessionStore(store))
+				Expect(err).ShouldNot(HaveOccurred())
+
+				return p
+			}
+
+			subject := dial()
+
+			sess := subject.Session()
+			_, err := sess.CurrentRevision().Update(context.Background(), ns, rinq.Set("a", "1"))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			destroyed := subject.Session()
+			destroyed.Destroy()
+
+			subject.GracefulStop()
+			<-subject.Done()
+
+			subject = dial()
+			defer subject.Stop()
+
+			sessions := subject.Sessions()
+			Expect(sessions).To(HaveLen(1))
+			Expect(sessions[0].ID()).To(Equal(sess.ID()))
+
+			restored := sessions[0].CurrentRevision()
+			attr, err := restored.Get(context.Background(), ns, "a")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(attr).To(Equal(rinq.Set("a", "1")))
+
+			_, err = restored.Update(context.Background(), ns, rinq.Set("a", "2"))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(subject.Session().ID().Seq).To(BeNumerically(">", destroyed.ID().Seq))
+		})
+
+		It("fails if the peer ID is not pinned", func() {
+			dialer := rinqamqp.Dialer{RestoreSessions: true}
+
+			_, err := dialer.Dial(context.Background(), os.Getenv("RINQ_AMQP_DSN"))
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Capture", func() {
+		It("records calls and the requests they produce", func() {
+			var (
+				m       sync.Mutex
+				records []capture.Record
+			)
+
+			subject := functest.NewPeer(options.Capture(capture.Func(func(r capture.Record) {
+				m.Lock()
+				defer m.Unlock()
+				records = append(records, r)
+			})))
+			defer subject.Stop()
+
+			err := subject.Listen(ns, func(ctx context.Context, req rinq.Request, res rinq.Response) {
+				defer req.Payload.Close()
+				res.Done(req.Payload.Clone())
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			sess := subject.Session()
+			defer sess.Destroy()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			out := rinq.NewPayload(123)
+			defer out.Close()
+
+			in, err := sess.Call(ctx, ns, "echo", out)
+			defer in.Close()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			m.Lock()
+			defer m.Unlock()
+
+			Expect(records).To(HaveLen(2))
+			Expect(records[0].Kind).To(Equal(capture.Request))
+			Expect(records[1].Kind).To(Equal(capture.Call))
+
+			for _, r := range records {
+				Expect(r.Namespace).To(Equal(ns))
+				Expect(r.Command).To(Equal("echo"))
+				Expect(r.MessageID).To(Equal(records[0].MessageID))
+				Expect(capture.Compare(r, in, nil)).To(BeEmpty())
+			}
+		})
+	})
+
+	Describe("Inspect", func() {
+		It("returns information about a peer with introspection enabled", func() {
+			target := functest.NewPeer(options.Introspection(true))
+			defer target.Stop()
+
+			sess := target.Session()
+			defer sess.Destroy()
+
+			err := target.Listen(ns, func(ctx context.Context, req rinq.Request, res rinq.Response) {
+				res.Close()
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			info, err := functest.SharedPeer().Inspect(ctx, target.ID())
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(info.ID).To(Equal(target.ID()))
+			Expect(info.Version).To(Equal(rinq.Version))
+			Expect(info.Namespaces).To(ContainElement(ns))
+			Expect(info.Namespaces).To(ContainElement("_peer"))
+			Expect(info.Sessions).To(ContainElement(sess.ID()))
+			Expect(info.Options).To(HaveKeyWithValue("introspection", "true"))
+		})
+
+		It("can inspect the peer itself", func() {
+			subject := functest.NewPeer(options.Introspection(true))
+			defer subject.Stop()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			info, err := subject.Inspect(ctx, subject.ID())
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(info.ID).To(Equal(subject.ID()))
+		})
+
+		It("does not receive a response from a peer with introspection disabled", func() {
+			target := functest.NewPeer()
+			defer target.Stop()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			_, err := functest.SharedPeer().Inspect(ctx, target.ID())
+
+			Expect(err).To(Equal(context.DeadlineExceeded))
+		})
+	})
+
+	Describe("InspectSession", func() {
+		It("returns the attributes of a remote session", func() {
+			owner := functest.NewPeer()
+			defer owner.Stop()
+
+			sess := owner.Session()
+			defer sess.Destroy()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, err := sess.CurrentRevision().Update(ctx, ns, rinq.Set("a", "1"), rinq.Freeze("b", "2"))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			info, err := functest.SharedPeer().InspectSession(ctx, sess.ID())
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(info.Ref).To(Equal(sess.ID().At(1)))
+			Expect(info.Attrs).To(HaveKeyWithValue(
+				ns,
+				[]rinq.Attr{rinq.Set("a", "1"), rinq.Freeze("b", "2")},
+			))
+		})
+
+		It("returns the attributes of a local session", func() {
+			subject := functest.SharedPeer()
+
+			sess := subject.Session()
+			defer sess.Destroy()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, err := sess.CurrentRevision().Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			info, err := subject.InspectSession(ctx, sess.ID())
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(info.String(ns)).To(Equal(ns + "::{a=1}"))
+		})
+
+		It("returns a not found error if the session does not exist", func() {
+			owner := functest.NewPeer()
+			defer owner.Stop()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, err := functest.SharedPeer().InspectSession(ctx, owner.ID().Session(1000))
+
+			Expect(rinq.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Describe("FindSessions", func() {
+		It("returns the matching sessions owned by any peer", func() {
+			subject := functest.SharedPeer()
+			owner := functest.NewPeer()
+			defer owner.Stop()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+
+			local := subject.Session()
+			defer local.Destroy()
+			_, err := local.CurrentRevision().Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			remote := owner.Session()
+			defer remote.Destroy()
+			_, err = remote.CurrentRevision().Update(ctx, ns, rinq.Set("a", "1"), rinq.Set("b", "2"))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			other := owner.Session()
+			defer other.Destroy()
+			_, err = other.CurrentRevision().Update(ctx, ns, rinq.Set("a", "2"))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			refs, err := subject.FindSessions(ctx, ns, constraint.Equal("a", "1"), 0)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(refs).To(ConsistOf(
+				local.ID().At(1),
+				remote.ID().At(1),
+			))
+		})
+
+		It("does not match sessions by attributes that have expired", func() {
+			subject := functest.SharedPeer()
+			owner := functest.NewPeer()
+			defer owner.Stop()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+
+			sess := owner.Session()
+			defer sess.Destroy()
+			_, err := sess.CurrentRevision().Update(ctx, ns, rinq.Set("a", "1").WithTTL(50*time.Millisecond))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			time.Sleep(100 * time.Millisecond)
+
+			refs, err := subject.FindSessions(ctx, ns, constraint.Equal("a", "1"), 0)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(refs).To(BeEmpty())
+		})
+
+		It("returns once the limit is reached", func() {
+			subject := functest.SharedPeer()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			for i := 0; i < 3; i++ {
+				sess := subject.Session()
+				defer sess.Destroy()
+
+				_, err := sess.CurrentRevision().Update(ctx, ns, rinq.Set("a", "1"))
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+
+			start := time.Now()
+			refs, err := subject.FindSessions(ctx, ns, constraint.Equal("a", "1"), 2)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(refs).To(HaveLen(2))
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		})
+
+		It("returns an empty result if no sessions match before the deadline", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			refs, err := functest.SharedPeer().FindSessions(ctx, ns, constraint.NotEmpty("a"), 0)
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(refs).To(BeEmpty())
+		})
+
+		It("returns an error if the context is canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := functest.SharedPeer().FindSessions(ctx, ns, constraint.NotEmpty("a"), 0)
+
+			Expect(err).To(Equal(context.Canceled))
+		})
+	})
+
+	Describe("SessionEvents", func() {
+		It("delivers events for local sessions in revision order", func() {
+			subject := functest.NewPeer()
+			defer subject.Stop()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			events := subject.SessionEvents(ctx)
+
+			sess := subject.Session()
+			rev, err := sess.CurrentRevision().Update(ctx, ns, rinq.Set("a", "1"))
+			Expect(err).ShouldNot(HaveOccurred())
+			rev, err = rev.Clear(ctx, ns)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(rev.Destroy(ctx)).To(Succeed())
+
+			src := rinq.SessionEventSource{Peer: subject.ID()}
+
+			Eventually(events).Should(Receive(Equal(rinq.SessionEvent{
+				Type:   rinq.SessionCreated,
+				Ref:    sess.ID().At(0),
+				Source: src,
+			})))
+			Eventually(events).Should(Receive(Equal(rinq.SessionEvent{
+				Type:      rinq.SessionUpdated,
+				Ref:       sess.ID().At(1),
+				Namespace: ns,
+				Attrs:     []rinq.Attr{rinq.Set("a", "1")},
+				Source:    src,
+			})))
+			Eventually(events).Should(Receive(Equal(rinq.SessionEvent{
+				Type:      rinq.SessionCleared,
+				Ref:       sess.ID().At(2),
+				Namespace: ns,
+				Attrs:     []rinq.Attr{rinq.Set("a", "")},
+				Source:    src,
+			})))
+			Eventually(events).Should(Receive(Equal(rinq.SessionEvent{
+				Type:   rinq.SessionDestroyed,
+				Ref:    sess.ID().At(2),
+				Source: src,
+			})))
+		})
+
+		It("identifies the peer that made changes via a remote revision", func() {
+			subject := functest.NewPeer()
+			defer subject.Stop()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			server := functest.NewPeer()
+			defer server.Stop()
+
+			functest.Must(server.Listen(ns, func(ctx context.Context, req rinq.Request, res rinq.Response) {
+				_, err := req.Source.Update(ctx, ns, rinq.Set("a", "1"))
+				if err != nil {
+					res.Error(err)
+				} else {
+					res.Close()
+				}
+			}))
+
+			sess := subject.Session()
+			events := subject.SessionEvents(ctx)
+
+			_, err := sess.Call(ctx, ns, "", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			var e rinq.SessionEvent
+			Eventually(events).Should(Receive(&e))
+
+			Expect(e.Type).To(Equal(rinq.SessionUpdated))
+			Expect(e.Ref).To(Equal(sess.ID().At(1)))
+			Expect(e.Source.Peer).To(Equal(server.ID()))
+			Expect(e.Source.TraceID).NotTo(BeEmpty())
+		})
+
+		It("closes the channel when the peer stops", func() {
+			subject := functest.NewPeer()
+
+			events := subject.SessionEvents(context.Background())
+
+			subject.Stop()
+			<-subject.Done()
+
+			Eventually(events).Should(BeClosed())
+		})
+
+		It("delivers destroyed events for sessions destroyed when the peer stops", func() {
+			logger := &messageLogger{}
+			subject := functest.NewPeer(options.StructuredLogger(logger))
+
+			events := subject.SessionEvents(context.Background())
+			sess := subject.Session()
+
+			subject.Stop()
+			<-subject.Done()
+
+			src := rinq.SessionEventSource{Peer: subject.ID()}
+
+			Eventually(events).Should(Receive(Equal(rinq.SessionEvent{
+				Type:   rinq.SessionCreated,
+				Ref:    sess.ID().At(0),
+				Source: src,
+			})))
+			Eventually(events).Should(Receive(Equal(rinq.SessionEvent{
+				Type:   rinq.SessionDestroyed,
+				Ref:    sess.ID().At(0),
+				Source: src,
+			})))
+			Expect(logger.Messages()).To(ContainElement(
+				ContainSubstring("session destroyed"),
+			))
+		})
+
+		It("does not deliver destroyed events for sessions closed when the peer stops with a persistent store", func() {
+			logger := &messageLogger{}
+			subject := functest.NewPeer(
+				options.StructuredLogger(logger),
+				options.SessionStore(sessionstore.NewMemory()),
+			)
+
+			events := subject.SessionEvents(context.Background())
+			sess := subject.Session()
+
+			subject.Stop()
+			<-subject.Done()
+
+			Eventually(events).Should(Receive(Equal(rinq.SessionEvent{
+				Type:   rinq.SessionCreated,
+				Ref:    sess.ID().At(0),
+				Source: rinq.SessionEventSource{Peer: subject.ID()},
+			})))
+			Eventually(events).Should(BeClosed())
+			Expect(logger.Messages()).NotTo(ContainElement(
+				ContainSubstring("session destroyed"),
+			))
+		})
 	})
 
 	Describe("Listen", func() {
@@ -225,9 +780,182 @@ var _ = Describe("peer (functional)", func() {
 				Expect(err).To(Equal(context.Canceled))
 			})
 		})
+
+		It("destroys local sessions", func() {
+			logger := &messageLogger{}
+			subject := functest.NewPeer(options.StructuredLogger(logger))
+
+			sess := subject.Session()
+
+			subject.Stop()
+			<-subject.Done()
+			<-sess.Done()
+
+			Expect(logger.Messages()).To(ContainElement(
+				ContainSubstring("session destroyed"),
+			))
+		})
+
+		It("closes local sessions without destroying them if a persistent session store is configured", func() {
+			logger := &messageLogger{}
+			subject := functest.NewPeer(
+				options.StructuredLogger(logger),
+				options.SessionStore(sessionstore.NewMemory()),
+			)
+
+			sess := subject.Session()
+
+			subject.Stop()
+			<-subject.Done()
+			<-sess.Done()
+
+			Expect(logger.Messages()).NotTo(ContainElement(
+				ContainSubstring("session destroyed"),
+			))
+		})
 	})
 
 	Describe("GracefulStop", func() {
+		Context("when session migration is enabled", func() {
+			type migrated struct {
+				sess      rinq.Session
+				from      ident.SessionID
+				listening []string
+			}
+
+			var (
+				received chan migrated
+				handler  rinq.MigrationHandler
+			)
+
+			BeforeEach(func() {
+				received = make(chan migrated, 1)
+				handler = func(ctx context.Context, sess rinq.Session, from ident.SessionID, listening []string) {
+					received <- migrated{sess, from, listening}
+				}
+			})
+
+			It("migrates local sessions to another peer", func() {
+				target := functest.NewPeer(options.SessionMigration(handler))
+				defer target.Stop()
+
+				subject := functest.NewPeer(options.SessionMigration(handler))
+				defer subject.Stop()
+
+				sess := subject.Session()
+				functest.Must(sess.Listen(ns, func(context.Context, rinq.Session, rinq.Notification) {}))
+				functest.Must(sess.CurrentRevision().Update(
+					context.Background(),
+					ns,
+					rinq.Set("a", "1"),
+					rinq.Freeze("b", "2"),
+				))
+
+				subject.GracefulStop()
+				<-subject.Done()
+
+				var m migrated
+				Eventually(received).Should(Receive(&m))
+
+				Expect(m.from).To(Equal(sess.ID()))
+				Expect(m.sess.ID().Peer).To(Equal(target.ID()))
+				Expect(m.listening).To(Equal([]string{ns}))
+
+				attrs, err := m.sess.CurrentRevision().GetMany(context.Background(), ns, "a", "b")
+				Expect(err).ShouldNot(HaveOccurred())
+
+				attr, _ := attrs.Get("a")
+				Expect(attr).To(Equal(rinq.Set("a", "1")))
+				attr, _ = attrs.Get("b")
+				Expect(attr).To(Equal(rinq.Freeze("b", "2")))
+			})
+
+			It("closes remote watchers of migrated sessions", func() {
+				target := functest.NewPeer(options.SessionMigration(handler))
+				defer target.Stop()
+
+				subject := functest.NewPeer(options.SessionMigration(handler))
+				defer subject.Stop()
+
+				observer := functest.NewPeer()
+				defer observer.Stop()
+
+				var remote rinq.Revision
+				functest.Must(observer.Listen(ns, func(ctx context.Context, req rinq.Request, res rinq.Response) {
+					remote = req.Source
+					res.Close()
+				}))
+
+				sess := subject.Session()
+				functest.Must(sess.Call(context.Background(), ns, "", nil))
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				changes, err := remote.Watch(ctx, ns)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				subject.GracefulStop()
+				<-subject.Done()
+
+				Eventually(received).Should(Receive())
+				Eventually(changes, 5*time.Second).Should(BeClosed())
+			})
+
+			It("destroys the sessions if they can not be migrated", func() {
+				store := sessionstore.NewMemory()
+				subject := functest.NewPeer(
+					options.SessionMigration(handler),
+					options.SessionStore(store),
+					options.DefaultTimeout(500*time.Millisecond),
+				)
+				defer subject.Stop()
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				events := subject.SessionEvents(ctx)
+				sess := subject.Session()
+
+				subject.GracefulStop()
+				<-subject.Done()
+
+				src := rinq.SessionEventSource{Peer: subject.ID()}
+
+				Eventually(events).Should(Receive(Equal(rinq.SessionEvent{
+					Type:   rinq.SessionCreated,
+					Ref:    sess.ID().At(0),
+					Source: src,
+				})))
+				Eventually(events).Should(Receive(Equal(rinq.SessionEvent{
+					Type:   rinq.SessionDestroyed,
+					Ref:    sess.ID().At(0),
+					Source: src,
+				})))
+
+				sessions, err := store.Load(subject.ID())
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(sessions).To(BeEmpty())
+			})
+
+			It("retains the expiry of migrated sessions", func() {
+				target := functest.NewPeer(options.SessionMigration(handler))
+				defer target.Stop()
+
+				subject := functest.NewPeer(options.SessionMigration(handler))
+				defer subject.Stop()
+
+				subject.Session(rinq.TTL(2 * time.Second))
+
+				subject.GracefulStop()
+				<-subject.Done()
+
+				var m migrated
+				Eventually(received).Should(Receive(&m))
+				Eventually(m.sess.Done(), 10*time.Second).Should(BeClosed())
+			})
+		})
+
 		It("waits for pending calls", func() {
 			server := functest.SharedPeer()
 			barrier := make(chan struct{})
@@ -249,3 +977,30 @@ var _ = Describe("peer (functional)", func() {
 		})
 	})
 })
+
+// messageLogger is a logging.Logger that records the messages it is given.
+type messageLogger struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (l *messageLogger) Log(fields []logging.Field, format string, args ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func (l *messageLogger) Debug(fields []logging.Field, format string, args ...interface{}) {}
+
+func (l *messageLogger) IsDebug() bool {
+	return false
+}
+
+// Messages returns the messages logged so far.
+func (l *messageLogger) Messages() []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return append([]string(nil), l.messages...)
+}
@@ -1,16 +1,20 @@
 package rinqamqp
 
 import (
-	"github.com/jmalloc/twelf/src/twelf"
 	"github.com/rinq/rinq-go/src/rinq/ident"
+	"github.com/rinq/rinq-go/src/rinq/logging"
 )
 
 func logStartedListening(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	namespace string,
 ) {
 	logger.Log(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Namespace(namespace),
+		},
 		"%s started listening for command requests in '%s' namespace",
 		peerID.ShortString(),
 		namespace,
@@ -18,13 +22,67 @@ func logStartedListening(
 }
 
 func logStoppedListening(
-	logger twelf.Logger,
+	logger logging.Logger,
 	peerID ident.PeerID,
 	namespace string,
 ) {
 	logger.Log(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Namespace(namespace),
+		},
 		"%s stopped listening for command requests in '%s' namespace",
 		peerID.ShortString(),
 		namespace,
 	)
 }
+
+func logMigrated(
+	logger logging.Logger,
+	peerID ident.PeerID,
+	moves map[ident.SessionID]ident.SessionID,
+	err error,
+) {
+	for from, to := range moves {
+		logger.Log(
+			[]logging.Field{
+				logging.Peer(peerID),
+				logging.Session(from),
+			},
+			"%s migrated session %s to %s",
+			peerID.ShortString(),
+			from.ShortString(),
+			to.ShortString(),
+		)
+	}
+
+	if err != nil {
+		logger.Log(
+			[]logging.Field{
+				logging.Peer(peerID),
+				logging.Error(err),
+			},
+			"%s could not inform other peers of migrated sessions: %s",
+			peerID.ShortString(),
+			err,
+		)
+	}
+}
+
+func logMigrationError(
+	logger logging.Logger,
+	peerID ident.PeerID,
+	count int,
+	err error,
+) {
+	logger.Log(
+		[]logging.Field{
+			logging.Peer(peerID),
+			logging.Error(err),
+		},
+		"%s could not migrate %d session(s), they have been destroyed: %s",
+		peerID.ShortString(),
+		count,
+		err,
+	)
+}